@@ -0,0 +1,588 @@
+package filesystemserver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// archiveSource is a file or directory added to an archive. Its entries are
+// stored below name, or at the root of the archive when name is empty.
+type archiveSource struct {
+	path string
+	name string
+}
+
+// archiveFormat returns "zip" or "tar.gz", taken from format when set and
+// from the extension of destination otherwise
+func archiveFormat(format, destination string) (string, error) {
+	switch strings.ToLower(format) {
+	case "zip":
+		return "zip", nil
+	case "tar.gz", "tgz":
+		return "tar.gz", nil
+	case "":
+	default:
+		return "", fmt.Errorf("unsupported archive format %q, expected zip or tar.gz", format)
+	}
+
+	lower := strings.ToLower(destination)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip", nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz", nil
+	}
+	return "", fmt.Errorf("cannot infer the archive format from %s, set format to zip or tar.gz", filepath.Base(destination))
+}
+
+// archiveWriter adds entries to a zip or tar.gz archive
+type archiveWriter interface {
+	addDir(name string, info os.FileInfo) error
+	addFile(name string, info os.FileInfo, src string) error
+	Close() error
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) addDir(name string, info os.FileInfo) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name + "/"
+	_, err = w.zw.CreateHeader(header)
+	return err
+}
+
+func (w *zipArchiveWriter) addFile(name string, info os.FileInfo, src string) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	dst, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	return copyFileContents(dst, src)
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarGzArchiveWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+func (w *tarGzArchiveWriter) addDir(name string, info os.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name + "/"
+	return w.tw.WriteHeader(header)
+}
+
+func (w *tarGzArchiveWriter) addFile(name string, info os.FileInfo, src string) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	return copyFileContents(w.tw, src)
+}
+
+func (w *tarGzArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gw.Close()
+}
+
+// copyFileContents streams the file at src into dst
+func copyFileContents(dst io.Writer, src string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(dst, file)
+	return err
+}
+
+// writeArchive streams sources into a new archive at destination and returns
+// the number of entries written. The archive is built in a temporary file that
+// is renamed into place, so a failure never leaves a partial archive behind.
+// Symlinks and other special files are skipped, so an archive never contains
+// content from outside its sources.
+func writeArchive(destination, format string, sources []archiveSource) (int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(destination), "."+filepath.Base(destination)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	defer tmp.Close()
+
+	var writer archiveWriter
+	if format == "zip" {
+		writer = &zipArchiveWriter{zw: zip.NewWriter(tmp)}
+	} else {
+		gw := gzip.NewWriter(tmp)
+		writer = &tarGzArchiveWriter{gw: gw, tw: tar.NewWriter(gw)}
+	}
+
+	entries := 0
+	for _, source := range sources {
+		err := filepath.WalkDir(source.path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Never archive the archive being written
+			if p == destination || p == tmpPath {
+				return nil
+			}
+
+			rel, err := filepath.Rel(source.path, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(source.name, filepath.ToSlash(rel))
+			if name == "." || name == "" {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			switch {
+			case info.IsDir():
+				err = writer.addDir(name, info)
+			case info.Mode().IsRegular():
+				err = writer.addFile(name, info, p)
+			default:
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("adding %s: %w", name, err)
+			}
+			entries++
+			return nil
+		})
+		if err != nil {
+			writer.Close()
+			return 0, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, destination); err != nil {
+		return 0, err
+	}
+	return entries, nil
+}
+
+// ExtractResult lists what extractArchive wrote and which entries it skipped
+type ExtractResult struct {
+	Files   []string
+	Skipped []string
+}
+
+// archiveEntryTarget returns where an archive entry named name is extracted
+// below dest. Entries that are absolute or would escape dest (Zip Slip) are
+// rejected.
+func archiveEntryTarget(dest, name string) (string, error) {
+	name = filepath.FromSlash(strings.ReplaceAll(name, `\`, "/"))
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("absolute path")
+	}
+	target := filepath.Join(dest, name)
+	if target != dest && !strings.HasPrefix(target, dest+string(filepath.Separator)) {
+		return "", fmt.Errorf("path escapes the destination")
+	}
+	return target, nil
+}
+
+// extractArchive unpacks a zip or tar.gz archive into dest. validate is
+// called on every target path and must reject paths outside the allowed
+// directories, which also catches symlinks already present below dest.
+// Unsafe entries, links and existing files (unless overwrite is set) are
+// skipped and reported rather than extracted.
+func extractArchive(archivePath, format, dest string, overwrite bool, validate func(string) error) (*ExtractResult, error) {
+	result := &ExtractResult{}
+
+	extract := func(name string, mode os.FileMode, open func() (io.ReadCloser, error)) error {
+		target, err := archiveEntryTarget(dest, name)
+		if err == nil {
+			err = validate(target)
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", name, err))
+			return nil
+		}
+
+		switch {
+		case mode.IsDir():
+			return os.MkdirAll(target, 0755)
+		case !mode.IsRegular():
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: not a regular file", name))
+			return nil
+		}
+
+		if info, err := os.Lstat(target); err == nil && (!overwrite || !info.Mode().IsRegular()) {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: already exists", name))
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		src, err := open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		perm := mode.Perm()
+		if perm == 0 {
+			perm = 0644
+		}
+		dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			dst.Close()
+			return err
+		}
+		if err := dst.Close(); err != nil {
+			return err
+		}
+		result.Files = append(result.Files, target)
+		return nil
+	}
+
+	if format == "zip" {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		for _, f := range zr.File {
+			if err := extract(f.Name, f.Mode(), f.Open); err != nil {
+				return result, fmt.Errorf("extracting %s: %w", f.Name, err)
+			}
+		}
+		return result, nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		if err := extract(header.Name, header.FileInfo().Mode(), open); err != nil {
+			return result, fmt.Errorf("extracting %s: %w", header.Name, err)
+		}
+	}
+}
+
+func (fs *FilesystemHandler) handleCompress(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	destination, err := request.RequireString("destination")
+	if err != nil {
+		return nil, err
+	}
+	source := request.GetString("source", "")
+	paths := request.GetStringSlice("paths", nil)
+	if (source == "") == (len(paths) == 0) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: set either source or paths",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	format, err := archiveFormat(request.GetString("format", ""), destination)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validDest, err := fs.validatePath(destination)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error with destination path: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if info, err := os.Stat(validDest); err == nil {
+		if info.IsDir() || !request.GetBool("overwrite", false) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: destination %s already exists; set overwrite to replace it", destination),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	// A source directory is archived with its contents at the root of the
+	// archive, listed paths are archived under their base name
+	var sources []archiveSource
+	if source != "" {
+		paths = []string{source}
+	}
+	for _, p := range paths {
+		validPath, err := fs.validatePath(p)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error with source path '%s': %v", p, err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		info, err := os.Stat(validPath)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		name := filepath.Base(validPath)
+		if source != "" && info.IsDir() {
+			name = ""
+		}
+		sources = append(sources, archiveSource{path: validPath, name: name})
+	}
+
+	entries, err := writeArchive(validDest, format, sources)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error creating archive: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validDest)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Created %s archive %s with %d entries", format, destination, entries),
+				},
+			},
+		}, nil
+	}
+
+	resourceURI := pathToResourceURI(validDest)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Created %s archive %s with %d entries (%d bytes)", format, destination, entries, info.Size()),
+			},
+			mcp.EmbeddedResource{
+				Type: "resource",
+				Resource: mcp.TextResourceContents{
+					URI:      resourceURI,
+					MIMEType: "text/plain",
+					Text:     fmt.Sprintf("Archive: %s (%d entries, %d bytes)", validDest, entries, info.Size()),
+				},
+			},
+		},
+	}, nil
+}
+
+func (fs *FilesystemHandler) handleExtract(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	archive, err := request.RequireString("archive")
+	if err != nil {
+		return nil, err
+	}
+	destination, err := request.RequireString("destination")
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := archiveFormat(request.GetString("format", ""), archive)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validArchive, err := fs.validatePath(archive)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error with archive path: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validDest, err := fs.validatePath(destination)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error with destination path: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if err := os.MkdirAll(validDest, 0755); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error creating destination directory: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validate := func(path string) error {
+		_, err := fs.validatePath(path)
+		return err
+	}
+	result, err := extractArchive(validArchive, format, validDest, request.GetBool("overwrite", false), validate)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error extracting archive: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Extracted %d files from %s to %s", len(result.Files), archive, destination))
+	for _, file := range result.Files {
+		text.WriteString("\n" + file)
+	}
+	if len(result.Skipped) > 0 {
+		text.WriteString(fmt.Sprintf("\n\nSkipped %d entries:", len(result.Skipped)))
+		for _, skipped := range result.Skipped {
+			text.WriteString("\n" + skipped)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text.String(),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,212 @@
+package filesystemserver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveFormat(t *testing.T) {
+	format, err := archiveFormat("", "/tmp/out.ZIP")
+	require.NoError(t, err)
+	assert.Equal(t, "zip", format)
+
+	format, err = archiveFormat("", "/tmp/out.tgz")
+	require.NoError(t, err)
+	assert.Equal(t, "tar.gz", format)
+
+	format, err = archiveFormat("tar.gz", "/tmp/out.bin")
+	require.NoError(t, err)
+	assert.Equal(t, "tar.gz", format)
+
+	_, err = archiveFormat("", "/tmp/out.bin")
+	assert.ErrorContains(t, err, "cannot infer")
+	_, err = archiveFormat("rar", "/tmp/out.rar")
+	assert.ErrorContains(t, err, "unsupported archive format")
+}
+
+func TestCompress(t *testing.T) {
+	dir := t.TempDir()
+	build := filepath.Join(dir, "build")
+	require.NoError(t, os.MkdirAll(filepath.Join(build, "assets"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(build, "app"), []byte("binary"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(build, "assets", "style.css"), []byte("body{}"), 0644))
+	require.NoError(t, os.Symlink(t.TempDir(), filepath.Join(build, "escape")))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "compress"
+	request.Params.Arguments = map[string]any{
+		"source":      build,
+		"destination": filepath.Join(dir, "build.zip"),
+	}
+
+	result, err := handler.handleCompress(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content))
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Created zip archive")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "with 3 entries")
+
+	zr, err := zip.OpenReader(filepath.Join(dir, "build.zip"))
+	require.NoError(t, err)
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"app", "assets/", "assets/style.css"}, names)
+
+	// an existing archive is only replaced with overwrite
+	result, err = handler.handleCompress(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "already exists")
+
+	request.Params.Arguments = map[string]any{
+		"paths":       []any{filepath.Join(build, "app"), filepath.Join(build, "assets")},
+		"destination": filepath.Join(dir, "bundle.tar.gz"),
+	}
+	result, err = handler.handleCompress(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content))
+
+	file, err := os.Open(filepath.Join(dir, "bundle.tar.gz"))
+	require.NoError(t, err)
+	defer file.Close()
+	gr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	contents := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		contents[header.Name] = string(data)
+	}
+	assert.Equal(t, map[string]string{"app": "binary", "assets/": "", "assets/style.css": "body{}"}, contents)
+}
+
+func TestArchiveEntryTarget(t *testing.T) {
+	dest := filepath.Join(string(filepath.Separator), "srv", "out")
+
+	target, err := archiveEntryTarget(dest, "docs/readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dest, "docs", "readme.md"), target)
+
+	for _, name := range []string{"../evil", "docs/../../evil", `..\evil`, "/etc/passwd"} {
+		_, err := archiveEntryTarget(dest, name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestExtract_ZipSlip(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	dest := filepath.Join(dir, "out")
+	require.NoError(t, os.MkdirAll(dest, 0755))
+	// an existing symlink below the destination must not be written through
+	require.NoError(t, os.Symlink(outside, filepath.Join(dest, "link")))
+
+	archive := filepath.Join(dir, "evil.zip")
+	file, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(file)
+	for _, name := range []string{"ok/file.txt", "../evil.txt", "/abs.txt", "link/pwn.txt"} {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("payload"))
+		require.NoError(t, err)
+	}
+	header := &zip.FileHeader{Name: "symlink"}
+	header.SetMode(os.ModeSymlink | 0777)
+	w, err := zw.CreateHeader(header)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("/etc/passwd"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, file.Close())
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "extract"
+	request.Params.Arguments = map[string]any{
+		"archive":     archive,
+		"destination": dest,
+	}
+
+	result, err := handler.handleExtract(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content))
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Extracted 1 files")
+	assert.Contains(t, text, "Skipped 4 entries")
+
+	content, err := os.ReadFile(filepath.Join(dest, "ok", "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "evil.txt"))
+	assert.NoFileExists(t, filepath.Join(outside, "pwn.txt"))
+	assert.NoFileExists(t, filepath.Join(dest, "symlink"))
+}
+
+func TestExtract_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "bin", "run.sh"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "README"), []byte("hi"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"source":      src,
+		"destination": filepath.Join(dir, "src.tgz"),
+	}
+	result, err := handler.handleCompress(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content))
+
+	request.Params.Arguments = map[string]any{
+		"archive":     filepath.Join(dir, "src.tgz"),
+		"destination": filepath.Join(dir, "copy"),
+	}
+	result, err = handler.handleExtract(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content))
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Extracted 2 files")
+
+	info, err := os.Stat(filepath.Join(dir, "copy", "bin", "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	// extracting again skips the existing files unless overwrite is set
+	result, err = handler.handleExtract(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Extracted 0 files")
+	request.Params.Arguments.(map[string]any)["overwrite"] = true
+	result, err = handler.handleExtract(context.Background(), request)
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Extracted 2 files")
+}
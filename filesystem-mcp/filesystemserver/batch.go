@@ -0,0 +1,408 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// Maximum number of operations in one batch
+	MAX_BATCH_OPERATIONS = 100
+	// Largest existing file a batch backs up so it can be restored (10MB)
+	MAX_BATCH_BACKUP_SIZE = 10 * 1024 * 1024
+)
+
+// batchOperation is a tool that can run inside a batch
+type batchOperation struct {
+	handler func(fs *FilesystemHandler) server.ToolHandlerFunc
+	// Arguments holding the paths the operation reads or changes
+	paths []string
+	// Argument holding the path the operation changes
+	target string
+}
+
+var batchOperations = map[string]batchOperation{
+	"write_file": {
+		handler: func(fs *FilesystemHandler) server.ToolHandlerFunc { return fs.handleWriteFile },
+		paths:   []string{"path"},
+		target:  "path",
+	},
+	"append_to_file": {
+		handler: func(fs *FilesystemHandler) server.ToolHandlerFunc { return fs.handleAppendToFile },
+		paths:   []string{"path"},
+		target:  "path",
+	},
+	"create_directory": {
+		handler: func(fs *FilesystemHandler) server.ToolHandlerFunc { return fs.handleCreateDirectory },
+		paths:   []string{"path"},
+		target:  "path",
+	},
+	"copy_file": {
+		handler: func(fs *FilesystemHandler) server.ToolHandlerFunc { return fs.handleCopyFile },
+		paths:   []string{"source", "destination"},
+		target:  "destination",
+	},
+	"move_file": {
+		handler: func(fs *FilesystemHandler) server.ToolHandlerFunc { return fs.handleMoveFile },
+		paths:   []string{"source", "destination"},
+		target:  "destination",
+	},
+	"delete_file": {
+		handler: func(fs *FilesystemHandler) server.ToolHandlerFunc { return fs.handleDeleteFile },
+		paths:   []string{"path"},
+		target:  "path",
+	},
+}
+
+// batchOperationNames returns the tools that can run inside a batch
+func batchOperationNames() []string {
+	names := make([]string, 0, len(batchOperations))
+	for name := range batchOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BatchOperationResult is the outcome of one operation of a batch
+type BatchOperationResult struct {
+	Tool string `json:"tool"`
+	// Status is succeeded, failed, rolled_back or not_run
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// RollbackError explains why a change could not be undone
+	RollbackError string `json:"rollbackError,omitempty"`
+}
+
+// BatchResult is the output of batch
+type BatchResult struct {
+	Succeeded  int                    `json:"succeeded"`
+	RolledBack bool                   `json:"rolledBack"`
+	Operations []BatchOperationResult `json:"operations"`
+}
+
+// batchSnapshot records the state of a path before an operation changes it,
+// so the change can be undone
+type batchSnapshot struct {
+	path    string
+	existed bool
+	isDir   bool
+	content []byte
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+	// Missing ancestors of a new path, outermost first
+	createdDirs []string
+	// Why the path cannot be restored, empty if it can
+	irreversible string
+}
+
+// takeSnapshot records the state of path. Regular files up to
+// MAX_BATCH_BACKUP_SIZE are backed up; the contents of existing directories
+// are not, so they cannot be restored once removed.
+func takeSnapshot(path string) batchSnapshot {
+	snapshot := batchSnapshot{path: path}
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if _, err := os.Lstat(dir); err == nil {
+				break
+			}
+			snapshot.createdDirs = append([]string{dir}, snapshot.createdDirs...)
+		}
+		return snapshot
+	}
+	if err != nil {
+		snapshot.irreversible = err.Error()
+		return snapshot
+	}
+
+	snapshot.existed = true
+	snapshot.isDir = info.IsDir()
+	snapshot.mode = info.Mode()
+	snapshot.size = info.Size()
+	snapshot.modTime = info.ModTime()
+	switch {
+	case info.IsDir():
+	case !info.Mode().IsRegular():
+		snapshot.irreversible = fmt.Sprintf("%s is not a regular file and was not backed up", path)
+	case info.Size() > MAX_BATCH_BACKUP_SIZE:
+		snapshot.irreversible = fmt.Sprintf("%s is larger than %d bytes and was not backed up", path, MAX_BATCH_BACKUP_SIZE)
+	default:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			snapshot.irreversible = err.Error()
+		}
+		snapshot.content = content
+	}
+	return snapshot
+}
+
+// changed reports whether the path differs from the snapshot
+func (s batchSnapshot) changed() bool {
+	info, err := os.Lstat(s.path)
+	if !s.existed {
+		if err == nil {
+			return true
+		}
+		for _, dir := range s.createdDirs {
+			if _, err := os.Lstat(dir); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	if s.isDir {
+		// Directories only count as changed when replaced, since their
+		// modification time changes with every entry added below them
+		return !info.IsDir()
+	}
+	return info.Mode() != s.mode || info.Size() != s.size || !info.ModTime().Equal(s.modTime)
+}
+
+// restore undoes the changes to the path since the snapshot was taken
+func (s batchSnapshot) restore() error {
+	// Changes below a directory are not tracked, so they are never undone
+	if s.isDir && s.irreversible != "" {
+		return errors.New(s.irreversible)
+	}
+	if !s.changed() {
+		return nil
+	}
+	if s.irreversible != "" {
+		return errors.New(s.irreversible)
+	}
+
+	if !s.existed {
+		if err := os.RemoveAll(s.path); err != nil {
+			return err
+		}
+		for i := len(s.createdDirs) - 1; i >= 0; i-- {
+			if err := os.Remove(s.createdDirs[i]); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if s.isDir {
+		return fmt.Errorf("directory %s was deleted or replaced and cannot be restored", s.path)
+	}
+	if info, err := os.Lstat(s.path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s was replaced by a directory and cannot be restored", s.path)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, s.content, s.mode.Perm()); err != nil {
+		return err
+	}
+	if err := os.Chmod(s.path, s.mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(s.path, s.modTime, s.modTime)
+}
+
+// batchStep is an operation of a batch with its validated arguments
+type batchStep struct {
+	tool      string
+	operation batchOperation
+	arguments map[string]any
+	// Validated paths, indexed by argument name
+	paths map[string]string
+}
+
+// parseBatch checks every operation of a batch before any runs: the tool must
+// be supported and each of its paths must be within the allowed directories
+func (fs *FilesystemHandler) parseBatch(raw any) ([]batchStep, error) {
+	operations, ok := raw.([]any)
+	if !ok || len(operations) == 0 {
+		return nil, fmt.Errorf("operations must be a non-empty array")
+	}
+	if len(operations) > MAX_BATCH_OPERATIONS {
+		return nil, fmt.Errorf("too many operations: %d (at most %d)", len(operations), MAX_BATCH_OPERATIONS)
+	}
+
+	steps := make([]batchStep, 0, len(operations))
+	for i, raw := range operations {
+		op, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("operation %d must be an object", i)
+		}
+		tool, _ := op["tool"].(string)
+		operation, ok := batchOperations[tool]
+		if !ok {
+			return nil, fmt.Errorf("operation %d: unsupported tool %q", i, tool)
+		}
+		arguments, ok := op["arguments"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("operation %d (%s): arguments must be an object", i, tool)
+		}
+
+		step := batchStep{
+			tool:      tool,
+			operation: operation,
+			arguments: arguments,
+			paths:     make(map[string]string),
+		}
+		for _, name := range operation.paths {
+			path, ok := arguments[name].(string)
+			if !ok || path == "" {
+				return nil, fmt.Errorf("operation %d (%s): missing %s", i, tool, name)
+			}
+			validPath, err := fs.validatePath(path)
+			if err != nil {
+				return nil, fmt.Errorf("operation %d (%s): %v", i, tool, err)
+			}
+			step.paths[name] = validPath
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// undo reverts a step that ran, or failed part-way, given the snapshot of its
+// target taken before it ran
+func (step batchStep) undo(snapshot batchSnapshot) error {
+	if step.tool == "move_file" {
+		// Move the source back first, then restore what it replaced
+		source := step.paths["source"]
+		if _, err := os.Lstat(source); os.IsNotExist(err) {
+			if _, err := os.Lstat(snapshot.path); err == nil {
+				if err := renameFile(snapshot.path, source); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return snapshot.restore()
+}
+
+// resultMessage returns the text of a tool result
+func resultMessage(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// runBatch runs steps in order and stops at the first failure. With
+// rollback, the changes of the failed step and of those before it are then
+// undone in reverse order.
+func (fs *FilesystemHandler) runBatch(ctx context.Context, steps []batchStep, rollback bool) BatchResult {
+	result := BatchResult{Operations: make([]BatchOperationResult, len(steps))}
+	for i, step := range steps {
+		result.Operations[i] = BatchOperationResult{Tool: step.tool, Status: "not_run"}
+	}
+
+	snapshots := make([]batchSnapshot, 0, len(steps))
+	failed := -1
+	for i, step := range steps {
+		snapshot := takeSnapshot(step.paths[step.operation.target])
+		if step.tool == "copy_file" && snapshot.isDir {
+			snapshot.irreversible = fmt.Sprintf("files copied into the existing directory %s were not removed", snapshot.path)
+		}
+		snapshots = append(snapshots, snapshot)
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = step.tool
+		request.Params.Arguments = step.arguments
+		toolResult, err := step.operation.handler(fs)(ctx, request)
+		switch {
+		case err != nil:
+			result.Operations[i].Status = "failed"
+			result.Operations[i].Message = err.Error()
+		case toolResult.IsError:
+			result.Operations[i].Status = "failed"
+			result.Operations[i].Message = resultMessage(toolResult)
+		default:
+			result.Operations[i].Status = "succeeded"
+			result.Operations[i].Message = resultMessage(toolResult)
+			result.Succeeded++
+		}
+		if result.Operations[i].Status == "failed" {
+			failed = i
+			break
+		}
+	}
+
+	if failed < 0 || !rollback {
+		return result
+	}
+
+	result.RolledBack = true
+	for i := failed; i >= 0; i-- {
+		if err := steps[i].undo(snapshots[i]); err != nil {
+			result.Operations[i].RollbackError = err.Error()
+			result.RolledBack = false
+			continue
+		}
+		if result.Operations[i].Status == "succeeded" {
+			result.Operations[i].Status = "rolled_back"
+		}
+	}
+	return result
+}
+
+func (fs *FilesystemHandler) handleBatch(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	rollback := request.GetBool("rollback", true)
+
+	steps, err := fs.parseBatch(request.GetArguments()["operations"])
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v; no operations were run", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	result := fs.runBatch(ctx, steps, rollback)
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+		IsError: result.Succeeded < len(steps),
+	}, nil
+}
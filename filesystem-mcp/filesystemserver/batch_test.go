@@ -0,0 +1,163 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	call := func(args map[string]any) (*mcp.CallToolResult, BatchResult) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleBatch(context.Background(), request)
+		require.NoError(t, err)
+		var output BatchResult
+		_ = json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output)
+		return result, output
+	}
+	op := func(tool string, arguments map[string]any) map[string]any {
+		return map[string]any{"tool": tool, "arguments": arguments}
+	}
+	statuses := func(output BatchResult) []string {
+		var s []string
+		for _, op := range output.Operations {
+			s = append(s, op.Status)
+		}
+		return s
+	}
+
+	t.Run("scaffold", func(t *testing.T) {
+		project := filepath.Join(dir, "project")
+		result, output := call(map[string]any{"operations": []any{
+			op("create_directory", map[string]any{"path": filepath.Join(project, "cmd")}),
+			op("write_file", map[string]any{"path": filepath.Join(project, "go.mod"), "content": "module example\n"}),
+			op("write_file", map[string]any{"path": filepath.Join(project, "cmd", "main.go"), "content": "package main\n"}),
+			op("copy_file", map[string]any{"source": filepath.Join(project, "go.mod"), "destination": filepath.Join(project, "go.mod.orig")}),
+			op("move_file", map[string]any{"source": filepath.Join(project, "go.mod.orig"), "destination": filepath.Join(project, "go.mod.bak")}),
+		}})
+		require.False(t, result.IsError, result.Content)
+		assert.Equal(t, 5, output.Succeeded)
+		assert.False(t, output.RolledBack)
+		assert.Equal(t, []string{"succeeded", "succeeded", "succeeded", "succeeded", "succeeded"}, statuses(output))
+		assert.FileExists(t, filepath.Join(project, "cmd", "main.go"))
+		assert.FileExists(t, filepath.Join(project, "go.mod.bak"))
+		assert.NoFileExists(t, filepath.Join(project, "go.mod.orig"))
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		existing := filepath.Join(dir, "existing.txt")
+		require.NoError(t, os.WriteFile(existing, []byte("original"), 0600))
+		log := filepath.Join(dir, "log.txt")
+		require.NoError(t, os.WriteFile(log, []byte("line 1\n"), 0644))
+		moved := filepath.Join(dir, "moved.txt")
+		require.NoError(t, os.WriteFile(moved, []byte("moved"), 0644))
+
+		result, output := call(map[string]any{"operations": []any{
+			op("write_file", map[string]any{"path": existing, "content": "replaced"}),
+			op("write_file", map[string]any{"path": filepath.Join(dir, "new", "deep", "file.txt"), "content": "new"}),
+			op("append_to_file", map[string]any{"path": log, "content": "line 2\n"}),
+			op("move_file", map[string]any{"source": moved, "destination": filepath.Join(dir, "elsewhere", "moved.txt")}),
+			op("move_file", map[string]any{"source": filepath.Join(dir, "missing.txt"), "destination": filepath.Join(dir, "other.txt")}),
+			op("write_file", map[string]any{"path": filepath.Join(dir, "never.txt"), "content": "never"}),
+		}})
+		require.True(t, result.IsError)
+		assert.Equal(t, 4, output.Succeeded)
+		assert.True(t, output.RolledBack, output)
+		assert.Equal(t, []string{"rolled_back", "rolled_back", "rolled_back", "rolled_back", "failed", "not_run"}, statuses(output))
+		assert.Contains(t, output.Operations[4].Message, "does not exist")
+
+		content, err := os.ReadFile(existing)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+		info, err := os.Stat(existing)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		assert.NoDirExists(t, filepath.Join(dir, "new"))
+		content, err = os.ReadFile(log)
+		require.NoError(t, err)
+		assert.Equal(t, "line 1\n", string(content))
+		assert.FileExists(t, moved)
+		assert.NoDirExists(t, filepath.Join(dir, "elsewhere"))
+		assert.NoFileExists(t, filepath.Join(dir, "never.txt"))
+	})
+
+	t.Run("without rollback", func(t *testing.T) {
+		kept := filepath.Join(dir, "kept.txt")
+		result, output := call(map[string]any{
+			"operations": []any{
+				op("write_file", map[string]any{"path": kept, "content": "kept"}),
+				op("delete_file", map[string]any{"path": filepath.Join(dir, "missing.txt")}),
+			},
+			"rollback": false,
+		})
+		require.True(t, result.IsError)
+		assert.False(t, output.RolledBack)
+		assert.Equal(t, []string{"succeeded", "failed"}, statuses(output))
+		assert.FileExists(t, kept)
+	})
+
+	t.Run("deleted directory cannot be restored", func(t *testing.T) {
+		doomed := filepath.Join(dir, "doomed")
+		require.NoError(t, os.MkdirAll(doomed, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(doomed, "file.txt"), []byte("x"), 0644))
+
+		result, output := call(map[string]any{"operations": []any{
+			op("delete_file", map[string]any{"path": doomed, "recursive": true}),
+			op("delete_file", map[string]any{"path": filepath.Join(dir, "missing.txt")}),
+		}})
+		require.True(t, result.IsError)
+		assert.False(t, output.RolledBack)
+		assert.Equal(t, "succeeded", output.Operations[0].Status)
+		assert.Contains(t, output.Operations[0].RollbackError, "cannot be restored")
+		assert.Empty(t, output.Operations[1].RollbackError)
+	})
+
+	t.Run("paths are validated before running", func(t *testing.T) {
+		first := filepath.Join(dir, "first.txt")
+		result, _ := call(map[string]any{"operations": []any{
+			op("write_file", map[string]any{"path": first, "content": "first"}),
+			op("write_file", map[string]any{"path": filepath.Join(t.TempDir(), "outside.txt"), "content": "outside"}),
+		}})
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "operation 1 (write_file): access denied")
+		assert.NoFileExists(t, first)
+	})
+
+	t.Run("invalid operations", func(t *testing.T) {
+		for _, operations := range []any{
+			nil,
+			[]any{},
+			[]any{op("read_file", map[string]any{"path": filepath.Join(dir, "x")})},
+			[]any{map[string]any{"tool": "write_file"}},
+			[]any{op("write_file", map[string]any{"content": "no path"})},
+		} {
+			result, _ := call(map[string]any{"operations": operations})
+			assert.True(t, result.IsError, operations)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "no operations were run")
+		}
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		readOnly, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithReadOnly(true))
+		require.NoError(t, err)
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"operations": []any{
+			op("write_file", map[string]any{"path": filepath.Join(dir, "ro.txt"), "content": "x"}),
+		}}
+		result, err := readOnly.handleBatch(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.NoFileExists(t, filepath.Join(dir, "ro.txt"))
+	})
+}
@@ -0,0 +1,83 @@
+package filesystemserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (fs *FilesystemHandler) handleChangeOwner(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	uid, err := request.RequireInt("uid")
+	if err != nil {
+		return nil, err
+	}
+	gid, err := request.RequireInt("gid")
+	if err != nil {
+		return nil, err
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if _, err := os.Stat(validPath); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	count, err := changeOwner(validPath, uid, gid)
+	if err != nil {
+		message := fmt.Sprintf("Error changing ownership: %v", err)
+		if errors.Is(err, os.ErrPermission) {
+			message = fmt.Sprintf("Error: insufficient privileges to change ownership (changed %d entries before failing): %v", count, err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: message,
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Successfully changed ownership of %d entries under %s to %d:%d", count, path, uid, gid),
+			},
+		},
+	}, nil
+}
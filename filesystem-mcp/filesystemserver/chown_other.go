@@ -0,0 +1,13 @@
+//go:build !unix
+
+package filesystemserver
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// changeOwner is not supported on non-Unix platforms
+func changeOwner(root string, uid, gid int) (int, error) {
+	return 0, fmt.Errorf("changing ownership is not supported on %s", runtime.GOOS)
+}
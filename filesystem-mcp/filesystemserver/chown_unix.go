@@ -0,0 +1,27 @@
+//go:build unix
+
+package filesystemserver
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// changeOwner recursively sets the owner of root and everything below it.
+// Symlinks are not followed; the links themselves are re-owned.
+// It returns the number of entries changed.
+func changeOwner(root string, uid, gid int) (int, error) {
+	count := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := os.Lchown(path, uid, gid); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
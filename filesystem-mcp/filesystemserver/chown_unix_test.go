@@ -0,0 +1,61 @@
+//go:build unix
+
+package filesystemserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing ownership requires root privileges")
+	}
+
+	// setting up test folder
+	// tmpDir/
+	// - sub/
+	//   - b.txt
+	// - a.txt
+	dir := t.TempDir()
+	subDir := filepath.Join(dir, "sub")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(subDir, "b.txt"), []byte("b"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "change_owner"
+	request.Params.Arguments = map[string]any{
+		"path": subDir,
+		"uid":  float64(1234),
+		"gid":  float64(5678),
+	}
+
+	result, err := handler.handleChangeOwner(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected error: %v", result.Content)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "2 entries")
+
+	for _, path := range []string{subDir, filepath.Join(subDir, "b.txt")} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		stat := info.Sys().(*syscall.Stat_t)
+		assert.Equal(t, uint32(1234), stat.Uid, path)
+		assert.Equal(t, uint32(5678), stat.Gid, path)
+	}
+
+	// files outside the requested tree keep their owner
+	info, err := os.Stat(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.NotEqual(t, uint32(1234), info.Sys().(*syscall.Stat_t).Uid)
+}
@@ -0,0 +1,66 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Default number of expensive operations, such as searches and directory
+// walks, that run at the same time
+const DEFAULT_MAX_CONCURRENCY = 4
+
+// limiter is a semaphore bounding the number of concurrent expensive
+// operations
+type limiter chan struct{}
+
+// acquire waits for a free slot. It gives up when ctx is cancelled, so a
+// cancelled request never holds or waits for a slot.
+func (l limiter) acquire(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l limiter) release() {
+	<-l
+}
+
+// WithMaxConcurrency sets how many expensive operations (searches, directory
+// trees and disk usage) run at once; further calls wait for a free slot.
+// Values <= 0 keep the default of DEFAULT_MAX_CONCURRENCY.
+func WithMaxConcurrency(maxConcurrency int) Option {
+	return func(fs *FilesystemHandler) {
+		if maxConcurrency > 0 {
+			fs.limiter = make(limiter, maxConcurrency)
+		}
+	}
+}
+
+// limited wraps the handler of an expensive tool so that it only runs while
+// holding a slot of the handler's limiter. The slot is released when the
+// handler returns; the walks behind these tools check the request's context
+// for every entry, so a cancelled request frees its slot promptly.
+func (fs *FilesystemHandler) limited(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := fs.limiter.acquire(ctx); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: request cancelled while waiting for a free slot: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		defer fs.limiter.release()
+
+		return handler(ctx, request)
+	}
+}
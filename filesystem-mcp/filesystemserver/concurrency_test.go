@@ -0,0 +1,123 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimited(t *testing.T) {
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, t.TempDir()), WithMaxConcurrency(1))
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	blocking := handler.limited(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-unblock
+		return &mcp.CallToolResult{}, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = blocking(context.Background(), mcp.CallToolRequest{})
+	}()
+	<-started
+
+	calls := 0
+	quick := handler.limited(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return &mcp.CallToolResult{}, nil
+	})
+
+	// a call waiting for the only slot gives up when its request is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := quick(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "waiting for a free slot")
+	assert.Zero(t, calls)
+
+	// the slot is free again once the running call returns
+	close(unblock)
+	<-done
+	result, err = quick(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSearchContent_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("needle\n"), 0644))
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = handler.searchContent(ctx, dir, ContentSearchOptions{Pattern: "needle", MaxResults: 10})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = diskUsage(ctx, dir, 0, DEFAULT_USAGE_TOP, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// cancelAfterContext reports itself cancelled once Err has been called more
+// than n times, to cancel a walk partway through
+type cancelAfterContext struct {
+	context.Context
+	n     int32
+	calls atomic.Int32
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.calls.Add(1) > c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestTreeWalks_CancelledFreeSlot(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("dir%02d", i))
+		require.NoError(t, os.MkdirAll(filepath.Join(sub, "nested"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(sub, "file.txt"), []byte("x"), 0644))
+	}
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithMaxConcurrency(1))
+	require.NoError(t, err)
+
+	for name, tool := range map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"tree":           handler.limited(handler.handleTree),
+		"directory_tree": handler.limited(handler.handleDirectoryTree),
+	} {
+		t.Run(name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = map[string]any{"path": dir, "depth": 3, "max_depth": 3}
+
+			// the request is cancelled after a few entries of the walk
+			ctx := &cancelAfterContext{Context: context.Background(), n: 5}
+			result, err := tool(ctx, request)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, context.Canceled.Error())
+			assert.Less(t, ctx.calls.Load(), int32(10), "the walk went on after the request was cancelled")
+
+			// the slot was released, so a new request runs right away
+			ctx2, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			result, err = tool(ctx2, request)
+			require.NoError(t, err)
+			assert.False(t, result.IsError, "expected the slot to be free, got %+v", result.Content)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Maximum number of files count_lines counts in a single request
+const MAX_COUNT_FILES = 100
+
+// Counts are the line, word and byte counts of a file, as reported by wc.
+// A final line without a trailing newline is counted as a line.
+type Counts struct {
+	Lines int64 `json:"lines"`
+	Words int64 `json:"words"`
+	Bytes int64 `json:"bytes"`
+}
+
+// FileCounts are the counts of a single file of count_lines
+type FileCounts struct {
+	Path string `json:"path"`
+	Counts
+	Error string `json:"error,omitempty"`
+}
+
+// CountResult is the output of count_lines
+type CountResult struct {
+	Files []FileCounts `json:"files"`
+	Total Counts       `json:"total"`
+}
+
+// isCountSpace reports whether b separates words. Bytes of multi-byte UTF-8
+// sequences are never spaces, like wc in the C locale.
+func isCountSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+// countReader streams r in fixed-size chunks and counts its lines, words and
+// bytes
+func countReader(r io.Reader) (Counts, error) {
+	var counts Counts
+	inWord := false
+	lastByte := byte('\n')
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			switch {
+			case b == '\n':
+				counts.Lines++
+				inWord = false
+			case isCountSpace(b):
+				inWord = false
+			case !inWord:
+				counts.Words++
+				inWord = true
+			}
+		}
+		if n > 0 {
+			counts.Bytes += int64(n)
+			lastByte = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Counts{}, err
+		}
+	}
+
+	// Count a trailing line without a final newline
+	if lastByte != '\n' {
+		counts.Lines++
+	}
+	return counts, nil
+}
+
+// countFile counts the lines, words and bytes of the file at path
+func countFile(path string) (Counts, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Counts{}, err
+	}
+	defer file.Close()
+
+	return countReader(file)
+}
+
+// countPath validates and counts a single path of count_lines
+func (fs *FilesystemHandler) countPath(path string) FileCounts {
+	result := FileCounts{Path: path}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if info.IsDir() {
+		result.Error = "path is a directory"
+		return result
+	}
+
+	counts, err := countFile(validPath)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Counts = counts
+	return result
+}
+
+func (fs *FilesystemHandler) handleCountLines(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	paths := request.GetStringSlice("paths", nil)
+	if path := request.GetString("path", ""); path != "" {
+		paths = append([]string{path}, paths...)
+	}
+
+	if len(paths) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: either path or paths is required",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	if len(paths) > MAX_COUNT_FILES {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Too many files requested. Maximum is %d files per request.", MAX_COUNT_FILES),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Failures are reported per path, and the total covers the files that
+	// could be counted
+	result := CountResult{Files: make([]FileCounts, 0, len(paths))}
+	for _, path := range paths {
+		counts := fs.countPath(path)
+		result.Files = append(result.Files, counts)
+		result.Total.Lines += counts.Lines
+		result.Total.Words += counts.Words
+		result.Total.Bytes += counts.Bytes
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+	}, nil
+}
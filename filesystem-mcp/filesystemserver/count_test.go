@@ -0,0 +1,78 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountReader(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Counts
+	}{
+		{"", Counts{}},
+		{"one\n", Counts{Lines: 1, Words: 1, Bytes: 4}},
+		{"one two\nthree", Counts{Lines: 2, Words: 3, Bytes: 13}},
+		{"  spaced\t\tout  \n\n", Counts{Lines: 2, Words: 2, Bytes: 17}},
+		{"héllo wörld\n", Counts{Lines: 1, Words: 2, Bytes: 14}},
+	}
+	for _, tt := range tests {
+		got, err := countReader(strings.NewReader(tt.input))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "input %q", tt.input)
+	}
+
+	// words spanning the chunk boundary are counted once
+	got, err := countReader(strings.NewReader(strings.Repeat("x", 40*1024) + " y"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.Words)
+}
+
+func TestCountLines(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	require.NoError(t, os.WriteFile(a, []byte("one two\nthree\n"), 0644))
+	require.NoError(t, os.WriteFile(b, []byte("four"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	count := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleCountLines(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := count(map[string]any{"path": a})
+	require.False(t, result.IsError)
+	var single CountResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &single))
+	require.Len(t, single.Files, 1)
+	assert.Equal(t, Counts{Lines: 2, Words: 3, Bytes: 14}, single.Files[0].Counts)
+
+	missing := filepath.Join(dir, "missing.txt")
+	result = count(map[string]any{"paths": []any{a, b, missing, dir}})
+	require.False(t, result.IsError)
+	var multi CountResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &multi))
+	require.Len(t, multi.Files, 4)
+	assert.Equal(t, b, multi.Files[1].Path)
+	assert.Equal(t, Counts{Lines: 1, Words: 1, Bytes: 4}, multi.Files[1].Counts)
+	assert.NotEmpty(t, multi.Files[2].Error)
+	assert.Equal(t, "path is a directory", multi.Files[3].Error)
+	assert.Equal(t, Counts{Lines: 3, Words: 4, Bytes: 18}, multi.Total)
+
+	result = count(map[string]any{})
+	assert.True(t, result.IsError)
+}
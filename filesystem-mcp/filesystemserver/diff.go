@@ -0,0 +1,279 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DIFF_CONTEXT_LINES is the number of unchanged lines shown around each change
+const DIFF_CONTEXT_LINES = 3
+
+type diffOp struct {
+	kind byte // ' ' for unchanged, '-' for deleted, '+' for inserted
+	line string
+}
+
+// splitLines splits text into lines, keeping the line terminators so that a
+// change to the final newline shows up in the diff
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script from a to b using Myers' algorithm
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= offset; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[prevY]})
+		} else {
+			ops = append(ops, diffOp{'-', a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffOp{' ', a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff returns a unified diff between oldText and newText, or an empty
+// string if they are identical
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	return unifiedDiffContext(oldName, newName, oldText, newText, DIFF_CONTEXT_LINES)
+}
+
+// unifiedDiffContext is unifiedDiff with contextLines unchanged lines around
+// each change
+func unifiedDiffContext(oldName, newName, oldText, newText string, contextLines int) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	// Line numbers in the old and new text before each operation
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	var changes []int
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+
+	for i := 0; i < len(changes); {
+		// Merge changes whose context would overlap into a single hunk
+		last := i
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*contextLines+1 {
+			last++
+		}
+		start := max(changes[i]-contextLines, 0)
+		stop := min(changes[last]+contextLines+1, len(ops))
+
+		oldCount := oldLine[stop] - oldLine[start]
+		newCount := newLine[stop] - newLine[start]
+		result.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldCount), hunkRange(newLine[start], newCount)))
+
+		for _, op := range ops[start:stop] {
+			result.WriteByte(op.kind)
+			result.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				result.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = last + 1
+	}
+
+	return result.String()
+}
+
+// hunkRange formats the start,count part of a hunk header. Empty ranges
+// refer to the line before the hunk, as in GNU diff.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// readDiffSide reads one side of diff_files. A missing file reads as empty
+// and is labeled /dev/null, like a created or deleted file in git.
+func (fs *FilesystemHandler) readDiffSide(path string) (label, content string, exists bool, err error) {
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	info, err := os.Stat(validPath)
+	if os.IsNotExist(err) {
+		return "/dev/null", "", false, nil
+	}
+	if err != nil {
+		return "", "", false, err
+	}
+	if info.IsDir() {
+		return "", "", false, fmt.Errorf("%s is a directory", validPath)
+	}
+	if info.Size() > MAX_INLINE_SIZE {
+		return "", "", false, fmt.Errorf("%s is too large to diff (%d bytes, limit %d bytes)", validPath, info.Size(), MAX_INLINE_SIZE)
+	}
+
+	data, err := os.ReadFile(validPath)
+	if err != nil {
+		return "", "", false, err
+	}
+	if looksBinary(data) {
+		return "", "", false, fmt.Errorf("%s is a binary file", validPath)
+	}
+	return validPath, string(data), true, nil
+}
+
+func (fs *FilesystemHandler) handleDiffFiles(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	oldPath, err := request.RequireString("old_path")
+	if err != nil {
+		return nil, err
+	}
+	newPath, err := request.RequireString("new_path")
+	if err != nil {
+		return nil, err
+	}
+	contextLines := request.GetInt("context_lines", DIFF_CONTEXT_LINES)
+	if contextLines < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: invalid context_lines %d", contextLines),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	oldLabel, oldText, oldExists, err := fs.readDiffSide(oldPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	newLabel, newText, newExists, err := fs.readDiffSide(newPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	if !oldExists && !newExists {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: neither %s nor %s exists", oldPath, newPath),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	diff := unifiedDiffContext(oldLabel, newLabel, oldText, newText, contextLines)
+	if diff == "" {
+		diff = "Files are identical"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: diff,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,209 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TextEdit replaces a unique occurrence of OldText with NewText
+type TextEdit struct {
+	OldText string `json:"oldText"`
+	NewText string `json:"newText"`
+}
+
+// parseEdits converts the raw edits argument of edit_file into TextEdits
+func parseEdits(raw any) ([]TextEdit, error) {
+	items, ok := raw.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("at least one edit is required")
+	}
+
+	edits := make([]TextEdit, 0, len(items))
+	for i, item := range items {
+		fields, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("edit %d: expected an object with oldText and newText", i+1)
+		}
+		oldText, ok := fields["oldText"].(string)
+		if !ok || oldText == "" {
+			return nil, fmt.Errorf("edit %d: oldText must be a non-empty string", i+1)
+		}
+		newText, ok := fields["newText"].(string)
+		if !ok {
+			return nil, fmt.Errorf("edit %d: newText must be a string", i+1)
+		}
+		edits = append(edits, TextEdit{OldText: oldText, NewText: newText})
+	}
+	return edits, nil
+}
+
+// applyEdits applies edits in order, each to the result of the previous
+// ones. Every OldText must occur exactly once, otherwise nothing is applied.
+func applyEdits(content string, edits []TextEdit) (string, error) {
+	for i, edit := range edits {
+		switch count := strings.Count(content, edit.OldText); count {
+		case 0:
+			return "", fmt.Errorf("edit %d: oldText not found", i+1)
+		case 1:
+			content = strings.Replace(content, edit.OldText, edit.NewText, 1)
+		default:
+			return "", fmt.Errorf("edit %d: oldText found %d times, include more surrounding context to make it unique", i+1, count)
+		}
+	}
+	return content, nil
+}
+
+func (fs *FilesystemHandler) handleEditFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	dryRun := request.GetBool("dry_run", false)
+
+	if !dryRun {
+		if result := fs.readOnlyError(); result != nil {
+			return result, nil
+		}
+	}
+
+	edits, err := parseEdits(request.GetArguments()["edits"])
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot edit a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if !isTextFile(detectMimeType(validPath)) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot edit a binary file",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	original, err := os.ReadFile(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error reading file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// All edits are applied in memory first, so a failing edit leaves the
+	// file untouched
+	edited, err := applyEdits(string(original), edits)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v. No changes were made to %s", err, path),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	diff := unifiedDiff(validPath, validPath, string(original), edited)
+	if diff == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("No changes: %s is unchanged by the edits", path),
+				},
+			},
+		}, nil
+	}
+
+	if !dryRun {
+		if err := writeFileAtomic(validPath, []byte(edited), info.Mode().Perm()); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error writing file: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	var result strings.Builder
+	if dryRun {
+		result.WriteString(fmt.Sprintf("Dry run: %d edit(s) would change %s\n\n", len(edits), path))
+	} else {
+		result.WriteString(fmt.Sprintf("Applied %d edit(s) to %s\n\n", len(edits), path))
+	}
+	result.WriteString(diff)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: result.String(),
+			},
+		},
+	}, nil
+}
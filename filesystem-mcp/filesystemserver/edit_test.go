@@ -0,0 +1,80 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEdits(t *testing.T) {
+	content := "a := 1\nb := 2\nc := a + b\n"
+
+	edited, err := applyEdits(content, []TextEdit{
+		{OldText: "b := 2", NewText: "b := 3"},
+		// later edits see the result of earlier ones
+		{OldText: "b := 3\n", NewText: "b := 3\nd := 4\n"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "a := 1\nb := 3\nd := 4\nc := a + b\n", edited)
+
+	_, err = applyEdits(content, []TextEdit{{OldText: "missing", NewText: "x"}})
+	assert.ErrorContains(t, err, "edit 1: oldText not found")
+
+	_, err = applyEdits(content, []TextEdit{
+		{OldText: "a := 1", NewText: "a := 0"},
+		{OldText: " := ", NewText: " = "},
+	})
+	assert.ErrorContains(t, err, "edit 2: oldText found 3 times")
+}
+
+func TestEditFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	original := "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"
+	require.NoError(t, os.WriteFile(file, []byte(original), 0640))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "edit_file"
+	request.Params.Arguments = map[string]any{
+		"path": file,
+		"edits": []any{
+			map[string]any{"oldText": "\"hello\"", "newText": "\"world\""},
+			map[string]any{"oldText": "main", "newText": "app"},
+		},
+	}
+
+	// the ambiguous second edit fails the whole batch
+	result, err := handler.handleEditFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, fmt.Sprint(result.Content[0]), "found 2 times")
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content), "a failed edit must not write")
+
+	request.Params.Arguments.(map[string]any)["edits"] = []any{
+		map[string]any{"oldText": "\"hello\"", "newText": "\"world\""},
+	}
+	result, err = handler.handleEditFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content[0]))
+	assert.Contains(t, fmt.Sprint(result.Content[0]), "-\tprintln(\"hello\")\n+\tprintln(\"world\")\n")
+
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {\n\tprintln(\"world\")\n}\n", string(content))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
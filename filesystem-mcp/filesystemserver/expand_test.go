@@ -0,0 +1,89 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandPath_Home(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.Mkdir(filepath.Join(home, "project"), 0755))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, home))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "expand_path"
+	request.Params.Arguments = map[string]any{
+		"path": "~/project",
+	}
+
+	result, err := handler.handleExpandPath(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content[0]))
+
+	expected, err := filepath.EvalSymlinks(filepath.Join(home, "project"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestExpandPath_EnvVar(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FS_TEST_PROJECT", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	for _, path := range []string{"$FS_TEST_PROJECT/main.go", "${FS_TEST_PROJECT}/main.go", "%FS_TEST_PROJECT%/main.go"} {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "expand_path"
+		request.Params.Arguments = map[string]any{
+			"path": path,
+		}
+
+		result, err := handler.handleExpandPath(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, fmt.Sprint(result.Content[0]))
+
+		expected, err := filepath.EvalSymlinks(filepath.Join(dir, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Content[0].(mcp.TextContent).Text, path)
+	}
+}
+
+func TestExpandPath_OutsideAllowedDirs(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	t.Setenv("FS_TEST_OUTSIDE", outside)
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, allowed))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "expand_path"
+	request.Params.Arguments = map[string]any{
+		"path": "$FS_TEST_OUTSIDE/secrets",
+	}
+
+	result, err := handler.handleExpandPath(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, fmt.Sprint(result.Content[0]), "access denied")
+
+	request.Params.Arguments = map[string]any{
+		"path": "$FS_TEST_UNSET_VARIABLE/file",
+	}
+	result, err = handler.handleExpandPath(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, fmt.Sprint(result.Content[0]), "FS_TEST_UNSET_VARIABLE")
+}
@@ -2,18 +2,23 @@ package filesystemserver
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/djherbis/times"
 	"github.com/gabriel-vasile/mimetype"
@@ -30,16 +35,38 @@ const (
 	MAX_SEARCH_RESULTS = 1000
 	// Maximum file size in bytes to search within (10MB)
 	MAX_SEARCHABLE_SIZE = 10 * 1024 * 1024
+	// Default maximum number of entries returned by list_directory
+	DEFAULT_MAX_LIST_ENTRIES = 1000
+	// Number of leading bytes checked for NUL bytes by looksBinary
+	binarySniffLength = 8000
+	// Default number of lines returned by head_file and tail_file
+	DEFAULT_HEAD_TAIL_LINES = 10
+	// Default depth of the tree and directory_tree tools
+	DEFAULT_TREE_DEPTH = 3
+	// Default cap on the combined size of files returned by read_multiple_files (10MB)
+	MAX_MULTI_READ_SIZE = 10 * 1024 * 1024
 )
 
 type FileInfo struct {
-	Size        int64     `json:"size"`
-	Created     time.Time `json:"created"`
-	Modified    time.Time `json:"modified"`
-	Accessed    time.Time `json:"accessed"`
-	IsDirectory bool      `json:"isDirectory"`
-	IsFile      bool      `json:"isFile"`
-	Permissions string    `json:"permissions"`
+	Size          int64     `json:"size"`
+	Created       time.Time `json:"created"`
+	Modified      time.Time `json:"modified"`
+	Accessed      time.Time `json:"accessed"`
+	IsDirectory   bool      `json:"isDirectory"`
+	IsFile        bool      `json:"isFile"`
+	IsSymlink     bool      `json:"isSymlink"`
+	SymlinkTarget string    `json:"symlinkTarget,omitempty"`
+	Mode          string    `json:"mode"`
+	Permissions   string    `json:"permissions"`
+}
+
+// DirectoryTreeNode is a compact node of the directory_tree output
+type DirectoryTreeNode struct {
+	Name     string               `json:"name"`
+	Type     string               `json:"type"` // "file", "directory" or "symlink"
+	Children []*DirectoryTreeNode `json:"children,omitempty"`
+	// Truncated is set on non-empty directories below max_depth
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // FileNode represents a node in the file tree
@@ -52,6 +79,27 @@ type FileNode struct {
 	Children []*FileNode `json:"children,omitempty"`
 }
 
+// DirectoryEntry is a single entry returned by list_directory
+type DirectoryEntry struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"` // relative to the listed directory
+	IsDir    bool      `json:"isDir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// MultiFileResult is the read_multiple_files result for a single path
+type MultiFileResult struct {
+	Content     string `json:"content,omitempty"`
+	Error       string `json:"error,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	ResourceURI string `json:"resourceUri,omitempty"`
+	// Attached is set when the file is returned as a separate image or
+	// resource content block
+	Attached bool `json:"attached,omitempty"`
+}
+
 // SearchResult represents a single match in a file
 type SearchResult struct {
 	FilePath    string
@@ -62,11 +110,99 @@ type SearchResult struct {
 
 type FilesystemHandler struct {
 	allowedDirs []string
+	readOnly    bool
+	// maxReadBytes is the largest file read_file returns without allow_large
+	maxReadBytes int64
+	// symlinkPolicy controls how validatePath treats symlinks
+	symlinkPolicy SymlinkPolicy
+	// watches holds the paths watched by the watch tool
+	watches *watchManager
+	// limiter bounds the number of concurrent expensive operations
+	limiter limiter
+}
+
+// SymlinkPolicy controls how symlinks below the allowed directories are
+// treated when a path is validated
+type SymlinkPolicy string
+
+const (
+	// SymlinkFollow resolves symlinks and allows them as long as their target
+	// is within the allowed directories
+	SymlinkFollow SymlinkPolicy = "follow"
+	// SymlinkNoFollow operates on a symlink itself rather than its target, so
+	// that deleting or moving a link never touches the file it points to.
+	// Links whose target is outside the allowed directories are still refused
+	SymlinkNoFollow SymlinkPolicy = "no_follow"
+	// SymlinkReject refuses any path with a symlink below its allowed directory
+	SymlinkReject SymlinkPolicy = "reject"
+)
+
+// ParseSymlinkPolicy parses follow, no_follow or reject
+func ParseSymlinkPolicy(s string) (SymlinkPolicy, error) {
+	switch policy := SymlinkPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case SymlinkFollow, SymlinkNoFollow, SymlinkReject:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid symlink policy %q: must be follow, no_follow or reject", s)
+	}
+}
+
+// Option configures optional behaviour of a FilesystemHandler
+type Option func(*FilesystemHandler)
+
+// WithReadOnly rejects every tool call that would modify the file system
+func WithReadOnly(readOnly bool) Option {
+	return func(fs *FilesystemHandler) {
+		fs.readOnly = readOnly
+	}
+}
+
+// WithSymlinkPolicy sets how symlinks are treated. An empty policy keeps the
+// default of SymlinkFollow.
+func WithSymlinkPolicy(policy SymlinkPolicy) Option {
+	return func(fs *FilesystemHandler) {
+		if policy != "" {
+			fs.symlinkPolicy = policy
+		}
+	}
+}
+
+// WithMaxWatchers sets how many paths can be watched at once. Values <= 0
+// keep the default of DEFAULT_MAX_WATCHERS.
+func WithMaxWatchers(maxWatchers int) Option {
+	return func(fs *FilesystemHandler) {
+		if maxWatchers > 0 {
+			fs.watches.max = maxWatchers
+		}
+	}
+}
+
+// WithMaxReadBytes sets the largest file read_file returns whole unless
+// allow_large is set. Values <= 0 keep the default of MAX_INLINE_SIZE.
+func WithMaxReadBytes(maxReadBytes int64) Option {
+	return func(fs *FilesystemHandler) {
+		if maxReadBytes > 0 {
+			fs.maxReadBytes = maxReadBytes
+		}
+	}
 }
 
-func NewFilesystemHandler(allowedDirs []string) (*FilesystemHandler, error) {
+// NewFilesystemHandler creates a handler serving allowedDirs. A directory
+// reached through a symlink is also allowed under its resolved path, and
+// directories listed more than once are only kept once.
+func NewFilesystemHandler(allowedDirs []string, opts ...Option) (*FilesystemHandler, error) {
 	// Normalize and validate directories
 	normalized := make([]string, 0, len(allowedDirs))
+	seen := make(map[string]bool)
+	addDir := func(dir string) {
+		// Ensure the path ends with a separator to prevent prefix matching issues
+		// For example, /tmp/foo should not match /tmp/foobar
+		dir = filepath.Clean(dir) + string(filepath.Separator)
+		if !seen[dir] {
+			seen[dir] = true
+			normalized = append(normalized, dir)
+		}
+	}
 	for _, dir := range allowedDirs {
 		abs, err := filepath.Abs(dir)
 		if err != nil {
@@ -85,13 +221,51 @@ func NewFilesystemHandler(allowedDirs []string) (*FilesystemHandler, error) {
 			return nil, fmt.Errorf("path is not a directory: %s", abs)
 		}
 
-		// Ensure the path ends with a separator to prevent prefix matching issues
-		// For example, /tmp/foo should not match /tmp/foobar
-		normalized = append(normalized, filepath.Clean(abs)+string(filepath.Separator))
+		addDir(abs)
+		// Paths are validated after resolving symlinks, so the real location
+		// of the directory has to be allowed as well
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve symlinks for directory %s: %w", abs, err)
+		}
+		addDir(resolved)
+	}
+	fs := &FilesystemHandler{
+		allowedDirs:   normalized,
+		maxReadBytes:  MAX_INLINE_SIZE,
+		symlinkPolicy: SymlinkFollow,
+		watches:       newWatchManager(DEFAULT_MAX_WATCHERS),
+		limiter:       make(limiter, DEFAULT_MAX_CONCURRENCY),
+	}
+	for _, opt := range opts {
+		opt(fs)
+	}
+	return fs, nil
+}
+
+// allowedRoots returns the allowed directories without their trailing separator
+func (fs *FilesystemHandler) allowedRoots() []string {
+	roots := make([]string, len(fs.allowedDirs))
+	for i, dir := range fs.allowedDirs {
+		roots[i] = strings.TrimSuffix(dir, string(filepath.Separator))
+	}
+	return roots
+}
+
+// readOnlyError returns an error result if the handler is in read-only mode, nil otherwise
+func (fs *FilesystemHandler) readOnlyError() *mcp.CallToolResult {
+	if !fs.readOnly {
+		return nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: "Error: the server is running in read-only mode",
+			},
+		},
+		IsError: true,
 	}
-	return &FilesystemHandler{
-		allowedDirs: normalized,
-	}, nil
 }
 
 // isPathInAllowedDirs checks if a path is within any of the allowed directories
@@ -122,8 +296,9 @@ func (fs *FilesystemHandler) isPathInAllowedDirs(path string) bool {
 	return false
 }
 
-// buildTree builds a tree representation of the filesystem starting at the given path
-func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth int, followSymlinks bool) (*FileNode, error) {
+// buildTree builds a tree representation of the filesystem starting at the
+// given path. It stops with ctx's error once the request is cancelled.
+func (fs *FilesystemHandler) buildTree(ctx context.Context, path string, maxDepth int, currentDepth int, followSymlinks bool) (*FileNode, error) {
 	// Validate the path
 	validPath, err := fs.validatePath(path)
 	if err != nil {
@@ -157,6 +332,11 @@ func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth i
 
 			// Process each entry
 			for _, entry := range entries {
+				// Stop walking once the request is cancelled
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+
 				entryPath := filepath.Join(validPath, entry.Name())
 
 				// Handle symlinks
@@ -183,7 +363,10 @@ func (fs *FilesystemHandler) buildTree(path string, maxDepth int, currentDepth i
 				}
 
 				// Recursively build child node
-				childNode, err := fs.buildTree(entryPath, maxDepth, currentDepth+1, followSymlinks)
+				childNode, err := fs.buildTree(ctx, entryPath, maxDepth, currentDepth+1, followSymlinks)
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
 				if err != nil {
 					// Skip entries with errors
 					continue
@@ -216,23 +399,48 @@ func (fs *FilesystemHandler) validatePath(requestedPath string) (string, error)
 		)
 	}
 
+	switch fs.symlinkPolicy {
+	case SymlinkReject:
+		if link := fs.firstSymlink(abs); link != "" {
+			return "", fmt.Errorf("access denied - path contains a symlink: %s", link)
+		}
+	case SymlinkNoFollow:
+		if info, err := os.Lstat(abs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			// Resolve the parent only, so the link itself is operated on
+			realParent, err := filepath.EvalSymlinks(filepath.Dir(abs))
+			if err != nil {
+				return "", err
+			}
+			link := filepath.Join(realParent, filepath.Base(abs))
+			if !fs.isPathInAllowedDirs(link) {
+				return "", fmt.Errorf(
+					"access denied - parent directory outside allowed directories",
+				)
+			}
+			if err := fs.checkSymlinkTarget(link); err != nil {
+				return "", err
+			}
+			return link, nil
+		}
+	}
+
 	// Handle symlinks
 	realPath, err := filepath.EvalSymlinks(abs)
 	if err != nil {
 		if !os.IsNotExist(err) {
 			return "", err
 		}
-		// For new files, check parent directory
-		parent := filepath.Dir(abs)
-		realParent, err := filepath.EvalSymlinks(parent)
-		if err != nil {
-			return "", fmt.Errorf("parent directory does not exist: %s", parent)
+		// A dangling symlink would create its target when written through
+		if info, err := os.Lstat(abs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if err := fs.checkSymlinkTarget(abs); err != nil {
+				return "", err
+			}
 		}
-
-		if !fs.isPathInAllowedDirs(realParent) {
-			return "", fmt.Errorf(
-				"access denied - parent directory outside allowed directories",
-			)
+		// For new files, check the nearest existing ancestor, so that missing
+		// parent directories can be created but a symlink cannot be used to
+		// escape the allowed directories
+		if err := fs.checkNearestAncestor(abs); err != nil {
+			return "", err
 		}
 		return abs, nil
 	}
@@ -247,7 +455,104 @@ func (fs *FilesystemHandler) validatePath(requestedPath string) (string, error)
 	return realPath, nil
 }
 
+// checkNearestAncestor checks that the nearest existing ancestor of the
+// missing path resolves to a directory within the allowed directories
+func (fs *FilesystemHandler) checkNearestAncestor(path string) error {
+	ancestor := filepath.Dir(path)
+	for {
+		realAncestor, err := filepath.EvalSymlinks(ancestor)
+		if err == nil {
+			if !fs.isPathInAllowedDirs(realAncestor) {
+				return fmt.Errorf(
+					"access denied - parent directory outside allowed directories",
+				)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		next := filepath.Dir(ancestor)
+		if next == ancestor {
+			return fmt.Errorf("parent directory does not exist: %s", filepath.Dir(path))
+		}
+		ancestor = next
+	}
+}
+
+// checkSymlinkTarget checks that the symlink at link points, possibly
+// through further symlinks, to a path within the allowed directories. The
+// target does not need to exist.
+func (fs *FilesystemHandler) checkSymlinkTarget(link string) error {
+	target, err := os.Readlink(link)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(link), target)
+	}
+	target = filepath.Clean(target)
+
+	realTarget, err := filepath.EvalSymlinks(target)
+	if err == nil {
+		if !fs.isPathInAllowedDirs(realTarget) {
+			return fmt.Errorf(
+				"access denied - symlink target outside allowed directories",
+			)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	if !fs.isPathInAllowedDirs(target) {
+		return fmt.Errorf(
+			"access denied - symlink target outside allowed directories",
+		)
+	}
+	return fs.checkNearestAncestor(target)
+}
+
+// firstSymlink returns the first symlink among the existing components of
+// path below the allowed directory containing it, or "" if there is none.
+// The allowed directory itself may be a symlink.
+func (fs *FilesystemHandler) firstSymlink(path string) string {
+	root := ""
+	for _, dir := range fs.allowedDirs {
+		dir = strings.TrimSuffix(dir, string(filepath.Separator))
+		if (path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))) && len(dir) > len(root) {
+			root = dir
+		}
+	}
+	if root == "" {
+		return ""
+	}
+
+	link := ""
+	for p := path; len(p) > len(root); p = filepath.Dir(p) {
+		if info, err := os.Lstat(p); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			link = p
+		}
+	}
+	return link
+}
+
+// getFileStats stats path, following symlinks for the size, times and mode.
+// When path itself is a symlink its unresolved target is reported as well.
 func (fs *FilesystemHandler) getFileStats(path string) (FileInfo, error) {
+	linfo, err := os.Lstat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	var target string
+	isSymlink := linfo.Mode()&os.ModeSymlink != 0
+	if isSymlink {
+		if target, err = os.Readlink(path); err != nil {
+			return FileInfo{}, err
+		}
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return FileInfo{}, err
@@ -255,36 +560,119 @@ func (fs *FilesystemHandler) getFileStats(path string) (FileInfo, error) {
 
 	timespec := times.Get(info)
 
+	// Not every platform records a creation time
+	var created time.Time
+	if timespec.HasBirthTime() {
+		created = timespec.BirthTime()
+	}
+
 	return FileInfo{
-		Size:        info.Size(),
-		Created:     timespec.BirthTime(),
-		Modified:    timespec.ModTime(),
-		Accessed:    timespec.AccessTime(),
-		IsDirectory: info.IsDir(),
-		IsFile:      !info.IsDir(),
-		Permissions: fmt.Sprintf("%o", info.Mode().Perm()),
+		Size:          info.Size(),
+		Created:       created,
+		Modified:      timespec.ModTime(),
+		Accessed:      timespec.AccessTime(),
+		IsDirectory:   info.IsDir(),
+		IsFile:        info.Mode().IsRegular(),
+		IsSymlink:     isSymlink,
+		SymlinkTarget: target,
+		Mode:          info.Mode().String(),
+		Permissions:   fmt.Sprintf("%o", info.Mode().Perm()),
 	}, nil
 }
 
+// FileSearchOptions configure how search_files matches entries
+type FileSearchOptions struct {
+	// Regex interprets the pattern as a Go regular expression instead of a glob
+	Regex bool
+	// MatchPath matches the slash-separated path relative to the search root
+	// instead of the base name
+	MatchPath bool
+	// Exclude are globs of entries to prune from the walk, matched against
+	// the relative path and the base name
+	Exclude []string
+	// CaseInsensitive matches the pattern regardless of case
+	CaseInsensitive bool
+}
+
+// compileNameMatcher returns a function reporting whether a name or relative
+// path matches pattern
+func compileNameMatcher(pattern string, opts FileSearchOptions) (func(string) bool, error) {
+	if opts.Regex {
+		// Lowercasing a regular expression would change escapes such as \W,
+		// so use the case-insensitive flag instead
+		if opts.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return re.MatchString, nil
+	}
+
+	if opts.CaseInsensitive {
+		pattern = strings.ToLower(pattern)
+	}
+	globPattern, err := glob.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern: %w", err)
+	}
+	if opts.CaseInsensitive {
+		return func(name string) bool {
+			return globPattern.Match(strings.ToLower(name))
+		}, nil
+	}
+	return globPattern.Match, nil
+}
+
 func (fs *FilesystemHandler) searchFiles(
-	rootPath, pattern string,
+	ctx context.Context, rootPath, pattern string, opts FileSearchOptions,
 ) ([]string, error) {
 	var results []string
-	globPattern := glob.MustCompile(pattern)
+	match, err := compileNameMatcher(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileGlobs(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.Walk(
+	err = filepath.Walk(
 		rootPath,
 		func(path string, info os.FileInfo, err error) error {
+			// Stop walking once the request is cancelled
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
 			if err != nil {
 				return nil // Skip errors and continue
 			}
 
+			relPath, err := filepath.Rel(rootPath, path)
+			if err != nil {
+				return nil
+			}
+
+			// Prune excluded directories instead of filtering their contents
+			if path != rootPath && matchesAnyGlob(exclude, relPath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
 			// Try to validate path
 			if _, err := fs.validatePath(path); err != nil {
 				return nil // Skip invalid paths
 			}
 
-			if globPattern.Match(info.Name()) {
+			candidate := info.Name()
+			if opts.MatchPath {
+				candidate = filepath.ToSlash(relPath)
+			}
+
+			if match(candidate) {
 				results = append(results, path)
 			}
 			return nil
@@ -298,7 +686,7 @@ func (fs *FilesystemHandler) searchFiles(
 
 // searchWithinFiles searches for a substring within file contents
 func (fs *FilesystemHandler) searchWithinFiles(
-	rootPath, substring string, maxDepth int, maxResults int,
+	ctx context.Context, rootPath, substring string, maxDepth int, maxResults int,
 ) ([]SearchResult, error) {
 	var results []SearchResult
 	resultCount := 0
@@ -308,6 +696,10 @@ func (fs *FilesystemHandler) searchWithinFiles(
 	err := filepath.Walk(
 		rootPath,
 		func(path string, info os.FileInfo, err error) error {
+			// Stop walking once the request is cancelled
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
 			if err != nil {
 				return nil // Skip errors and continue
 			}
@@ -473,6 +865,16 @@ func isTextFile(mimeType string) bool {
 	return false
 }
 
+// looksBinary reports whether content is unlikely to be text: it contains a
+// NUL byte near the start or is not valid UTF-8
+func looksBinary(content []byte) bool {
+	sample := content[:min(len(content), binarySniffLength)]
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(content)
+}
+
 // isImageFile determines if a file is an image based on MIME type
 func isImageFile(mimeType string) bool {
 	return strings.HasPrefix(mimeType, "image/") ||
@@ -484,7 +886,8 @@ func pathToResourceURI(path string) string {
 	return "file://" + path
 }
 
-// Resource handler
+// Resource handler. It serves both the allowed directories, registered as
+// resources, and any file:// URI below them through the file resource template.
 func (fs *FilesystemHandler) handleReadResource(
 	ctx context.Context,
 	request mcp.ReadResourceRequest,
@@ -569,7 +972,7 @@ func (fs *FilesystemHandler) handleReadResource(
 	}
 
 	// Handle based on content type
-	if isTextFile(mimeType) {
+	if isTextFile(mimeType) && !looksBinary(content) {
 		// It's a text file, return as text
 		return []mcp.ResourceContents{
 			mcp.TextResourceContents{
@@ -604,6 +1007,144 @@ func (fs *FilesystemHandler) handleReadResource(
 
 // Tool handlers
 
+// readLineRange returns the 1-based, inclusive line range [start, end] of a
+// file without loading the whole file. Out-of-range values are clamped: a
+// start below 1 reads from the first line and an end of 0, or past the end of
+// the file, reads to the end. It returns the lines actually read and whether
+// the end of the file was reached.
+func readLineRange(path string, start, end int) (string, int, int, bool, error) {
+	if start < 1 {
+		start = 1
+	}
+	if end > 0 && end < start {
+		end = start
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, 0, false, err
+	}
+	defer file.Close()
+
+	var result strings.Builder
+	reader := bufio.NewReader(file)
+	lineNum := 0
+	for end <= 0 || lineNum < end {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lineNum++
+			if lineNum >= start {
+				result.WriteString(line)
+			}
+		}
+		if err == io.EOF {
+			return result.String(), start, lineNum, true, nil
+		}
+		if err != nil {
+			return "", 0, 0, false, err
+		}
+	}
+
+	// Reaching the last requested line exactly at the end of the file is
+	// still reported as the end of the file
+	_, err = reader.Peek(1)
+	return result.String(), start, lineNum, err == io.EOF, nil
+}
+
+// readByteRange seeks to offset and reads up to length bytes. Fewer bytes
+// are returned only when the end of the file is reached.
+func readByteRange(path string, offset, length int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, length)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// trimPartialRunes drops the incomplete UTF-8 sequences an arbitrary byte
+// range can start or end with, so they are not mistaken for binary content
+func trimPartialRunes(b []byte) []byte {
+	for i := 0; i < utf8.UTFMax-1 && len(b) > 0 && !utf8.RuneStart(b[0]); i++ {
+		b = b[1:]
+	}
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				b = b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// tailLines returns the last n lines of a file. It scans backwards from the
+// end of the file in fixed-size chunks, so only the tail is ever read.
+func tailLines(path string, n int) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	const chunkSize = 4096
+	offset := info.Size()
+	var tail []byte
+	for offset > 0 {
+		readSize := int64(chunkSize)
+		if offset < readSize {
+			readSize = offset
+		}
+		offset -= readSize
+		chunk := make([]byte, readSize)
+		if _, err := file.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return "", err
+		}
+		tail = append(chunk, tail...)
+
+		// A trailing newline terminates the last line rather than starting a new one
+		newlines := bytes.Count(tail, []byte{'\n'})
+		if bytes.HasSuffix(tail, []byte{'\n'}) {
+			newlines--
+		}
+		if newlines >= n {
+			break
+		}
+	}
+
+	// Drop everything before the n-th newline from the end
+	end := len(tail)
+	if bytes.HasSuffix(tail, []byte{'\n'}) {
+		end--
+	}
+	start := 0
+	for i, count := end-1, 0; i >= 0; i-- {
+		if tail[i] == '\n' {
+			count++
+			if count == n {
+				start = i + 1
+				break
+			}
+		}
+	}
+	return string(tail[start:]), nil
+}
+
 func (fs *FilesystemHandler) handleReadFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
@@ -682,72 +1223,254 @@ func (fs *FilesystemHandler) handleReadFile(
 	// Determine MIME type
 	mimeType := detectMimeType(validPath)
 
-	// Check file size
-	if info.Size() > MAX_INLINE_SIZE {
-		// File is too large to inline, return a resource reference
-		resourceURI := pathToResourceURI(validPath)
+	// A line range is read line by line, so it also works for text files too
+	// large to be returned whole
+	args := request.GetArguments()
+	_, hasStart := args["start_line"]
+	_, hasEnd := args["end_line"]
+	_, hasOffset := args["offset"]
+	_, hasLength := args["length"]
+	if (hasStart || hasEnd) && (hasOffset || hasLength) {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: fmt.Sprintf("File is too large to display inline (%d bytes). Access it via resource URI: %s", info.Size(), resourceURI),
-				},
-				mcp.EmbeddedResource{
-					Type: "resource",
-					Resource: mcp.TextResourceContents{
-						URI:      resourceURI,
-						MIMEType: "text/plain",
-						Text:     fmt.Sprintf("Large file: %s (%s, %d bytes)", validPath, mimeType, info.Size()),
-					},
+					Text: "Error: offset/length cannot be combined with start_line/end_line",
 				},
 			},
+			IsError: true,
 		}, nil
 	}
+	if (hasStart || hasEnd) && isTextFile(mimeType) {
+		startLine := request.GetInt("start_line", 1)
+		endLine := request.GetInt("end_line", 0)
 
-	// Read file content
-	content, err := os.ReadFile(validPath)
-	if err != nil {
+		text, first, last, eof, err := readLineRange(validPath, startLine, endLine)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error reading file: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		summary := fmt.Sprintf("Lines %d-%d of %s", first, last, validPath)
+		if last < first {
+			summary = fmt.Sprintf("No lines from line %d of %s", first, validPath)
+		}
+		if eof {
+			summary += " (end of file)"
+		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: fmt.Sprintf("Error reading file: %v", err),
+					Text: text,
+				},
+				mcp.TextContent{
+					Type: "text",
+					Text: summary,
 				},
 			},
-			IsError: true,
 		}, nil
 	}
 
-	// Check if it's a text file
-	if isTextFile(mimeType) {
-		// It's a text file, return as text
+	// mode overrides the text/binary detection when it guesses wrong
+	mode := request.GetString("mode", "auto")
+	if mode != "auto" && mode != "text" && mode != "binary" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: string(content),
+					Text: fmt.Sprintf("Error: invalid mode %q, expected auto, text or binary", mode),
 				},
 			},
+			IsError: true,
 		}, nil
-	} else if isImageFile(mimeType) {
-		// It's an image file, return as image content
-		if info.Size() <= MAX_BASE64_SIZE {
+	}
+
+	// A byte range is read with a single seek, for random access into files
+	// of any size
+	if hasOffset || hasLength {
+		offset := int64(request.GetInt("offset", 0))
+		if offset < 0 || offset > info.Size() {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{
 						Type: "text",
-						Text: fmt.Sprintf("Image file: %s (%s, %d bytes)", validPath, mimeType, info.Size()),
-					},
-					mcp.ImageContent{
-						Type:     "image",
-						Data:     base64.StdEncoding.EncodeToString(content),
-						MIMEType: mimeType,
+						Text: fmt.Sprintf("Error: offset %d is outside the file (%d bytes)", offset, info.Size()),
 					},
 				},
+				IsError: true,
 			}, nil
-		} else {
-			// Too large for base64, return a reference
-			resourceURI := pathToResourceURI(validPath)
+		}
+		length := info.Size() - offset
+		if hasLength {
+			length = int64(request.GetInt("length", 0))
+		}
+		if length < 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: invalid length %d", length),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		if length > info.Size()-offset {
+			length = info.Size() - offset
+		}
+		if length > fs.maxReadBytes && !request.GetBool("allow_large", false) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: range too large to read (%d bytes, limit %d bytes). Request a smaller length or set allow_large to read it anyway.", length, fs.maxReadBytes),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		content, err := readByteRange(validPath, offset, length)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error reading file: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+
+		asText := isTextFile(mimeType) && !looksBinary(trimPartialRunes(content))
+		switch mode {
+		case "text":
+			asText = true
+		case "binary":
+			asText = false
+		}
+
+		summary := fmt.Sprintf("Bytes %d-%d of %s (%d bytes total)", offset, offset+int64(len(content)), validPath, info.Size())
+		if asText {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: strings.ToValidUTF8(string(content), "\uFFFD"),
+					},
+					mcp.TextContent{
+						Type: "text",
+						Text: summary,
+					},
+				},
+			}, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: summary,
+				},
+				mcp.EmbeddedResource{
+					Type: "resource",
+					Resource: mcp.BlobResourceContents{
+						URI:      pathToResourceURI(validPath),
+						MIMEType: "application/octet-stream",
+						Blob:     base64.StdEncoding.EncodeToString(content),
+					},
+				},
+			},
+		}, nil
+	}
+
+	// Check file size before reading, so a huge file is never loaded by accident
+	if info.Size() > fs.maxReadBytes && !request.GetBool("allow_large", false) {
+		resourceURI := pathToResourceURI(validPath)
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: file too large to read (%d bytes, limit %d bytes). Read a range with start_line/end_line or offset/length, inspect it with peek_file, or set allow_large to read it anyway.", info.Size(), fs.maxReadBytes),
+				},
+				mcp.EmbeddedResource{
+					Type: "resource",
+					Resource: mcp.TextResourceContents{
+						URI:      resourceURI,
+						MIMEType: "text/plain",
+						Text:     fmt.Sprintf("Large file: %s (%s, %d bytes)", validPath, mimeType, info.Size()),
+					},
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Read file content
+	content, err := os.ReadFile(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error reading file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// The MIME type is mostly guessed from the file name, so also check the
+	// content itself before returning it as text
+	asText := isTextFile(mimeType) && !looksBinary(content)
+	switch mode {
+	case "text":
+		asText = true
+	case "binary":
+		asText = false
+	}
+	if !asText && isTextFile(mimeType) {
+		mimeType = http.DetectContentType(content)
+	}
+
+	// Check if it's a text file
+	if asText {
+		// It's a text file, return as text
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: strings.ToValidUTF8(string(content), "\uFFFD"),
+				},
+			},
+		}, nil
+	} else if isImageFile(mimeType) {
+		// It's an image file, return as image content
+		if info.Size() <= MAX_BASE64_SIZE {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Image file: %s (%s, %d bytes)", validPath, mimeType, info.Size()),
+					},
+					mcp.ImageContent{
+						Type:     "image",
+						Data:     base64.StdEncoding.EncodeToString(content),
+						MIMEType: mimeType,
+					},
+				},
+			}, nil
+		} else {
+			// Too large for base64, return a reference
+			resourceURI := pathToResourceURI(validPath)
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{
@@ -813,6 +1536,10 @@ func (fs *FilesystemHandler) handleWriteFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
 	path, err := request.RequireString("path")
 	if err != nil {
 		return nil, err
@@ -866,21 +1593,40 @@ func (fs *FilesystemHandler) handleWriteFile(
 		}, nil
 	}
 
-	// Create parent directories if they don't exist
+	// Create parent directories unless the caller asked us not to
 	parentDir := filepath.Dir(validPath)
-	if err := os.MkdirAll(parentDir, 0755); err != nil {
+	if request.GetBool("create_dirs", true) {
+		if err := os.MkdirAll(parentDir, 0755); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error creating parent directories: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	} else if info, err := os.Stat(parentDir); err != nil || !info.IsDir() {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: fmt.Sprintf("Error creating parent directories: %v", err),
+					Text: fmt.Sprintf("Error: parent directory %s does not exist", parentDir),
 				},
 			},
 			IsError: true,
 		}, nil
 	}
 
-	if err := os.WriteFile(validPath, []byte(content), 0644); err != nil {
+	// Keep the permissions of a file being overwritten
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(validPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	// Write through a temporary file so a failure never truncates the existing file
+	if err := writeFileAtomic(validPath, []byte(content), perm); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
@@ -900,7 +1646,7 @@ func (fs *FilesystemHandler) handleWriteFile(
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: fmt.Sprintf("Successfully wrote to %s", path),
+					Text: fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), path),
 				},
 			},
 		}, nil
@@ -925,6 +1671,210 @@ func (fs *FilesystemHandler) handleWriteFile(
 	}, nil
 }
 
+func (fs *FilesystemHandler) handleAppendToFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	content, err := request.RequireString("content")
+	if err != nil {
+		return nil, err
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Check if it's a directory
+	if info, err := os.Stat(validPath); err == nil && info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot append to a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// O_APPEND makes every write land at the current end of the file, so
+	// concurrent appends never overwrite each other
+	file, err := os.OpenFile(validPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error opening file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error appending to file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Successfully appended %d bytes to %s", len(content), path),
+				},
+			},
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Successfully appended %d bytes to %s (new size: %d bytes)", len(content), path, info.Size()),
+			},
+		},
+	}, nil
+}
+
+func (fs *FilesystemHandler) handleHeadFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	return fs.headOrTailFile(request, false)
+}
+
+func (fs *FilesystemHandler) handleTailFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	return fs.headOrTailFile(request, true)
+}
+
+// headOrTailFile returns the first or, with tail set, the last lines of a text file
+func (fs *FilesystemHandler) headOrTailFile(request mcp.CallToolRequest, tail bool) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	lines := request.GetInt("lines", DEFAULT_HEAD_TAIL_LINES)
+	if lines <= 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: lines must be positive",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot read lines of a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if !isTextFile(detectMimeType(validPath)) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot read lines of a binary file",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	var text string
+	if tail {
+		text, err = tailLines(validPath, lines)
+	} else {
+		text, _, _, _, err = readLineRange(validPath, 1, lines)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error reading file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}, nil
+}
+
 func (fs *FilesystemHandler) handleListDirectory(
 	ctx context.Context,
 	request mcp.CallToolRequest,
@@ -991,7 +1941,13 @@ func (fs *FilesystemHandler) handleListDirectory(
 		}, nil
 	}
 
-	entries, err := os.ReadDir(validPath)
+	recursive := request.GetBool("recursive", false)
+	maxEntries := request.GetInt("max_entries", DEFAULT_MAX_LIST_ENTRIES)
+	if maxEntries <= 0 {
+		maxEntries = DEFAULT_MAX_LIST_ENTRIES
+	}
+
+	entries, truncated, err := listDirectory(validPath, recursive, maxEntries)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1004,50 +1960,97 @@ func (fs *FilesystemHandler) handleListDirectory(
 		}, nil
 	}
 
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("Directory listing for: %s\n\n", validPath))
-
-	for _, entry := range entries {
-		entryPath := filepath.Join(validPath, entry.Name())
-		resourceURI := pathToResourceURI(entryPath)
+	jsonData, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
 
-		if entry.IsDir() {
-			result.WriteString(fmt.Sprintf("[DIR]  %s (%s)\n", entry.Name(), resourceURI))
-		} else {
-			info, err := entry.Info()
-			if err == nil {
-				result.WriteString(fmt.Sprintf("[FILE] %s (%s) - %d bytes\n",
-					entry.Name(), resourceURI, info.Size()))
-			} else {
-				result.WriteString(fmt.Sprintf("[FILE] %s (%s)\n", entry.Name(), resourceURI))
-			}
-		}
+	content := []mcp.Content{
+		mcp.TextContent{
+			Type: "text",
+			Text: string(jsonData),
+		},
+	}
+	if truncated {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Listing truncated after %d entries; raise max_entries to see more", maxEntries),
+		})
 	}
 
 	// Return both text content and embedded resource
 	resourceURI := pathToResourceURI(validPath)
 	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: result.String(),
-			},
-			mcp.EmbeddedResource{
-				Type: "resource",
-				Resource: mcp.TextResourceContents{
-					URI:      resourceURI,
-					MIMEType: "text/plain",
-					Text:     fmt.Sprintf("Directory: %s", validPath),
-				},
+		Content: append(content, mcp.EmbeddedResource{
+			Type: "resource",
+			Resource: mcp.TextResourceContents{
+				URI:      resourceURI,
+				MIMEType: "text/plain",
+				Text:     fmt.Sprintf("Directory: %s", validPath),
 			},
-		},
+		}),
 	}, nil
 }
 
+// listDirectory returns the entries of dir, descending into subdirectories
+// when recursive is set. Symlinks are listed but not followed. It stops after
+// maxEntries entries and reports whether the listing was truncated.
+func listDirectory(dir string, recursive bool, maxEntries int) ([]DirectoryEntry, bool, error) {
+	entries := []DirectoryEntry{}
+	errTruncated := errors.New("truncated")
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Only a failure to read the listed directory itself is fatal
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if path == dir {
+			return nil
+		}
+		if len(entries) >= maxEntries {
+			return errTruncated
+		}
+
+		entry := DirectoryEntry{Name: d.Name(), IsDir: d.IsDir()}
+		entry.Path, _ = filepath.Rel(dir, path)
+		if info, err := d.Info(); err == nil {
+			entry.Modified = info.ModTime()
+			if !d.IsDir() {
+				entry.Size = info.Size()
+			}
+		}
+		entries = append(entries, entry)
+
+		if d.IsDir() && !recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if errors.Is(err, errTruncated) {
+		return entries, true, nil
+	}
+	return entries, false, err
+}
+
 func (fs *FilesystemHandler) handleCreateDirectory(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
 	path, err := request.RequireString("path")
 	if err != nil {
 		return nil, err
@@ -1151,6 +2154,10 @@ func (fs *FilesystemHandler) handleCopyFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
 	source, err := request.RequireString("source")
 	if err != nil {
 		return nil, err
@@ -1257,14 +2264,41 @@ func (fs *FilesystemHandler) handleCopyFile(
 	}
 
 	// Perform the copy operation based on whether source is a file or directory
+	var files int
+	var bytes int64
 	if srcInfo.IsDir() {
+		if !request.GetBool("recursive", false) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: %s is a directory. Use recursive=true to copy directories.", source),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		// Copying a directory into itself would never terminate
+		if validDest == validSource || strings.HasPrefix(validDest, validSource+string(filepath.Separator)) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: cannot copy %s into itself", source),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+
 		// It's a directory, copy recursively
-		if err := copyDir(validSource, validDest); err != nil {
+		files, bytes, err = copyDir(validSource, validDest)
+		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{
 						Type: "text",
-						Text: fmt.Sprintf("Error copying directory: %v", err),
+						Text: fmt.Sprintf("Error copying directory after %d files: %v", files, err),
 					},
 				},
 				IsError: true,
@@ -1272,7 +2306,8 @@ func (fs *FilesystemHandler) handleCopyFile(
 		}
 	} else {
 		// It's a file, copy directly
-		if err := copyFile(validSource, validDest); err != nil {
+		bytes, err = copyFile(validSource, validDest)
+		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{
@@ -1283,6 +2318,7 @@ func (fs *FilesystemHandler) handleCopyFile(
 				IsError: true,
 			}, nil
 		}
+		files = 1
 	}
 
 	resourceURI := pathToResourceURI(validDest)
@@ -1291,9 +2327,11 @@ func (fs *FilesystemHandler) handleCopyFile(
 			mcp.TextContent{
 				Type: "text",
 				Text: fmt.Sprintf(
-					"Successfully copied %s to %s",
+					"Successfully copied %s to %s (%d files, %d bytes)",
 					source,
 					destination,
+					files,
+					bytes,
 				),
 			},
 			mcp.EmbeddedResource{
@@ -1309,55 +2347,61 @@ func (fs *FilesystemHandler) handleCopyFile(
 }
 
 // copyFile copies a single file from src to dst
-func copyFile(src, dst string) error {
+// copyFile streams src to dst, preserving the file mode, and returns the
+// number of bytes copied
+func copyFile(src, dst string) (int64, error) {
 	// Open the source file
 	sourceFile, err := os.Open(src)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer sourceFile.Close()
 
 	// Create the destination file
 	destFile, err := os.Create(dst)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer destFile.Close()
 
 	// Copy the contents
-	if _, err := io.Copy(destFile, sourceFile); err != nil {
-		return err
+	written, err := io.Copy(destFile, sourceFile)
+	if err != nil {
+		return written, err
 	}
 
 	// Get source file mode
 	sourceInfo, err := os.Stat(src)
 	if err != nil {
-		return err
+		return written, err
 	}
 
 	// Set the same file mode on destination
-	return os.Chmod(dst, sourceInfo.Mode())
+	return written, os.Chmod(dst, sourceInfo.Mode())
 }
 
-// copyDir recursively copies a directory tree from src to dst
-func copyDir(src, dst string) error {
+// copyDir recursively copies a directory tree from src to dst and returns the
+// number of files and bytes copied
+func copyDir(src, dst string) (int, int64, error) {
 	// Get properties of source dir
 	srcInfo, err := os.Stat(src)
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
 	// Create the destination directory with the same permissions
 	if err = os.MkdirAll(dst, srcInfo.Mode()); err != nil {
-		return err
+		return 0, 0, err
 	}
 
 	// Read directory entries
 	entries, err := os.ReadDir(src)
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
+	var files int
+	var bytes int64
 	for _, entry := range entries {
 		srcPath := filepath.Join(src, entry.Name())
 		dstPath := filepath.Join(dst, entry.Name())
@@ -1370,16 +2414,88 @@ func copyDir(src, dst string) error {
 
 		// Recursively copy subdirectories or copy files
 		if entry.IsDir() {
-			if err = copyDir(srcPath, dstPath); err != nil {
-				return err
+			subFiles, subBytes, err := copyDir(srcPath, dstPath)
+			files += subFiles
+			bytes += subBytes
+			if err != nil {
+				return files, bytes, err
 			}
 		} else {
-			if err = copyFile(srcPath, dstPath); err != nil {
-				return err
+			written, err := copyFile(srcPath, dstPath)
+			bytes += written
+			if err != nil {
+				return files, bytes, err
 			}
+			files++
+		}
+	}
+
+	return files, bytes, nil
+}
+
+// renameFile is os.Rename, replaceable in tests to simulate cross-device moves
+var renameFile = os.Rename
+
+// moveFile renames src to dst. Renames fail across file systems (EXDEV), in
+// which case src is copied to dst and then removed.
+func moveFile(src, dst string) error {
+	err := renameFile(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		_, _, err = copyDir(src, dst)
+	} else {
+		_, err = copyFile(src, dst)
+	}
+	if err != nil {
+		// Don't leave a partial copy behind; the source is still intact
+		if info.IsDir() {
+			os.RemoveAll(dst)
+		} else {
+			os.Remove(dst)
 		}
+		return fmt.Errorf("failed to copy across file systems: %w", err)
+	}
+	return os.RemoveAll(src)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
 	}
+	tmpPath := tmp.Name()
 
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
 	return nil
 }
 
@@ -1387,6 +2503,10 @@ func (fs *FilesystemHandler) handleMoveFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
 	source, err := request.RequireString("source")
 	if err != nil {
 		return nil, err
@@ -1471,6 +2591,33 @@ func (fs *FilesystemHandler) handleMoveFile(
 		}, nil
 	}
 
+	// Refuse to replace an existing destination unless asked to, and never
+	// replace a directory
+	if destInfo, err := os.Lstat(validDest); err == nil && validDest != validSource {
+		if !request.GetBool("overwrite", false) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: Destination already exists: %s (set overwrite to replace it)", destination),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		if destInfo.IsDir() {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: Destination is a directory and cannot be overwritten: %s", destination),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
 	// Create parent directory for destination if it doesn't exist
 	destDir := filepath.Dir(validDest)
 	if err := os.MkdirAll(destDir, 0755); err != nil {
@@ -1485,7 +2632,7 @@ func (fs *FilesystemHandler) handleMoveFile(
 		}, nil
 	}
 
-	if err := os.Rename(validSource, validDest); err != nil {
+	if err := moveFile(validSource, validDest); err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				mcp.TextContent{
@@ -1590,7 +2737,14 @@ func (fs *FilesystemHandler) handleSearchFiles(
 		}, nil
 	}
 
-	results, err := fs.searchFiles(validPath, pattern)
+	opts := FileSearchOptions{
+		Regex:           request.GetBool("regex", false),
+		MatchPath:       request.GetBool("match_path", false),
+		Exclude:         request.GetStringSlice("exclude", nil),
+		CaseInsensitive: request.GetBool("case_insensitive", false),
+	}
+
+	results, err := fs.searchFiles(ctx, validPath, pattern, opts)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1672,16 +2826,10 @@ func (fs *FilesystemHandler) handleTree(
 	}
 
 	// Extract depth parameter (optional, default: 3)
-	depth := 3 // Default value
-	if depthParam, err := request.RequireFloat("depth"); err != nil {
-		depth = int(depthParam)
-	}
+	depth := request.GetInt("depth", DEFAULT_TREE_DEPTH)
 
 	// Extract follow_symlinks parameter (optional, default: false)
-	followSymlinks := false // Default value
-	if followParam, err := request.RequireBool("follow_symlinks"); err != nil {
-		followSymlinks = followParam
-	}
+	followSymlinks := request.GetBool("follow_symlinks", false)
 
 	// Validate the path is within allowed directories
 	validPath, err := fs.validatePath(path)
@@ -1724,7 +2872,7 @@ func (fs *FilesystemHandler) handleTree(
 	}
 
 	// Build the tree structure
-	tree, err := fs.buildTree(validPath, depth, 0, followSymlinks)
+	tree, err := fs.buildTree(ctx, validPath, depth, 0, followSymlinks)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1773,6 +2921,156 @@ func (fs *FilesystemHandler) handleTree(
 	}, nil
 }
 
+// buildDirectoryTree returns the names below dir up to maxDepth levels deep.
+// Symlinks are reported but not followed, and unreadable directories are
+// reported without children. It stops with ctx's error once the request is
+// cancelled.
+func buildDirectoryTree(ctx context.Context, dir string, maxDepth int, includeHidden bool) (*DirectoryTreeNode, error) {
+	root := &DirectoryTreeNode{Name: filepath.Base(dir), Type: "directory"}
+	if err := fillDirectoryTree(ctx, root, dir, maxDepth, includeHidden); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func fillDirectoryTree(ctx context.Context, node *DirectoryTreeNode, dir string, depth int, includeHidden bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	for _, entry := range entries {
+		// Stop walking once the request is cancelled
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !includeHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if depth <= 0 {
+			node.Truncated = true
+			return nil
+		}
+
+		child := &DirectoryTreeNode{Name: entry.Name(), Type: "file"}
+		switch {
+		case entry.Type()&os.ModeSymlink != 0:
+			child.Type = "symlink"
+		case entry.IsDir():
+			child.Type = "directory"
+			if err := fillDirectoryTree(ctx, child, filepath.Join(dir, entry.Name()), depth-1, includeHidden); err != nil {
+				return err
+			}
+		}
+		node.Children = append(node.Children, child)
+	}
+	return nil
+}
+
+func (fs *FilesystemHandler) handleDirectoryTree(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+
+	// Handle empty or relative paths like "." or "./" by converting to absolute path
+	if path == "." || path == "./" {
+		// Get current working directory
+		cwd, err := os.Getwd()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error resolving current directory: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		path = cwd
+	}
+
+	maxDepth := request.GetInt("max_depth", DEFAULT_TREE_DEPTH)
+	includeHidden := request.GetBool("include_hidden", false)
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if !info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: The specified path is not a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	tree, err := buildDirectoryTree(ctx, validPath, maxDepth, includeHidden)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error building directory tree: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	jsonData, err := json.Marshal(tree)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+	}, nil
+}
+
 func (fs *FilesystemHandler) handleGetFileInfo(
 	ctx context.Context,
 	request mcp.CallToolRequest,
@@ -1813,7 +3111,14 @@ func (fs *FilesystemHandler) handleGetFileInfo(
 		}, nil
 	}
 
-	info, err := fs.getFileStats(validPath)
+	// validPath has symlinks resolved, so stat the requested path to be able
+	// to report whether it is a symlink itself
+	statPath, err := filepath.Abs(path)
+	if err != nil {
+		statPath = validPath
+	}
+
+	info, err := fs.getFileStats(statPath)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -1826,10 +3131,20 @@ func (fs *FilesystemHandler) handleGetFileInfo(
 		}, nil
 	}
 
-	// Get MIME type for files
-	mimeType := "directory"
-	if info.IsFile {
-		mimeType = detectMimeType(validPath)
+	// Get MIME type for files
+	mimeType := "directory"
+	if !info.IsDirectory {
+		mimeType = detectMimeType(validPath)
+	}
+
+	created := "unavailable"
+	if !info.Created.IsZero() {
+		created = info.Created.Format(time.RFC3339)
+	}
+
+	symlinkText := fmt.Sprintf("IsSymlink: %v", info.IsSymlink)
+	if info.IsSymlink {
+		symlinkText += fmt.Sprintf("\nSymlinkTarget: %s", info.SymlinkTarget)
 	}
 
 	resourceURI := pathToResourceURI(validPath)
@@ -1847,14 +3162,16 @@ func (fs *FilesystemHandler) handleGetFileInfo(
 			mcp.TextContent{
 				Type: "text",
 				Text: fmt.Sprintf(
-					"File information for: %s\n\nSize: %d bytes\nCreated: %s\nModified: %s\nAccessed: %s\nIsDirectory: %v\nIsFile: %v\nPermissions: %s\nMIME Type: %s\nResource URI: %s",
-					validPath,
+					"File information for: %s\n\nSize: %d bytes\nCreated: %s\nModified: %s\nAccessed: %s\nIsDirectory: %v\nIsFile: %v\n%s\nMode: %s\nPermissions: %s\nMIME Type: %s\nResource URI: %s",
+					statPath,
 					info.Size,
-					info.Created.Format(time.RFC3339),
+					created,
 					info.Modified.Format(time.RFC3339),
 					info.Accessed.Format(time.RFC3339),
 					info.IsDirectory,
 					info.IsFile,
+					symlinkText,
+					info.Mode,
 					info.Permissions,
 					mimeType,
 					resourceURI,
@@ -1911,149 +3228,138 @@ func (fs *FilesystemHandler) handleReadMultipleFiles(
 		}, nil
 	}
 
-	// Process each file
-	var results []mcp.Content
+	maxTotalSize := int64(request.GetInt("max_total_size", MAX_MULTI_READ_SIZE))
+	if maxTotalSize <= 0 {
+		maxTotalSize = MAX_MULTI_READ_SIZE
+	}
+
+	// Process each file, recording failures per path rather than aborting
+	results := make(map[string]MultiFileResult, len(pathsSlice))
+	var attachments []mcp.Content
+	remaining := maxTotalSize
 	for _, path := range pathsSlice {
-		// Handle empty or relative paths like "." or "./" by converting to absolute path
-		if path == "." || path == "./" {
-			// Get current working directory
-			cwd, err := os.Getwd()
-			if err != nil {
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Error resolving current directory for path '%s': %v", path, err),
-				})
-				continue
-			}
-			path = cwd
+		result, attachment := fs.readFileForBatch(path, remaining)
+		if result.Error == "" {
+			remaining -= result.Size
 		}
-
-		validPath, err := fs.validatePath(path)
-		if err != nil {
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Error with path '%s': %v", path, err),
-			})
-			continue
+		results[path] = result
+		if attachment != nil {
+			attachments = append(attachments, attachment)
 		}
+	}
 
-		// Check if it's a directory
-		info, err := os.Stat(validPath)
-		if err != nil {
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Error accessing '%s': %v", path, err),
-			})
-			continue
-		}
+	jsonData, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
 
-		if info.IsDir() {
-			// For directories, return a resource reference instead
-			resourceURI := pathToResourceURI(validPath)
-			results = append(results, mcp.TextContent{
+	return &mcp.CallToolResult{
+		Content: append([]mcp.Content{
+			mcp.TextContent{
 				Type: "text",
-				Text: fmt.Sprintf("'%s' is a directory. Use list_directory tool or resource URI: %s", path, resourceURI),
-			})
-			continue
+				Text: string(jsonData),
+			},
+		}, attachments...),
+	}, nil
+}
+
+// readFileForBatch reads a single file for read_multiple_files. Files larger
+// than remaining bytes are skipped. Images and small binary files are
+// returned as a separate content block alongside their result.
+func (fs *FilesystemHandler) readFileForBatch(path string, remaining int64) (MultiFileResult, mcp.Content) {
+	// Handle empty or relative paths like "." or "./" by converting to absolute path
+	if path == "." || path == "./" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return MultiFileResult{Error: fmt.Sprintf("error resolving current directory: %v", err)}, nil
 		}
+		path = cwd
+	}
 
-		// Determine MIME type
-		mimeType := detectMimeType(validPath)
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return MultiFileResult{Error: err.Error()}, nil
+	}
 
-		// Check file size
-		if info.Size() > MAX_INLINE_SIZE {
-			// File is too large to inline, return a resource reference
-			resourceURI := pathToResourceURI(validPath)
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("File '%s' is too large to display inline (%d bytes). Access it via resource URI: %s",
-					path, info.Size(), resourceURI),
-			})
-			continue
-		}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return MultiFileResult{Error: fmt.Sprintf("error accessing file: %v", err)}, nil
+	}
 
-		// Read file content
-		content, err := os.ReadFile(validPath)
-		if err != nil {
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: fmt.Sprintf("Error reading file '%s': %v", path, err),
-			})
-			continue
-		}
+	resourceURI := pathToResourceURI(validPath)
+	if info.IsDir() {
+		return MultiFileResult{
+			Error:       "path is a directory, use the list_directory tool instead",
+			ResourceURI: resourceURI,
+		}, nil
+	}
 
-		// Add file header
-		results = append(results, mcp.TextContent{
-			Type: "text",
-			Text: fmt.Sprintf("--- File: %s ---", path),
-		})
+	mimeType := detectMimeType(validPath)
+	result := MultiFileResult{
+		MimeType:    mimeType,
+		Size:        info.Size(),
+		ResourceURI: resourceURI,
+	}
 
-		// Check if it's a text file
-		if isTextFile(mimeType) {
-			// It's a text file, return as text
-			results = append(results, mcp.TextContent{
-				Type: "text",
-				Text: string(content),
-			})
-		} else if isImageFile(mimeType) {
-			// It's an image file, return as image content
-			if info.Size() <= MAX_BASE64_SIZE {
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Image file: %s (%s, %d bytes)", path, mimeType, info.Size()),
-				})
-				results = append(results, mcp.ImageContent{
-					Type:     "image",
-					Data:     base64.StdEncoding.EncodeToString(content),
-					MIMEType: mimeType,
-				})
-			} else {
-				// Too large for base64, return a reference
-				resourceURI := pathToResourceURI(validPath)
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Image file '%s' is too large to display inline (%d bytes). Access it via resource URI: %s",
-						path, info.Size(), resourceURI),
-				})
-			}
-		} else {
-			// It's another type of binary file
-			resourceURI := pathToResourceURI(validPath)
+	if info.Size() > MAX_INLINE_SIZE {
+		result.Error = fmt.Sprintf("file is too large to display inline (%d bytes), access it via its resource URI", info.Size())
+		return result, nil
+	}
+	if !isTextFile(mimeType) && info.Size() > MAX_BASE64_SIZE {
+		result.Error = fmt.Sprintf("binary file is too large to display inline (%d bytes), access it via its resource URI", info.Size())
+		return result, nil
+	}
+	if info.Size() > remaining {
+		result.Error = fmt.Sprintf("skipped, the response size limit was reached (%d bytes remaining)", remaining)
+		return result, nil
+	}
 
-			if info.Size() <= MAX_BASE64_SIZE {
-				// Small enough for base64 encoding
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Binary file: %s (%s, %d bytes)", path, mimeType, info.Size()),
-				})
-				results = append(results, mcp.EmbeddedResource{
-					Type: "resource",
-					Resource: mcp.BlobResourceContents{
-						URI:      resourceURI,
-						MIMEType: mimeType,
-						Blob:     base64.StdEncoding.EncodeToString(content),
-					},
-				})
-			} else {
-				// Too large for base64, return a reference
-				results = append(results, mcp.TextContent{
-					Type: "text",
-					Text: fmt.Sprintf("Binary file '%s' (%s, %d bytes). Access it via resource URI: %s",
-						path, mimeType, info.Size(), resourceURI),
-				})
-			}
+	content, err := os.ReadFile(validPath)
+	if err != nil {
+		result.Error = fmt.Sprintf("error reading file: %v", err)
+		return result, nil
+	}
+
+	switch {
+	case isTextFile(mimeType):
+		result.Content = string(content)
+		return result, nil
+	case isImageFile(mimeType):
+		result.Attached = true
+		return result, mcp.ImageContent{
+			Type:     "image",
+			Data:     base64.StdEncoding.EncodeToString(content),
+			MIMEType: mimeType,
+		}
+	default:
+		result.Attached = true
+		return result, mcp.EmbeddedResource{
+			Type: "resource",
+			Resource: mcp.BlobResourceContents{
+				URI:      resourceURI,
+				MIMEType: mimeType,
+				Blob:     base64.StdEncoding.EncodeToString(content),
+			},
 		}
 	}
-
-	return &mcp.CallToolResult{
-		Content: results,
-	}, nil
 }
 
 func (fs *FilesystemHandler) handleDeleteFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
 	path, err := request.RequireString("path")
 	if err != nil {
 		return nil, err
@@ -2114,26 +3420,51 @@ func (fs *FilesystemHandler) handleDeleteFile(
 		}, nil
 	}
 
-	// Extract recursive parameter (optional, default: false)
-	recursive := false
-	if recursiveParam, err := request.RequireBool("recursive"); err != nil {
-		recursive = recursiveParam
+	// Never delete one of the allowed directories themselves
+	if fs.isAllowedRoot(validPath) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %s is an allowed directory and cannot be deleted", path),
+				},
+			},
+			IsError: true,
+		}, nil
 	}
 
+	// Extract recursive parameter (optional, default: false)
+	recursive := request.GetBool("recursive", false)
+
 	// Check if it's a directory and handle accordingly
 	if info.IsDir() {
 		if !recursive {
+			// os.Remove only deletes empty directories
+			if err := os.Remove(validPath); err != nil {
+				return &mcp.CallToolResult{
+					Content: []mcp.Content{
+						mcp.TextContent{
+							Type: "text",
+							Text: fmt.Sprintf("Error: %s is not an empty directory. Use recursive=true to delete it with its contents. (%v)", path, err),
+						},
+					},
+					IsError: true,
+				}, nil
+			}
+
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					mcp.TextContent{
 						Type: "text",
-						Text: fmt.Sprintf("Error: %s is a directory. Use recursive=true to delete directories.", path),
+						Text: fmt.Sprintf("Successfully deleted empty directory %s", path),
 					},
 				},
-				IsError: true,
 			}, nil
 		}
 
+		// Count the contents first so we can report what was deleted
+		files, dirs := countTree(validPath)
+
 		// It's a directory and recursive is true, so remove it
 		if err := os.RemoveAll(validPath); err != nil {
 			return &mcp.CallToolResult{
@@ -2151,7 +3482,7 @@ func (fs *FilesystemHandler) handleDeleteFile(
 			Content: []mcp.Content{
 				mcp.TextContent{
 					Type: "text",
-					Text: fmt.Sprintf("Successfully deleted directory %s", path),
+					Text: fmt.Sprintf("Successfully deleted directory %s containing %d files and %d subdirectories", path, files, dirs),
 				},
 			},
 		}, nil
@@ -2174,17 +3505,59 @@ func (fs *FilesystemHandler) handleDeleteFile(
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
-				Text: fmt.Sprintf("Successfully deleted file %s", path),
+				Text: fmt.Sprintf("Successfully deleted file %s (%d bytes)", path, info.Size()),
 			},
 		},
 	}, nil
 }
 
+// isAllowedRoot reports whether path is one of the allowed directories
+func (fs *FilesystemHandler) isAllowedRoot(path string) bool {
+	candidates := []string{filepath.Clean(path)}
+	if realPath, err := filepath.EvalSymlinks(path); err == nil {
+		candidates = append(candidates, realPath)
+	}
+
+	for _, dir := range fs.allowedDirs {
+		root := filepath.Clean(dir)
+		realRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			realRoot = root
+		}
+		for _, candidate := range candidates {
+			if candidate == root || candidate == realRoot {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countTree counts the files and subdirectories below root without following symlinks
+func countTree(root string) (files, dirs int) {
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		if d.IsDir() {
+			dirs++
+		} else {
+			files++
+		}
+		return nil
+	})
+	return files, dirs
+}
+
 // handleModifyFile handles the modify_file tool request
 func (fs *FilesystemHandler) handleModifyFile(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
 	// Extract arguments
 	path, err := request.RequireString("path")
 	if err != nil {
@@ -2497,7 +3870,7 @@ func (fs *FilesystemHandler) handleSearchWithinFiles(
 	}
 
 	// Perform the search
-	results, err := fs.searchWithinFiles(validPath, substring, maxDepth, maxResults)
+	results, err := fs.searchWithinFiles(ctx, validPath, substring, maxDepth, maxResults)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -2578,22 +3951,165 @@ func (fs *FilesystemHandler) handleSearchWithinFiles(
 	}, nil
 }
 
-func (fs *FilesystemHandler) handleListAllowedDirectories(
+func (fs *FilesystemHandler) handleSearchContent(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
-	// Remove the trailing separator for display purposes
-	displayDirs := make([]string, len(fs.allowedDirs))
-	for i, dir := range fs.allowedDirs {
-		displayDirs[i] = strings.TrimSuffix(dir, string(filepath.Separator))
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := request.RequireString("pattern")
+	if err != nil {
+		return nil, err
+	}
+	if pattern == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: pattern cannot be empty",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	opts := ContentSearchOptions{
+		Pattern:         pattern,
+		Regex:           request.GetBool("regex", false),
+		CaseInsensitive: request.GetBool("case_insensitive", false),
+		Include:         request.GetStringSlice("include", nil),
+		Exclude:         request.GetStringSlice("exclude", nil),
+		IncludeBinary:   request.GetBool("include_binary", false),
+		MaxResults:      request.GetInt("max_results", MAX_SEARCH_RESULTS),
+	}
+	if opts.MaxResults <= 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: max_results must be positive",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Handle empty or relative paths like "." or "./" by converting to absolute path
+	if path == "." || path == "./" {
+		// Get current working directory
+		cwd, err := os.Getwd()
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error resolving current directory: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		path = cwd
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if !info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: search path must be a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	matches, truncated, err := fs.searchContent(ctx, validPath, opts)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error searching file contents: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	jsonData, err := json.MarshalIndent(matches, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	content := []mcp.Content{
+		mcp.TextContent{
+			Type: "text",
+			Text: string(jsonData),
+		},
+	}
+	if truncated {
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Results limited to %d matches. There may be more occurrences.", opts.MaxResults),
+		})
 	}
 
+	return &mcp.CallToolResult{Content: content}, nil
+}
+
+func (fs *FilesystemHandler) handleListAllowedDirectories(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
 	var result strings.Builder
 	result.WriteString("Allowed directories:\n\n")
 
-	for _, dir := range displayDirs {
+	for _, dir := range fs.allowedRoots() {
 		resourceURI := pathToResourceURI(dir)
 		result.WriteString(fmt.Sprintf("%s (%s)\n", dir, resourceURI))
+
+		// Paths are checked after resolving symlinks, so show where a
+		// symlinked root really points to as well
+		if realDir, err := filepath.EvalSymlinks(dir); err == nil && realDir != dir {
+			result.WriteString(fmt.Sprintf("  resolves to: %s\n", realDir))
+		}
 	}
 
 	return &mcp.CallToolResult{
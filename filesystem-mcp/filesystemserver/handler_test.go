@@ -2,9 +2,13 @@ package filesystemserver
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -12,6 +16,35 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestNewFilesystemHandler_AllowedDirs(t *testing.T) {
+	dir := t.TempDir()
+	realDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	// the temp dir may itself be behind a symlink, e.g. on macOS
+	linkDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	link := filepath.Join(linkDir, "link")
+	require.NoError(t, os.Symlink(dir, link))
+
+	// duplicates, trailing separators and symlinks to the same directory
+	// collapse to the directory and its resolved path
+	handler, err := NewFilesystemHandler([]string{dir, dir + string(filepath.Separator), link, realDir})
+	require.NoError(t, err)
+
+	sep := string(filepath.Separator)
+	want := []string{dir + sep}
+	if realDir != dir {
+		want = append(want, realDir+sep)
+	}
+	want = append(want, link+sep)
+	assert.Equal(t, want, handler.allowedDirs)
+
+	// paths through the link are allowed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("x"), 0644))
+	_, err = handler.validatePath(filepath.Join(link, "file.txt"))
+	assert.NoError(t, err)
+}
+
 func TestReadfile_Valid(t *testing.T) {
 	// prepare temp directory
 	dir := t.TempDir()
@@ -134,6 +167,25 @@ func TestSearchFiles_Pattern(t *testing.T) {
 	}
 }
 
+func TestReadOnlyMode(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithReadOnly(true))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "write_file"
+	request.Params.Arguments = map[string]any{
+		"path":    filepath.Join(dir, "test"),
+		"content": "test-content",
+	}
+
+	result, err := handler.handleWriteFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, fmt.Sprint(result.Content[0]), "read-only mode")
+	assert.NoFileExists(t, filepath.Join(dir, "test"))
+}
+
 // resolveAllowedDirs generates a list of allowed paths, including their resolved symlinks.
 // This ensures both the original paths and their symlink-resolved counterparts are included,
 // which is useful when paths may be symlinks (e.g., t.TempDir() on some Unix systems).
@@ -152,3 +204,842 @@ func resolveAllowedDirs(t *testing.T, dirs ...string) []string {
 	}
 	return allowedDirs
 }
+
+func TestWriteFile_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "config.txt")
+	require.NoError(t, os.WriteFile(target, []byte("old content"), 0600))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "write_file"
+	request.Params.Arguments = map[string]any{
+		"path":    target,
+		"content": "new content",
+	}
+
+	result, err := handler.handleWriteFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Successfully wrote 11 bytes")
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new content", string(data))
+
+	// the overwritten file keeps its permissions and no temporary files are left behind
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFile_CreateDirs(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "write_file"
+	request.Params.Arguments = map[string]any{
+		"path":        filepath.Join(dir, "a", "b", "file.txt"),
+		"content":     "content",
+		"create_dirs": false,
+	}
+
+	result, err := handler.handleWriteFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "does not exist")
+
+	request.Params.Arguments.(map[string]any)["create_dirs"] = true
+	result, err = handler.handleWriteFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.FileExists(t, filepath.Join(dir, "a", "b", "file.txt"))
+}
+
+func TestValidatePath_MissingParentsBehindSymlink(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "link")))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	_, err = handler.validatePath(filepath.Join(dir, "link", "new", "file.txt"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+func TestMoveFile_Overwrite(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.txt")
+	destination := filepath.Join(dir, "destination.txt")
+	require.NoError(t, os.WriteFile(source, []byte("new"), 0644))
+	require.NoError(t, os.WriteFile(destination, []byte("old"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "move_file"
+	request.Params.Arguments = map[string]any{
+		"source":      source,
+		"destination": destination,
+	}
+
+	result, err := handler.handleMoveFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "already exists")
+	assert.FileExists(t, source)
+
+	request.Params.Arguments.(map[string]any)["overwrite"] = true
+	result, err = handler.handleMoveFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.NoFileExists(t, source)
+	data, err := os.ReadFile(destination)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
+
+func TestMoveFile_CrossDevice(t *testing.T) {
+	defer func(original func(string, string) error) { renameFile = original }(renameFile)
+	renameFile = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "tree")
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "sub", "file.txt"), []byte("content"), 0644))
+
+	destination := filepath.Join(dir, "moved")
+	require.NoError(t, moveFile(source, destination))
+
+	assert.NoDirExists(t, source)
+	data, err := os.ReadFile(filepath.Join(destination, "sub", "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+}
+
+func TestDeleteFile_Directories(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "full", "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "full", "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "full", "sub", "b.txt"), []byte("b"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	deleteFile := func(path string, recursive bool) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "delete_file"
+		request.Params.Arguments = map[string]any{
+			"path":      path,
+			"recursive": recursive,
+		}
+		result, err := handler.handleDeleteFile(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := deleteFile(filepath.Join(dir, "empty"), false)
+	require.False(t, result.IsError)
+	assert.NoDirExists(t, filepath.Join(dir, "empty"))
+
+	result = deleteFile(filepath.Join(dir, "full"), false)
+	assert.True(t, result.IsError)
+	assert.DirExists(t, filepath.Join(dir, "full"))
+
+	result = deleteFile(filepath.Join(dir, "full"), true)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "2 files and 1 subdirectories")
+	assert.NoDirExists(t, filepath.Join(dir, "full"))
+}
+
+func TestDeleteFile_RefusesAllowedRoot(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("keep"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "delete_file"
+	request.Params.Arguments = map[string]any{
+		"path":      dir,
+		"recursive": true,
+	}
+	result, err := handler.handleDeleteFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "cannot be deleted")
+	assert.FileExists(t, filepath.Join(dir, "keep.txt"))
+}
+
+func TestCopyFile_Recursive(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "tree")
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "a.txt"), []byte("abc"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "sub", "run.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "copy_file"
+	request.Params.Arguments = map[string]any{
+		"source":      source,
+		"destination": filepath.Join(dir, "copy"),
+	}
+
+	result, err := handler.handleCopyFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "recursive=true")
+
+	request.Params.Arguments.(map[string]any)["recursive"] = true
+	result, err = handler.handleCopyFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "(2 files, 13 bytes)")
+
+	info, err := os.Stat(filepath.Join(dir, "copy", "sub", "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	// a directory cannot be copied into itself
+	request.Params.Arguments.(map[string]any)["destination"] = filepath.Join(source, "sub", "nested")
+	result, err = handler.handleCopyFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "into itself")
+}
+
+func TestListDirectory_JSON(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", "deeper"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("abc"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("hello"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "list_directory"
+	request.Params.Arguments = map[string]any{
+		"path": dir,
+	}
+
+	result, err := handler.handleListDirectory(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var entries []DirectoryEntry
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a.txt", entries[0].Name)
+	assert.False(t, entries[0].IsDir)
+	assert.Equal(t, int64(3), entries[0].Size)
+	assert.False(t, entries[0].Modified.IsZero())
+	assert.Equal(t, "sub", entries[1].Name)
+	assert.True(t, entries[1].IsDir)
+
+	request.Params.Arguments.(map[string]any)["recursive"] = true
+	result, err = handler.handleListDirectory(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	entries = nil
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &entries))
+	var paths []string
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	assert.Equal(t, []string{"a.txt", "sub", filepath.Join("sub", "b.txt"), filepath.Join("sub", "deeper")}, paths)
+
+	// max_entries caps the listing and says so
+	request.Params.Arguments.(map[string]any)["max_entries"] = 2
+	result, err = handler.handleListDirectory(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	entries = nil
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &entries))
+	assert.Len(t, entries, 2)
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "truncated after 2 entries")
+}
+
+func TestGetFileInfo_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "target.txt"), []byte("abc"), 0640))
+	require.NoError(t, os.Symlink("target.txt", filepath.Join(dir, "link")))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_file_info"
+	request.Params.Arguments = map[string]any{
+		"path": filepath.Join(dir, "link"),
+	}
+
+	result, err := handler.handleGetFileInfo(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Size: 3 bytes")
+	assert.Contains(t, text, "IsSymlink: true\nSymlinkTarget: target.txt")
+	assert.Contains(t, text, "Mode: -rw-r-----")
+	assert.Contains(t, text, "Permissions: 640")
+
+	request.Params.Arguments.(map[string]any)["path"] = filepath.Join(dir, "target.txt")
+	result, err = handler.handleGetFileInfo(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "IsSymlink: false")
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "SymlinkTarget")
+}
+
+func TestReadMultipleFiles_PerPathResults(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("aaaa"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("bbbb"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.txt"), []byte("cc"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	missing := filepath.Join(dir, "missing.txt")
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "read_multiple_files"
+	request.Params.Arguments = map[string]any{
+		"paths": []any{
+			filepath.Join(dir, "a.txt"),
+			missing,
+			outside,
+			filepath.Join(dir, "b.txt"),
+			filepath.Join(dir, "c.txt"),
+		},
+		"max_total_size": 6,
+	}
+
+	result, err := handler.handleReadMultipleFiles(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var results map[string]MultiFileResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &results))
+	require.Len(t, results, 5)
+	assert.Equal(t, "aaaa", results[filepath.Join(dir, "a.txt")].Content)
+	assert.Contains(t, results[missing].Error, "no such file or directory")
+	assert.Contains(t, results[outside].Error, "access denied")
+	// b.txt would exceed the size cap, the smaller c.txt still fits
+	assert.Contains(t, results[filepath.Join(dir, "b.txt")].Error, "size limit")
+	assert.Empty(t, results[filepath.Join(dir, "b.txt")].Content)
+	assert.Equal(t, "cc", results[filepath.Join(dir, "c.txt")].Content)
+}
+
+func TestReadFile_LineRange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\nthree\nfour\nfive\n"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		expected string
+		summary  string
+	}{
+		{"middle", map[string]any{"start_line": 2, "end_line": 3}, "two\nthree\n", "Lines 2-3"},
+		{"to end", map[string]any{"start_line": 4}, "four\nfive\n", "Lines 4-5"},
+		{"end clamped", map[string]any{"start_line": 4, "end_line": 100}, "four\nfive\n", "(end of file)"},
+		{"start clamped", map[string]any{"start_line": -3, "end_line": 1}, "one\n", "Lines 1-1"},
+		{"past end", map[string]any{"start_line": 10}, "", "No lines from line 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["path"] = path
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "read_file"
+			request.Params.Arguments = tt.args
+
+			result, err := handler.handleReadFile(context.Background(), request)
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			require.Len(t, result.Content, 2)
+			assert.Equal(t, tt.expected, result.Content[0].(mcp.TextContent).Text)
+			assert.Contains(t, result.Content[1].(mcp.TextContent).Text, tt.summary)
+		})
+	}
+}
+
+func TestDirectoryTree(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "pkg"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("readme"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("X=1"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "pkg", "a.go"), []byte("package pkg"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "directory_tree"
+	request.Params.Arguments = map[string]any{
+		"path":      dir,
+		"max_depth": 2,
+	}
+
+	result, err := handler.handleDirectoryTree(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var tree DirectoryTreeNode
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &tree))
+	require.Len(t, tree.Children, 2, "hidden entries are skipped by default")
+	assert.Equal(t, DirectoryTreeNode{Name: "README.md", Type: "file"}, *tree.Children[0])
+	src := tree.Children[1]
+	assert.Equal(t, "src", src.Name)
+	require.Len(t, src.Children, 1)
+	assert.Equal(t, "pkg", src.Children[0].Name)
+	assert.Empty(t, src.Children[0].Children)
+	assert.True(t, src.Children[0].Truncated)
+
+	request.Params.Arguments.(map[string]any)["include_hidden"] = true
+	result, err = handler.handleDirectoryTree(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	tree = DirectoryTreeNode{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &tree))
+	assert.Len(t, tree.Children, 4)
+}
+
+func TestTree_Depth(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "tree"
+	request.Params.Arguments = map[string]any{
+		"path":  dir,
+		"depth": 1,
+	}
+
+	result, err := handler.handleTree(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "(max depth: 1)")
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, `"name": "b"`)
+}
+
+func TestSearchFiles_Regex(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "cmd"), 0755))
+	mainGo := filepath.Join(dir, "cmd", "main.go")
+	mainTest := filepath.Join(dir, "cmd", "main_test.go")
+	rootGo := filepath.Join(dir, "main.go")
+	for _, file := range []string{mainGo, mainTest, rootGo} {
+		require.NoError(t, os.WriteFile(file, []byte("package main"), 0644))
+	}
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	tests := []struct {
+		info       string
+		args       map[string]any
+		matches    []string
+		notMatches []string
+	}{
+		{info: "regex on names", args: map[string]any{"pattern": `_test\.go$`, "regex": true}, matches: []string{mainTest}, notMatches: []string{rootGo}},
+		{info: "regex on relative paths", args: map[string]any{"pattern": `^cmd/main\.go$`, "regex": true, "match_path": true}, matches: []string{mainGo}, notMatches: []string{rootGo, mainTest}},
+		{info: "glob on relative paths", args: map[string]any{"pattern": "cmd/*.go", "match_path": true}, matches: []string{mainGo, mainTest}, notMatches: []string{rootGo}},
+		{info: "case-insensitive glob", args: map[string]any{"pattern": "MAIN.GO", "case_insensitive": true}, matches: []string{mainGo, rootGo}, notMatches: []string{mainTest}},
+		{info: "case-insensitive regex", args: map[string]any{"pattern": `^Main_Test\.go$`, "regex": true, "case_insensitive": true}, matches: []string{mainTest}, notMatches: []string{mainGo}},
+		{info: "case-sensitive by default", args: map[string]any{"pattern": "MAIN.GO"}, notMatches: []string{mainGo, rootGo}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.info, func(t *testing.T) {
+			test.args["path"] = dir
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "search_files"
+			request.Params.Arguments = test.args
+
+			result, err := handler.handleSearchFiles(context.Background(), request)
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+
+			text := result.Content[0].(mcp.TextContent).Text
+			for _, match := range test.matches {
+				assert.Contains(t, text, match+" ")
+			}
+			for _, match := range test.notMatches {
+				assert.NotContains(t, text, match+" ")
+			}
+		})
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "search_files"
+	request.Params.Arguments = map[string]any{
+		"path":    dir,
+		"pattern": "main(",
+		"regex":   true,
+	}
+	result, err := handler.handleSearchFiles(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid regular expression")
+}
+
+func TestSearchFiles_Exclude(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"node_modules/pkg", ".git", "src/vendor", "docs/generated"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, sub), 0755))
+	}
+	kept := filepath.Join(dir, "src", "index.js")
+	for _, file := range []string{
+		kept,
+		filepath.Join(dir, "node_modules", "pkg", "index.js"),
+		filepath.Join(dir, "src", "vendor", "lib.js"),
+		filepath.Join(dir, "docs", "generated", "api.js"),
+		filepath.Join(dir, "docs", "app.min.js"),
+	} {
+		require.NoError(t, os.WriteFile(file, []byte("x"), 0644))
+	}
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "search_files"
+	request.Params.Arguments = map[string]any{
+		"path":    dir,
+		"pattern": "*.js",
+		"exclude": []any{"node_modules", ".git", "vendor", "docs/generated", "*.min.js"},
+	}
+
+	result, err := handler.handleSearchFiles(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Found 1 results")
+	assert.Contains(t, text, kept)
+}
+
+func TestReadFile_MaxReadBytes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	content := strings.Repeat("x", 100)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithMaxReadBytes(64))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "read_file"
+	request.Params.Arguments = map[string]any{
+		"path": path,
+	}
+
+	result, err := handler.handleReadFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "file too large to read (100 bytes, limit 64 bytes)")
+
+	request.Params.Arguments.(map[string]any)["allow_large"] = true
+	result, err = handler.handleReadFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, content, result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestReadFile_BinaryDetection(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "data.txt")
+	require.NoError(t, os.WriteFile(binary, []byte("abc\x00\x01\x02def"), 0644))
+	text := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(text, []byte("hello"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	read := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "read_file"
+		request.Params.Arguments = args
+		result, err := handler.handleReadFile(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	// a text file name with binary content is returned base64-encoded
+	result := read(map[string]any{"path": binary})
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	blob := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+	assert.Equal(t, "application/octet-stream", blob.MIMEType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("abc\x00\x01\x02def")), blob.Blob)
+
+	result = read(map[string]any{"path": binary, "mode": "text"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "abc\x00\x01\x02def", result.Content[0].(mcp.TextContent).Text)
+
+	result = read(map[string]any{"path": text, "mode": "binary"})
+	require.False(t, result.IsError)
+	blob = result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("hello")), blob.Blob)
+
+	result = read(map[string]any{"path": text, "mode": "hex"})
+	assert.True(t, result.IsError)
+}
+
+func TestAppendToFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "build.log")
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "append_to_file"
+	request.Params.Arguments = map[string]any{
+		"path":    path,
+		"content": "step 1\n",
+	}
+
+	result, err := handler.handleAppendToFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "(new size: 7 bytes)")
+
+	request.Params.Arguments.(map[string]any)["content"] = "step 2\n"
+	result, err = handler.handleAppendToFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "(new size: 14 bytes)")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "step 1\nstep 2\n", string(content))
+
+	request.Params.Arguments.(map[string]any)["path"] = dir
+	result, err = handler.handleAppendToFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHeadTailFile(t *testing.T) {
+	dir := t.TempDir()
+	var lines []string
+	for i := 1; i <= 2000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	withNewline := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(withNewline, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+	withoutNewline := filepath.Join(dir, "short.log")
+	require.NoError(t, os.WriteFile(withoutNewline, []byte("a\nb\nc"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	call := func(tail bool, args map[string]any) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		var result *mcp.CallToolResult
+		if tail {
+			result, err = handler.handleTailFile(context.Background(), request)
+		} else {
+			result, err = handler.handleHeadFile(context.Background(), request)
+		}
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, strings.Join(lines[:10], "\n")+"\n", call(false, map[string]any{"path": withNewline}))
+	assert.Equal(t, strings.Join(lines[1990:], "\n")+"\n", call(true, map[string]any{"path": withNewline}))
+	// spans several 4KB chunks
+	assert.Equal(t, strings.Join(lines[500:], "\n")+"\n", call(true, map[string]any{"path": withNewline, "lines": 1500}))
+	assert.Equal(t, "b\nc", call(true, map[string]any{"path": withoutNewline, "lines": 2}))
+	assert.Equal(t, "a\nb\nc", call(true, map[string]any{"path": withoutNewline, "lines": 50}))
+	assert.Equal(t, "a\n", call(false, map[string]any{"path": withoutNewline, "lines": 1}))
+}
+
+func TestListAllowedDirectories_Symlink(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	realDir := filepath.Join(dir, "real")
+	require.NoError(t, os.Mkdir(realDir, 0755))
+	link := filepath.Join(dir, "link")
+	require.NoError(t, os.Symlink(realDir, link))
+
+	handler, err := NewFilesystemHandler([]string{link})
+	require.NoError(t, err)
+
+	result, err := handler.handleListAllowedDirectories(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, link+" (file://"+link+")")
+	assert.Contains(t, text, "resolves to: "+realDir)
+}
+
+func TestValidatePath_SymlinkPolicy(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "allowed")
+	outside := filepath.Join(root, "outside")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.MkdirAll(outside, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "target.txt"), []byte("abc"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+	require.NoError(t, os.Symlink("target.txt", filepath.Join(dir, "link")))
+	require.NoError(t, os.Symlink("sub", filepath.Join(dir, "sublink")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "new.txt"), filepath.Join(dir, "dangling")))
+
+	newHandler := func(policy SymlinkPolicy) *FilesystemHandler {
+		handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithSymlinkPolicy(policy))
+		require.NoError(t, err)
+		return handler
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	t.Run("follow", func(t *testing.T) {
+		handler := newHandler(SymlinkFollow)
+
+		path, err := handler.validatePath(filepath.Join(dir, "link"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(realDir, "target.txt"), path)
+
+		_, err = handler.validatePath(filepath.Join(dir, "escape"))
+		assert.ErrorContains(t, err, "symlink target outside allowed directories")
+
+		// writing through a dangling link would create a file outside
+		_, err = handler.validatePath(filepath.Join(dir, "dangling"))
+		assert.ErrorContains(t, err, "symlink target outside allowed directories")
+	})
+
+	t.Run("no_follow", func(t *testing.T) {
+		handler := newHandler(SymlinkNoFollow)
+
+		path, err := handler.validatePath(filepath.Join(dir, "link"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(realDir, "link"), path)
+
+		_, err = handler.validatePath(filepath.Join(dir, "escape"))
+		assert.ErrorContains(t, err, "symlink target outside allowed directories")
+
+		// deleting the link leaves its target in place
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": filepath.Join(dir, "link")}
+		result, err := handler.handleDeleteFile(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+		_, err = os.Lstat(filepath.Join(dir, "link"))
+		assert.True(t, os.IsNotExist(err))
+		assert.FileExists(t, filepath.Join(dir, "target.txt"))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		handler := newHandler(SymlinkReject)
+
+		_, err := handler.validatePath(filepath.Join(dir, "sublink"))
+		assert.ErrorContains(t, err, "path contains a symlink")
+
+		_, err = handler.validatePath(filepath.Join(dir, "sublink", "new.txt"))
+		assert.ErrorContains(t, err, "path contains a symlink: "+filepath.Join(dir, "sublink"))
+
+		path, err := handler.validatePath(filepath.Join(dir, "target.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(realDir, "target.txt"), path)
+	})
+}
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	policy, err := ParseSymlinkPolicy(" No_Follow ")
+	require.NoError(t, err)
+	assert.Equal(t, SymlinkNoFollow, policy)
+
+	_, err = ParseSymlinkPolicy("ignore")
+	assert.ErrorContains(t, err, "invalid symlink policy")
+}
+
+func TestReadFile_ByteRange(t *testing.T) {
+	dir := t.TempDir()
+	textFile := filepath.Join(dir, "data.txt")
+	require.NoError(t, os.WriteFile(textFile, []byte("0123456789héllo"), 0644))
+	binFile := filepath.Join(dir, "data.bin")
+	require.NoError(t, os.WriteFile(binFile, []byte{'a', 0, 1, 2, 3}, 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithMaxReadBytes(8))
+	require.NoError(t, err)
+
+	read := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleReadFile(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := read(map[string]any{"path": textFile, "offset": 2, "length": 4})
+	require.False(t, result.IsError)
+	assert.Equal(t, "2345", result.Content[0].(mcp.TextContent).Text)
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "Bytes 2-6 of")
+
+	// a range starting inside a multi-byte rune is still text
+	result = read(map[string]any{"path": textFile, "offset": 12, "length": 4})
+	require.False(t, result.IsError)
+	assert.Equal(t, "�llo", result.Content[0].(mcp.TextContent).Text)
+
+	// length is clamped to the end of the file
+	result = read(map[string]any{"path": textFile, "offset": 14, "length": 100})
+	require.False(t, result.IsError)
+	assert.Equal(t, "lo", result.Content[0].(mcp.TextContent).Text)
+
+	// without length the rest of the file is read, subject to the size limit
+	result = read(map[string]any{"path": textFile, "offset": 1})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "range too large")
+
+	result = read(map[string]any{"path": binFile, "offset": 1, "length": 3})
+	require.False(t, result.IsError)
+	blob := result.Content[1].(mcp.EmbeddedResource).Resource.(mcp.BlobResourceContents)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0, 1, 2}), blob.Blob)
+
+	result = read(map[string]any{"path": textFile, "offset": 100})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "outside the file")
+
+	result = read(map[string]any{"path": textFile, "offset": 0, "start_line": 1})
+	require.True(t, result.IsError)
+}
+
+func TestTrimPartialRunes(t *testing.T) {
+	assert.Equal(t, []byte("abc"), trimPartialRunes([]byte("abc")))
+	assert.Equal(t, []byte("é"), trimPartialRunes([]byte("é")))
+	assert.Equal(t, []byte("a"), trimPartialRunes([]byte{0xa9, 'a', 0xc3}))
+}
@@ -0,0 +1,176 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DEFAULT_PEEK_SIZE is the number of bytes read by peek_file by default (4KB)
+const DEFAULT_PEEK_SIZE = 4 * 1024
+
+// PeekResult holds the leading bytes of a file sampled by peek_file
+type PeekResult struct {
+	FirstLine string
+	BytesRead int
+	Truncated bool
+}
+
+// peekFile reads at most maxBytes from the start of a file and returns its first line
+func peekFile(path string, maxBytes int) (PeekResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return PeekResult{}, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, maxBytes)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return PeekResult{}, err
+	}
+	buf = buf[:n]
+
+	// Only the first line is returned; if no newline was found within the
+	// sampled bytes and the buffer is full, the line continues past the sample
+	result := PeekResult{BytesRead: n}
+	if idx := strings.IndexByte(string(buf), '\n'); idx != -1 {
+		result.FirstLine = strings.TrimSuffix(string(buf[:idx]), "\r")
+	} else {
+		result.FirstLine = string(buf)
+		result.Truncated = n == maxBytes
+	}
+	return result, nil
+}
+
+// countLines streams a file and counts its newline-terminated lines
+func countLines(path string) (int, error) {
+	counts, err := countFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return int(counts.Lines), nil
+}
+
+func (fs *FilesystemHandler) handlePeekFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+
+	maxBytes := DEFAULT_PEEK_SIZE
+	if maxBytesArg, err := request.RequireFloat("max_bytes"); err == nil {
+		maxBytes = int(maxBytesArg)
+		if maxBytes <= 0 {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: "Error: max_bytes must be positive",
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	withLineCount := request.GetBool("count_lines", false)
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot peek a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	peek, err := peekFile(validPath, maxBytes)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error reading file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	mimeType := detectMimeType(validPath)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Peek of: %s\n\n", validPath))
+	result.WriteString(fmt.Sprintf("MIME Type: %s\n", mimeType))
+	result.WriteString(fmt.Sprintf("Size: %d bytes\n", info.Size()))
+	result.WriteString(fmt.Sprintf("Bytes read: %d\n", peek.BytesRead))
+
+	if withLineCount {
+		lines, err := countLines(validPath)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error counting lines: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+		result.WriteString(fmt.Sprintf("Lines: %d\n", lines))
+	}
+
+	if isTextFile(mimeType) {
+		result.WriteString(fmt.Sprintf("Truncated: %v\n", peek.Truncated))
+		result.WriteString(fmt.Sprintf("First line: %s", peek.FirstLine))
+	} else {
+		result.WriteString("First line: (binary content not shown)")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: result.String(),
+			},
+		},
+	}, nil
+}
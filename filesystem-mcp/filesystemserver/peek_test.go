@@ -0,0 +1,64 @@
+package filesystemserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeekFile_LargeFile(t *testing.T) {
+	dir := t.TempDir()
+	largeFile := filepath.Join(dir, "large.csv")
+
+	// a header line followed by ~2MB of rows
+	content := "id,name,value\n" + strings.Repeat("1,foo,42\n", 256*1024)
+	err := os.WriteFile(largeFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	peek, err := peekFile(largeFile, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name,value", peek.FirstLine)
+	assert.LessOrEqual(t, peek.BytesRead, 64)
+	assert.False(t, peek.Truncated)
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "peek_file"
+	request.Params.Arguments = map[string]any{
+		"path":      largeFile,
+		"max_bytes": float64(64),
+	}
+
+	result, err := handler.handlePeekFile(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "Bytes read: 64")
+	assert.Contains(t, text, "First line: id,name,value")
+	assert.NotContains(t, text, "Lines:")
+}
+
+func TestPeekFile_LongFirstLine(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "minified.js")
+	err := os.WriteFile(file, []byte(strings.Repeat("a", 1000)+"\nb\nc"), 0644)
+	require.NoError(t, err)
+
+	peek, err := peekFile(file, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 100, peek.BytesRead)
+	assert.True(t, peek.Truncated)
+	assert.Len(t, peek.FirstLine, 100)
+
+	lines, err := countLines(file)
+	require.NoError(t, err)
+	assert.Equal(t, 3, lines)
+}
@@ -0,0 +1,149 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fileModePattern matches the octal permission modes set_permissions accepts,
+// e.g. 755 or 0755. Setuid, setgid and sticky bits are not supported.
+var fileModePattern = regexp.MustCompile(`^0?[0-7]{3}$`)
+
+// parseFileMode parses an octal permission mode such as 0755
+func parseFileMode(s string) (os.FileMode, error) {
+	if !fileModePattern.MatchString(s) {
+		return 0, fmt.Errorf("invalid mode %q: expected three octal digits with an optional leading 0, e.g. 0755", s)
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mode %q: %w", s, err)
+	}
+	return os.FileMode(mode), nil
+}
+
+// setPermissions sets the permission bits of root and, with recursive, of
+// everything below it. Symlinks below root are skipped, since changing their
+// mode would change their target. It returns the number of entries changed.
+func setPermissions(root string, mode os.FileMode, recursive bool) (int, error) {
+	if !recursive {
+		if err := os.Chmod(root, mode); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	count := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func (fs *FilesystemHandler) handleSetPermissions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	modeArg, err := request.RequireString("mode")
+	if err != nil {
+		return nil, err
+	}
+	recursive := request.GetBool("recursive", false)
+
+	mode, err := parseFileMode(modeArg)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	oldMode := info.Mode().Perm()
+
+	count, err := setPermissions(validPath, mode, recursive && info.IsDir())
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error changing permissions (changed %d entries before failing): %v", count, err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Report the mode as applied, which may differ on platforms such as
+	// Windows that only support the write bit
+	newMode := mode
+	if info, err := os.Stat(validPath); err == nil {
+		newMode = info.Mode().Perm()
+	}
+
+	message := fmt.Sprintf("Successfully changed mode of %s from %04o to %04o", path, oldMode, newMode)
+	if recursive && info.IsDir() {
+		message += fmt.Sprintf(" (%d entries)", count)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: message,
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,85 @@
+package filesystemserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileMode(t *testing.T) {
+	for input, expected := range map[string]os.FileMode{"0755": 0755, "644": 0644, "0000": 0} {
+		mode, err := parseFileMode(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, mode, input)
+	}
+	for _, input := range []string{"", "755 ", "0o755", "0758", "4755", "75", "rwxr-xr-x", "+x"} {
+		_, err := parseFileMode(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestSetPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows only supports the write bit")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "run.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0644))
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	nested := filepath.Join(sub, "data.txt")
+	require.NoError(t, os.WriteFile(nested, []byte("x"), 0644))
+	// symlinks below a directory are skipped, so their target keeps its mode
+	outside := filepath.Join(dir, "target.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("x"), 0644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(sub, "link")))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleSetPermissions(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+	mode := func(path string) os.FileMode {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info.Mode().Perm()
+	}
+
+	result := call(map[string]any{"path": script, "mode": "0755"})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "from 0644 to 0755")
+	assert.Equal(t, os.FileMode(0755), mode(script))
+
+	// without recursive only the directory itself changes
+	result = call(map[string]any{"path": sub, "mode": "700"})
+	require.False(t, result.IsError, result.Content)
+	assert.Equal(t, os.FileMode(0700), mode(sub))
+	assert.Equal(t, os.FileMode(0644), mode(nested))
+
+	result = call(map[string]any{"path": sub, "mode": "0750", "recursive": true})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "(2 entries)")
+	assert.Equal(t, os.FileMode(0750), mode(sub))
+	assert.Equal(t, os.FileMode(0750), mode(nested))
+	assert.Equal(t, os.FileMode(0644), mode(outside))
+
+	assert.True(t, call(map[string]any{"path": script, "mode": "u+x"}).IsError)
+	assert.True(t, call(map[string]any{"path": filepath.Join(dir, "missing"), "mode": "0644"}).IsError)
+	assert.True(t, call(map[string]any{"path": t.TempDir(), "mode": "0755"}).IsError)
+
+	handler.readOnly = true
+	assert.True(t, call(map[string]any{"path": script, "mode": "0644"}).IsError)
+	assert.Equal(t, os.FileMode(0755), mode(script))
+}
@@ -0,0 +1,229 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Maximum number of files bulk_rename renames in a single request
+const MAX_BULK_RENAME = 1000
+
+// Rename is a single rename of bulk_rename
+type Rename struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// BulkRenameResult is the output of bulk_rename
+type BulkRenameResult struct {
+	Applied   bool     `json:"applied"`
+	Renames   []Rename `json:"renames"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// renameTransform returns the function computing the new base name of a file
+// for bulk_rename. With useRegex, find is a regular expression and replace
+// may refer to its capture groups as $1 or ${name}.
+func renameTransform(find, replace string, useRegex bool) (func(string) string, error) {
+	if find == "" {
+		return nil, fmt.Errorf("find must not be empty")
+	}
+	if !useRegex {
+		return func(name string) string {
+			return strings.ReplaceAll(name, find, replace)
+		}, nil
+	}
+
+	re, err := regexp.Compile(find)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return func(name string) string {
+		return re.ReplaceAllString(name, replace)
+	}, nil
+}
+
+// planRenames matches sourcePattern, a glob in its last path element, and
+// computes the new name of every match. The plan is refused as a whole if a
+// path is not allowed, a new name is invalid, or two files would end up
+// with the same name or replace an existing file.
+func (fs *FilesystemHandler) planRenames(sourcePattern string, transform func(string) string) ([]Rename, int, error) {
+	dir, pattern := filepath.Split(sourcePattern)
+	if dir == "" {
+		dir = "."
+	}
+	if strings.ContainsAny(dir, "*?[") {
+		return nil, 0, fmt.Errorf("wildcards are only supported in the last element of source_pattern")
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, 0, fmt.Errorf("invalid source_pattern: %w", err)
+	}
+
+	validDir, err := fs.validatePath(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	entries, err := os.ReadDir(validDir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var renames []Rename
+	unchanged := 0
+	sources := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if matched, _ := filepath.Match(pattern, name); !matched {
+			continue
+		}
+
+		newName := transform(name)
+		if newName == name {
+			unchanged++
+			continue
+		}
+		if newName == "" || newName == "." || newName == ".." || strings.ContainsAny(newName, `/\`) {
+			return nil, 0, fmt.Errorf("invalid new name %q for %s", newName, name)
+		}
+
+		source, err := fs.validatePath(filepath.Join(validDir, name))
+		if err != nil {
+			return nil, 0, err
+		}
+		destination, err := fs.validatePath(filepath.Join(validDir, newName))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if other, ok := sources[destination]; ok {
+			return nil, 0, fmt.Errorf("both %s and %s would be renamed to %s", other, source, destination)
+		}
+		// On case-insensitive file systems a change of case finds the
+		// source itself
+		if destInfo, err := os.Lstat(destination); err == nil {
+			if sourceInfo, err := os.Lstat(source); err != nil || !os.SameFile(sourceInfo, destInfo) {
+				return nil, 0, fmt.Errorf("%s would be renamed to %s, which already exists", source, destination)
+			}
+		}
+		sources[destination] = source
+
+		renames = append(renames, Rename{Source: source, Destination: destination})
+		if len(renames) > MAX_BULK_RENAME {
+			return nil, 0, fmt.Errorf("too many files to rename (limit %d)", MAX_BULK_RENAME)
+		}
+	}
+
+	if len(renames) == 0 && unchanged == 0 {
+		return nil, 0, fmt.Errorf("no files match %s", sourcePattern)
+	}
+	return renames, unchanged, nil
+}
+
+// applyRenames performs the planned renames. If one fails, the renames done
+// so far are undone, so the batch is applied completely or not at all.
+func applyRenames(renames []Rename) error {
+	for i, rename := range renames {
+		if err := renameFile(rename.Source, rename.Destination); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				renameFile(renames[j].Destination, renames[j].Source)
+			}
+			return fmt.Errorf("failed to rename %s: %w", rename.Source, err)
+		}
+	}
+	return nil
+}
+
+func (fs *FilesystemHandler) handleBulkRename(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	sourcePattern, err := request.RequireString("source_pattern")
+	if err != nil {
+		return nil, err
+	}
+	find, err := request.RequireString("find")
+	if err != nil {
+		return nil, err
+	}
+	replace := request.GetString("replace", "")
+	confirm := request.GetBool("confirm", false)
+
+	if confirm {
+		if result := fs.readOnlyError(); result != nil {
+			return result, nil
+		}
+	}
+
+	transform, err := renameTransform(find, replace, request.GetBool("regex", false))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	renames, unchanged, err := fs.planRenames(sourcePattern, transform)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if confirm {
+		if err := applyRenames(renames); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error: %v; no files were renamed", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	result := BulkRenameResult{Applied: confirm, Renames: renames, Unchanged: unchanged}
+	if result.Renames == nil {
+		result.Renames = []Rename{}
+	}
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+	}, nil
+}
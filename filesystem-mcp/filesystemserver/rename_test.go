@@ -0,0 +1,142 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkRename(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.jpeg", "b.jpeg", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+	validDir, err := handler.validatePath(dir)
+	require.NoError(t, err)
+
+	call := func(args map[string]any) (*mcp.CallToolResult, BulkRenameResult) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleBulkRename(context.Background(), request)
+		require.NoError(t, err)
+		var output BulkRenameResult
+		if !result.IsError {
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		}
+		return result, output
+	}
+
+	t.Run("preview", func(t *testing.T) {
+		result, output := call(map[string]any{
+			"source_pattern": filepath.Join(dir, "*.jpeg"),
+			"find":           ".jpeg",
+			"replace":        ".jpg",
+		})
+		require.False(t, result.IsError, result.Content)
+		assert.False(t, output.Applied)
+		assert.Equal(t, []Rename{
+			{filepath.Join(validDir, "a.jpeg"), filepath.Join(validDir, "a.jpg")},
+			{filepath.Join(validDir, "b.jpeg"), filepath.Join(validDir, "b.jpg")},
+		}, output.Renames)
+		assert.FileExists(t, filepath.Join(dir, "a.jpeg"))
+	})
+
+	t.Run("regex with capture groups", func(t *testing.T) {
+		result, output := call(map[string]any{
+			"source_pattern": filepath.Join(dir, "*"),
+			"find":           `^(\w+)\.jpeg$`,
+			"replace":        "photo-$1.jpg",
+			"regex":          true,
+			"confirm":        true,
+		})
+		require.False(t, result.IsError, result.Content)
+		assert.True(t, output.Applied)
+		assert.Len(t, output.Renames, 2)
+		assert.Equal(t, 1, output.Unchanged)
+		assert.FileExists(t, filepath.Join(dir, "photo-a.jpg"))
+		assert.FileExists(t, filepath.Join(dir, "photo-b.jpg"))
+		assert.NoFileExists(t, filepath.Join(dir, "a.jpeg"))
+	})
+
+	t.Run("collision between sources", func(t *testing.T) {
+		result, _ := call(map[string]any{
+			"source_pattern": filepath.Join(dir, "photo-*"),
+			"find":           `photo-\w`,
+			"replace":        "photo",
+			"regex":          true,
+			"confirm":        true,
+		})
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "would be renamed to")
+		assert.FileExists(t, filepath.Join(dir, "photo-a.jpg"))
+		assert.FileExists(t, filepath.Join(dir, "photo-b.jpg"))
+	})
+
+	t.Run("collision with an existing file", func(t *testing.T) {
+		result, _ := call(map[string]any{
+			"source_pattern": filepath.Join(dir, "photo-a.jpg"),
+			"find":           "photo-a.jpg",
+			"replace":        "notes.txt",
+			"confirm":        true,
+		})
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "already exists")
+	})
+
+	t.Run("invalid names and patterns", func(t *testing.T) {
+		for _, args := range []map[string]any{
+			{"source_pattern": filepath.Join(dir, "*.txt"), "find": "notes", "replace": "../notes"},
+			{"source_pattern": filepath.Join(dir, "*", "*.txt"), "find": "notes"},
+			{"source_pattern": filepath.Join(dir, "*.missing"), "find": "x"},
+			{"source_pattern": filepath.Join(t.TempDir(), "*"), "find": "x"},
+		} {
+			result, _ := call(args)
+			assert.True(t, result.IsError, args)
+		}
+	})
+
+	t.Run("failed renames are undone", func(t *testing.T) {
+		defer func(original func(string, string) error) { renameFile = original }(renameFile)
+		calls := 0
+		renameFile = func(oldpath, newpath string) error {
+			calls++
+			if calls == 2 {
+				return errors.New("disk on fire")
+			}
+			return os.Rename(oldpath, newpath)
+		}
+
+		result, _ := call(map[string]any{
+			"source_pattern": filepath.Join(dir, "photo-*.jpg"),
+			"find":           ".jpg",
+			"replace":        ".png",
+			"confirm":        true,
+		})
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "no files were renamed")
+		assert.FileExists(t, filepath.Join(dir, "photo-a.jpg"))
+		assert.FileExists(t, filepath.Join(dir, "photo-b.jpg"))
+	})
+
+	t.Run("read-only mode allows previews", func(t *testing.T) {
+		handler.readOnly = true
+		defer func() { handler.readOnly = false }()
+
+		args := map[string]any{"source_pattern": filepath.Join(dir, "*.txt"), "find": "notes", "replace": "todo"}
+		result, _ := call(args)
+		assert.False(t, result.IsError, result.Content)
+		args["confirm"] = true
+		result, _ = call(args)
+		assert.True(t, result.IsError)
+	})
+}
@@ -0,0 +1,167 @@
+package filesystemserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// maxMatchLineLength caps the line text reported for a content match
+const maxMatchLineLength = 1000
+
+// ContentMatch is a single line matched by search_content
+type ContentMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// ContentSearchOptions configure a search_content walk
+type ContentSearchOptions struct {
+	Pattern         string
+	Regex           bool
+	CaseInsensitive bool
+	// Include and Exclude are glob patterns matched against both the base
+	// name and the slash-separated path relative to the search root
+	Include       []string
+	Exclude       []string
+	IncludeBinary bool
+	MaxResults    int
+}
+
+// compileLineMatcher returns a function reporting whether a line matches
+func compileLineMatcher(pattern string, isRegex, caseInsensitive bool) (func(string) bool, error) {
+	if !isRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if caseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return re.MatchString, nil
+}
+
+func compileGlobs(patterns []string) ([]glob.Glob, error) {
+	globs := make([]glob.Glob, 0, len(patterns))
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, nil
+}
+
+// matchesAnyGlob reports whether the base name or relative path of an entry
+// matches one of globs
+func matchesAnyGlob(globs []glob.Glob, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	name := relPath[strings.LastIndex(relPath, "/")+1:]
+	for _, g := range globs {
+		if g.Match(name) || g.Match(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchContent greps the files below root line by line. It returns the
+// matches and whether the search stopped at MaxResults.
+func (fs *FilesystemHandler) searchContent(ctx context.Context, root string, opts ContentSearchOptions) ([]ContentMatch, bool, error) {
+	match, err := compileLineMatcher(opts.Pattern, opts.Regex, opts.CaseInsensitive)
+	if err != nil {
+		return nil, false, err
+	}
+	include, err := compileGlobs(opts.Include)
+	if err != nil {
+		return nil, false, err
+	}
+	exclude, err := compileGlobs(opts.Exclude)
+	if err != nil {
+		return nil, false, err
+	}
+
+	matches := []ContentMatch{}
+	errLimit := errors.New("result limit reached")
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		// Stop walking once the request is cancelled
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil || path == root {
+			return nil // Skip unreadable entries and continue
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if matchesAnyGlob(exclude, relPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if len(include) > 0 && !matchesAnyGlob(include, relPath) {
+			return nil
+		}
+
+		// Symlinked files are only searched when they resolve inside the
+		// allowed directories
+		validPath, err := fs.validatePath(path)
+		if err != nil {
+			return nil
+		}
+		info, err := os.Stat(validPath)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > MAX_SEARCHABLE_SIZE {
+			return nil
+		}
+		if !opts.IncludeBinary && !isTextFile(detectMimeType(validPath)) {
+			return nil
+		}
+
+		file, err := os.Open(validPath)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), MAX_SEARCHABLE_SIZE)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			if !match(line) {
+				continue
+			}
+			if len(matches) >= opts.MaxResults {
+				return errLimit
+			}
+			if len(line) > maxMatchLineLength {
+				line = line[:maxMatchLineLength] + "..."
+			}
+			matches = append(matches, ContentMatch{Path: path, Line: lineNum, Text: line})
+		}
+		return nil
+	})
+	if errors.Is(err, errLimit) {
+		return matches, true, nil
+	}
+	return matches, false, err
+}
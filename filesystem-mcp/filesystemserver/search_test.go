@@ -0,0 +1,74 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchContent(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "vendor"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\n// TODO: wire flags\nfunc main() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "util.go"), []byte("package pkg\n// todo later\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "notes.txt"), []byte("TODO: docs\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "dep.go"), []byte("// TODO: upstream\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blob.bin"), []byte("\x00\x01TODO\x00\xff"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	search := func(args map[string]any) ([]ContentMatch, *mcp.CallToolResult) {
+		args["path"] = dir
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "search_content"
+		request.Params.Arguments = args
+
+		result, err := handler.handleSearchContent(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content)
+
+		var matches []ContentMatch
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &matches))
+		return matches, result
+	}
+
+	matches, _ := search(map[string]any{
+		"pattern": "TODO",
+		"include": []any{"*.go"},
+		"exclude": []any{"vendor"},
+	})
+	require.Len(t, matches, 1)
+	assert.Equal(t, ContentMatch{Path: filepath.Join(dir, "main.go"), Line: 3, Text: "// TODO: wire flags"}, matches[0])
+
+	matches, _ = search(map[string]any{
+		"pattern":          "todo",
+		"case_insensitive": true,
+		"include":          []any{"pkg/*"},
+	})
+	assert.Len(t, matches, 2)
+
+	matches, _ = search(map[string]any{
+		"pattern": `^package \w+$`,
+		"regex":   true,
+	})
+	assert.Len(t, matches, 2)
+
+	// binary files are skipped unless requested
+	matches, _ = search(map[string]any{"pattern": "TODO", "include": []any{"*.bin"}})
+	assert.Empty(t, matches)
+	matches, _ = search(map[string]any{"pattern": "TODO", "include": []any{"*.bin"}, "include_binary": true})
+	assert.Len(t, matches, 1)
+
+	matches, result := search(map[string]any{"pattern": "TODO", "max_results": 2})
+	assert.Len(t, matches, 2)
+	require.Len(t, result.Content, 2)
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "limited to 2 matches")
+}
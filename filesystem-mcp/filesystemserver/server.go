@@ -1,45 +1,126 @@
 package filesystemserver
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 var Version = "dev"
 
-func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
+func NewFilesystemServer(allowedDirs []string, opts ...Option) (*server.MCPServer, error) {
 
-	h, err := NewFilesystemHandler(allowedDirs)
+	h, err := NewFilesystemHandler(allowedDirs, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	// Watches end with the session that created them
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		h.watches.removeSession(session.SessionID())
+	})
+
 	s := server.NewMCPServer(
 		"secure-filesystem-server",
 		Version,
 		server.WithResourceCapabilities(true, true),
+		server.WithHooks(hooks),
 	)
+	h.watches.notify = func(sessionID, uri string) {
+		_ = s.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri})
+	}
 
-	// Register resource handlers
-	s.AddResource(mcp.NewResource(
-		"file://",
+	// Register resource handlers: every allowed directory is listed as a
+	// resource root, and the files below them are read through the template
+	for _, dir := range h.allowedRoots() {
+		s.AddResource(mcp.NewResource(
+			pathToResourceURI(dir),
+			filepath.Base(dir),
+			mcp.WithResourceDescription(fmt.Sprintf("Allowed directory %s; reading it lists its entries with their resource URIs", dir)),
+			mcp.WithMIMEType("text/plain"),
+		), h.handleReadResource)
+	}
+	s.AddResourceTemplate(mcp.NewResourceTemplate(
+		"file://{+path}",
 		"File System",
-		mcp.WithResourceDescription("Access to files and directories on the local file system"),
+		mcp.WithTemplateDescription("Files and directories within the allowed directories, addressed by their absolute path"),
 	), h.handleReadResource)
 
 	// Register tool handlers
 	s.AddTool(mcp.NewTool(
 		"read_file",
-		mcp.WithDescription("Read the complete contents of a file from the file system."),
+		mcp.WithDescription("Read the complete contents of a file from the file system, or only a range of lines of a text file. Binary files are returned base64-encoded with their detected MIME type."),
 		mcp.WithString("path",
 			mcp.Description("Path to the file to read"),
 			mcp.Required(),
 		),
+		mcp.WithNumber("start_line",
+			mcp.Description("First line to read, 1-based (default: 1)"),
+		),
+		mcp.WithNumber("end_line",
+			mcp.Description("Last line to read, inclusive (default: end of file)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Byte offset to start reading at; cannot be combined with start_line/end_line (default: 0)"),
+		),
+		mcp.WithNumber("length",
+			mcp.Description("Number of bytes to read from offset (default: to the end of the file)"),
+		),
+		mcp.WithBoolean("allow_large",
+			mcp.Description("Read the whole file even if it exceeds the server's maximum read size (default: false)"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("How to return the content: auto detects binary content, text forces text and binary forces base64 (default: auto)"),
+			mcp.Enum("auto", "text", "binary"),
+		),
 	), h.handleReadFile)
 
+	s.AddTool(mcp.NewTool(
+		"head_file",
+		mcp.WithDescription("Return the first lines of a text file."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to read"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("lines",
+			mcp.Description(fmt.Sprintf("Number of lines to return (default: %d)", DEFAULT_HEAD_TAIL_LINES)),
+		),
+	), h.handleHeadFile)
+
+	s.AddTool(mcp.NewTool(
+		"tail_file",
+		mcp.WithDescription("Return the last lines of a text file. The file is read backwards from the end, so this is cheap even for large logs."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to read"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("lines",
+			mcp.Description(fmt.Sprintf("Number of lines to return (default: %d)", DEFAULT_HEAD_TAIL_LINES)),
+		),
+	), h.handleTailFile)
+
+	s.AddTool(mcp.NewTool(
+		"peek_file",
+		mcp.WithDescription("Cheaply inspect a file by reading only its first line (bounded by max_bytes), together with its MIME type and size. Use this to decide how to process an unknown file without loading it entirely."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to peek"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("max_bytes",
+			mcp.Description("Maximum number of bytes to read from the start of the file (default: 4096)"),
+		),
+		mcp.WithBoolean("count_lines",
+			mcp.Description("Also count the lines in the file; this streams the whole file (default: false)"),
+		),
+	), h.handlePeekFile)
+
 	s.AddTool(mcp.NewTool(
 		"write_file",
-		mcp.WithDescription("Create a new file or overwrite an existing file with new content."),
+		mcp.WithDescription("Create a new file or overwrite an existing file with new content. The file is replaced atomically, so an interrupted write never leaves it truncated."),
 		mcp.WithString("path",
 			mcp.Description("Path where to write the file"),
 			mcp.Required(),
@@ -48,15 +129,91 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 			mcp.Description("Content to write to the file"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("create_dirs",
+			mcp.Description("Create missing parent directories (default: true)"),
+		),
 	), h.handleWriteFile)
 
+	s.AddTool(mcp.NewTool(
+		"append_to_file",
+		mcp.WithDescription("Append content to the end of a file, creating it if it does not exist. Useful for building up log or output files across several calls. Returns the new size of the file."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to append to; its parent directory must exist"),
+			mcp.Required(),
+		),
+		mcp.WithString("content",
+			mcp.Description("Content to append"),
+			mcp.Required(),
+		),
+	), h.handleAppendToFile)
+
+	s.AddTool(mcp.NewTool(
+		"touch",
+		mcp.WithDescription("Create an empty file if it does not exist, or update the access and modification times of an existing file or directory. Times default to now. Returns the resulting times."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file to touch; its parent directory must exist"),
+			mcp.Required(),
+		),
+		mcp.WithString("modified",
+			mcp.Description("Modification time as an RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z (default: now)"),
+		),
+		mcp.WithString("accessed",
+			mcp.Description("Access time as an RFC 3339 timestamp (default: now)"),
+		),
+	), h.handleTouch)
+
+	s.AddTool(mcp.NewTool(
+		"truncate_file",
+		mcp.WithDescription("Set the length of a file, shrinking it or growing it with zero bytes. A size of 0 clears a file, e.g. a log, without deleting it. Returns the resulting size."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file to truncate"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("size",
+			mcp.Description("New length of the file in bytes"),
+			mcp.Required(),
+		),
+	), h.handleTruncateFile)
+
+	s.AddTool(mcp.NewTool(
+		"batch",
+		mcp.WithDescription(fmt.Sprintf("Run several file operations in order in one call, e.g. to scaffold a project. Every path is validated before any operation runs. The batch stops at the first failure and, unless rollback is false, undoes the changes made so far where possible: new files and directories are removed and overwritten files up to %d bytes are restored, but deleted directories cannot be brought back. Returns the outcome of every operation.", MAX_BATCH_BACKUP_SIZE)),
+		mcp.WithArray("operations",
+			mcp.Description(fmt.Sprintf("Operations to run in order (at most %d), each with the tool name and the arguments that tool takes", MAX_BATCH_OPERATIONS)),
+			mcp.Required(),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tool": map[string]any{
+						"type": "string",
+						"enum": batchOperationNames(),
+					},
+					"arguments": map[string]any{
+						"type":        "object",
+						"description": "Arguments of the tool, e.g. path and content for write_file",
+					},
+				},
+				"required": []string{"tool", "arguments"},
+			}),
+		),
+		mcp.WithBoolean("rollback",
+			mcp.Description("Undo the changes of the batch when an operation fails (default: true)"),
+		),
+	), h.handleBatch)
+
 	s.AddTool(mcp.NewTool(
 		"list_directory",
-		mcp.WithDescription("Get a detailed listing of all files and directories in a specified path."),
+		mcp.WithDescription("Get a detailed listing of all files and directories in a specified path as a JSON array of entries with name, relative path, isDir, size and modification time."),
 		mcp.WithString("path",
 			mcp.Description("Path of the directory to list"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("recursive",
+			mcp.Description("Also list the contents of subdirectories (default: false)"),
+		),
+		mcp.WithNumber("max_entries",
+			mcp.Description(fmt.Sprintf("Maximum number of entries to return (default: %d)", DEFAULT_MAX_LIST_ENTRIES)),
+		),
 	), h.handleListDirectory)
 
 	s.AddTool(mcp.NewTool(
@@ -70,7 +227,7 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 
 	s.AddTool(mcp.NewTool(
 		"copy_file",
-		mcp.WithDescription("Copy files and directories."),
+		mcp.WithDescription("Copy files and directories, preserving file modes. Returns the number of files and bytes copied."),
 		mcp.WithString("source",
 			mcp.Description("Source path of the file or directory"),
 			mcp.Required(),
@@ -79,11 +236,14 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 			mcp.Description("Destination path"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("recursive",
+			mcp.Description("Required to copy a directory with its contents (default: false)"),
+		),
 	), h.handleCopyFile)
 
 	s.AddTool(mcp.NewTool(
 		"move_file",
-		mcp.WithDescription("Move or rename files and directories."),
+		mcp.WithDescription("Move or rename files and directories. Moves across file systems fall back to copying and deleting the source."),
 		mcp.WithString("source",
 			mcp.Description("Source path of the file or directory"),
 			mcp.Required(),
@@ -92,8 +252,66 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 			mcp.Description("Destination path"),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace an existing destination file (default: false). Directories are never replaced."),
+		),
 	), h.handleMoveFile)
 
+	s.AddTool(mcp.NewTool(
+		"bulk_rename",
+		mcp.WithDescription("Rename every file matching a glob by replacing text in its name, e.g. find '.jpeg' and replace '.jpg'. Returns the mapping of old to new paths; the files are only renamed when confirm is set. The batch is refused if two files would get the same name or a new name already exists."),
+		mcp.WithString("source_pattern",
+			mcp.Description("Glob of the files to rename, with wildcards in the last path element only, e.g. /data/photos/*.jpeg"),
+			mcp.Required(),
+		),
+		mcp.WithString("find",
+			mcp.Description("Text to replace in each file name"),
+			mcp.Required(),
+		),
+		mcp.WithString("replace",
+			mcp.Description("Replacement text; with regex, $1 or ${name} insert capture groups (default: empty)"),
+		),
+		mcp.WithBoolean("regex",
+			mcp.Description("Treat find as a regular expression (default: false)"),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Apply the renames; without it only the preview is returned (default: false)"),
+		),
+	), h.handleBulkRename)
+
+	s.AddTool(mcp.NewTool(
+		"change_owner",
+		mcp.WithDescription("Recursively change the owner and group of a file or directory tree (Unix only). Symbolic links are not followed. Requires sufficient privileges and is unavailable in read-only mode."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file or directory whose ownership should change"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("uid",
+			mcp.Description("Numeric user ID of the new owner (-1 leaves it unchanged)"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("gid",
+			mcp.Description("Numeric group ID of the new group (-1 leaves it unchanged)"),
+			mcp.Required(),
+		),
+	), h.handleChangeOwner)
+
+	s.AddTool(mcp.NewTool(
+		"set_permissions",
+		mcp.WithDescription("Change the permission bits of a file or directory, e.g. to make a script executable. Returns the old and new modes. Unavailable in read-only mode."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file or directory"),
+			mcp.Required(),
+		),
+		mcp.WithString("mode",
+			mcp.Description("Octal permission mode, e.g. 0755 or 644"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("recursive",
+			mcp.Description("Also apply the mode to everything below a directory; symlinks are skipped (default: false)"),
+		),
+	), h.handleSetPermissions)
+
 	s.AddTool(mcp.NewTool(
 		"search_files",
 		mcp.WithDescription("Recursively search for files and directories matching a pattern."),
@@ -102,14 +320,27 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 			mcp.Required(),
 		),
 		mcp.WithString("pattern",
-			mcp.Description("Search pattern to match against file names"),
+			mcp.Description("Search pattern to match against file names: a glob by default, or a Go regular expression when regex is set"),
 			mcp.Required(),
 		),
-	), h.handleSearchFiles)
+		mcp.WithBoolean("regex",
+			mcp.Description("Interpret the pattern as a Go regular expression instead of a glob (default: false)"),
+		),
+		mcp.WithBoolean("match_path",
+			mcp.Description("Match the pattern against the slash-separated path relative to the search directory instead of the file name (default: false)"),
+		),
+		mcp.WithBoolean("case_insensitive",
+			mcp.Description("Match the pattern regardless of case, e.g. readme.md also finds README.md (default: false)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Globs of files and directories to skip, e.g. node_modules, .git or vendor. Patterns match the slash-separated path relative to the search directory; a pattern without a slash also matches the name at any depth. Excluded directories are not descended into."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	), h.limited(h.handleSearchFiles))
 
 	s.AddTool(mcp.NewTool(
 		"get_file_info",
-		mcp.WithDescription("Retrieve detailed metadata about a file or directory."),
+		mcp.WithDescription("Retrieve detailed metadata about a file or directory: size, mode, creation/modification/access times, and whether it is a directory or symlink (with the symlink target)."),
 		mcp.WithString("path",
 			mcp.Description("Path to the file or directory"),
 			mcp.Required(),
@@ -118,17 +349,29 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 
 	s.AddTool(mcp.NewTool(
 		"list_allowed_directories",
-		mcp.WithDescription("Returns the list of directories that this server is allowed to access."),
+		mcp.WithDescription("Returns the list of directories that this server is allowed to access, including the real path of any that are symlinks. Call this first to avoid access denied errors."),
 	), h.handleListAllowedDirectories)
 
+	s.AddTool(mcp.NewTool(
+		"expand_path",
+		mcp.WithDescription("Expand ~ and environment variables ($VAR, ${VAR}, %VAR%) in a path and validate the result against the allowed directories. Returns the expanded canonical path, or an error if it is outside the allowed directories."),
+		mcp.WithString("path",
+			mcp.Description("Path to expand, e.g. $HOME/project or ~/code"),
+			mcp.Required(),
+		),
+	), h.handleExpandPath)
+
 	s.AddTool(mcp.NewTool(
 		"read_multiple_files",
-		mcp.WithDescription("Read the contents of multiple files in a single operation."),
+		mcp.WithDescription("Read the contents of multiple files in a single operation. Returns a JSON object mapping each path to its content or error; a path that cannot be read does not fail the others."),
 		mcp.WithArray("paths",
 			mcp.Description("List of file paths to read"),
 			mcp.Required(),
 			mcp.Items(map[string]any{"type": "string"}),
 		),
+		mcp.WithNumber("max_total_size",
+			mcp.Description(fmt.Sprintf("Maximum combined size in bytes of the files returned; later files are skipped once it is reached (default: %d)", MAX_MULTI_READ_SIZE)),
+		),
 	), h.handleReadMultipleFiles)
 
 	s.AddTool(mcp.NewTool(
@@ -144,17 +387,75 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		mcp.WithBoolean("follow_symlinks",
 			mcp.Description("Whether to follow symbolic links (default: false)"),
 		),
-	), h.handleTree)
+	), h.limited(h.handleTree))
+
+	s.AddTool(mcp.NewTool(
+		"directory_tree",
+		mcp.WithDescription("Returns a compact nested JSON tree of the names below a directory, for a quick overview of a project layout. Directories cut off by max_depth are marked truncated; symlinks are listed but not followed."),
+		mcp.WithString("path",
+			mcp.Description("Path of the directory to traverse"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description(fmt.Sprintf("Maximum number of levels below the directory to include (default: %d)", DEFAULT_TREE_DEPTH)),
+		),
+		mcp.WithBoolean("include_hidden",
+			mcp.Description("Include files and directories whose name starts with a dot (default: false)"),
+		),
+	), h.limited(h.handleDirectoryTree))
+
+	s.AddTool(mcp.NewTool(
+		"compress",
+		mcp.WithDescription("Bundle a directory, or a list of files and directories, into a zip or tar.gz archive. Entries are streamed into the archive and keep their relative paths; symlinks are skipped. Returns the archive size and entry count."),
+		mcp.WithString("source",
+			mcp.Description("Directory (or file) to archive; a directory's contents are stored at the root of the archive. Either source or paths is required."),
+		),
+		mcp.WithArray("paths",
+			mcp.Description("Files and directories to archive, each stored under its base name. Either source or paths is required."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("destination",
+			mcp.Description("Path of the archive to create"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Archive format (default: inferred from the destination extension .zip, .tar.gz or .tgz)"),
+			mcp.Enum("zip", "tar.gz"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace an existing archive at the destination (default: false)"),
+		),
+	), h.handleCompress)
+
+	s.AddTool(mcp.NewTool(
+		"extract",
+		mcp.WithDescription("Unpack a zip or tar.gz archive into a directory. Entries that would escape the destination or the allowed directories, links, and existing files are skipped and reported. Returns the list of extracted files."),
+		mcp.WithString("archive",
+			mcp.Description("Path of the archive to extract"),
+			mcp.Required(),
+		),
+		mcp.WithString("destination",
+			mcp.Description("Directory to extract into; it is created if missing"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Archive format (default: inferred from the archive extension .zip, .tar.gz or .tgz)"),
+			mcp.Enum("zip", "tar.gz"),
+		),
+		mcp.WithBoolean("overwrite",
+			mcp.Description("Replace existing regular files (default: false, existing files are skipped)"),
+		),
+	), h.handleExtract)
 
 	s.AddTool(mcp.NewTool(
 		"delete_file",
-		mcp.WithDescription("Delete a file or directory from the file system."),
+		mcp.WithDescription("Delete a file or directory from the file system. Non-empty directories require recursive; the allowed directories themselves can never be deleted."),
 		mcp.WithString("path",
 			mcp.Description("Path to the file or directory to delete"),
 			mcp.Required(),
 		),
 		mcp.WithBoolean("recursive",
-			mcp.Description("Whether to recursively delete directories (default: false)"),
+			mcp.Description("Whether to delete directories together with their contents (default: false, only empty directories are deleted)"),
 		),
 	), h.handleDeleteFile)
 
@@ -181,6 +482,56 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		),
 	), h.handleModifyFile)
 
+	s.AddTool(mcp.NewTool(
+		"transform_file",
+		mcp.WithDescription("Apply an ordered pipeline of content transforms to a text file and write the result atomically. Returns a unified diff of the changes; use dry_run to preview without writing."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to transform"),
+			mcp.Required(),
+		),
+		mcp.WithArray("transforms",
+			mcp.Description("Transforms to apply in order: trim_trailing_ws, normalize_eol, tabs_to_spaces, lowercase, uppercase, ensure_final_newline"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string", "enum": transformNames()}),
+		),
+		mcp.WithNumber("tab_width",
+			mcp.Description("Number of columns per tab stop for tabs_to_spaces (default: 4)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only return the diff without writing the file (default: false)"),
+		),
+	), h.handleTransformFile)
+
+	s.AddTool(mcp.NewTool(
+		"edit_file",
+		mcp.WithDescription("Make targeted replacements in a text file. Each edit replaces oldText, which must occur exactly once, with newText; edits are applied in order and none are written if any fails. Returns a unified diff of the changes; use dry_run to preview without writing."),
+		mcp.WithString("path",
+			mcp.Description("Path to the file to edit"),
+			mcp.Required(),
+		),
+		mcp.WithArray("edits",
+			mcp.Description("Edits to apply in order"),
+			mcp.Required(),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"oldText": map[string]any{
+						"type":        "string",
+						"description": "Exact text to replace; must match exactly once",
+					},
+					"newText": map[string]any{
+						"type":        "string",
+						"description": "Text to replace it with",
+					},
+				},
+				"required": []string{"oldText", "newText"},
+			}),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only return the diff without writing the file (default: false)"),
+		),
+	), h.handleEditFile)
+
 	s.AddTool(mcp.NewTool(
 		"search_within_files",
 		mcp.WithDescription("Search for text within file contents. Unlike search_files which only searches file names, this tool scans the actual contents of text files for matching substrings. Binary files are automatically excluded from the search. Reports file paths and line numbers where matches are found."),
@@ -198,7 +549,105 @@ func NewFilesystemServer(allowedDirs []string) (*server.MCPServer, error) {
 		mcp.WithNumber("max_results",
 			mcp.Description("Maximum number of results to return (default: 1000)"),
 		),
-	), h.handleSearchWithinFiles)
+	), h.limited(h.handleSearchWithinFiles))
+
+	s.AddTool(mcp.NewTool(
+		"search_content",
+		mcp.WithDescription("Recursively grep the contents of files below a directory. Returns a JSON array of matches with the file path, line number and line text. Binary files are skipped unless include_binary is set."),
+		mcp.WithString("path",
+			mcp.Description("Directory to search"),
+			mcp.Required(),
+		),
+		mcp.WithString("pattern",
+			mcp.Description("Text, or regular expression when regex is set, to search for"),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("regex",
+			mcp.Description("Treat the pattern as a regular expression (default: false)"),
+		),
+		mcp.WithBoolean("case_insensitive",
+			mcp.Description("Match regardless of case (default: false)"),
+		),
+		mcp.WithArray("include",
+			mcp.Description("Only search files whose name or relative path matches one of these globs, e.g. *.go"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Skip files and directories whose name or relative path matches one of these globs, e.g. node_modules"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("include_binary",
+			mcp.Description("Also search files detected as binary (default: false)"),
+		),
+		mcp.WithNumber("max_results",
+			mcp.Description(fmt.Sprintf("Maximum number of matches to return (default: %d)", MAX_SEARCH_RESULTS)),
+		),
+	), h.limited(h.handleSearchContent))
+
+	s.AddTool(mcp.NewTool(
+		"diff_files",
+		mcp.WithDescription("Compare two text files and return a unified diff from old_path to new_path. A missing file is treated as empty, so the diff shows the other file as created or deleted."),
+		mcp.WithString("old_path",
+			mcp.Description("Path of the original file"),
+			mcp.Required(),
+		),
+		mcp.WithString("new_path",
+			mcp.Description("Path of the changed file"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("context_lines",
+			mcp.Description(fmt.Sprintf("Number of unchanged lines shown around each change (default: %d)", DIFF_CONTEXT_LINES)),
+		),
+	), h.handleDiffFiles)
+
+	s.AddTool(mcp.NewTool(
+		"count_lines",
+		mcp.WithDescription("Count the lines, words and bytes of one or more files, like wc. Files are streamed, so counting large files is cheap. Returns a JSON object with the counts of each file and their total; files that cannot be counted are reported with an error."),
+		mcp.WithString("path",
+			mcp.Description("Path of the file to count"),
+		),
+		mcp.WithArray("paths",
+			mcp.Description(fmt.Sprintf("Paths of several files to count (at most %d)", MAX_COUNT_FILES)),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	), h.handleCountLines)
+
+	s.AddTool(mcp.NewTool(
+		"disk_usage",
+		mcp.WithDescription("Measure the size of a directory tree: total bytes, number of files and directories, and the largest files and subdirectories. Symlinks are not followed."),
+		mcp.WithString("path",
+			mcp.Description("Path of the directory to measure"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("top",
+			mcp.Description(fmt.Sprintf("Number of largest files and subdirectories to return (default: %d)", DEFAULT_USAGE_TOP)),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description("Maximum number of directory levels to descend; deeper contents are not measured (default: unlimited)"),
+		),
+		mcp.WithArray("exclude",
+			mcp.Description("Globs of files and directories to skip, e.g. node_modules, .git or vendor. Patterns match the slash-separated path relative to the measured directory; a pattern without a slash also matches the name at any depth."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	), h.limited(h.handleDiskUsage))
+
+	s.AddTool(mcp.NewTool(
+		"watch",
+		mcp.WithDescription(fmt.Sprintf("Watch a file or directory for changes. The server then sends a notifications/resources/updated notification with the path's file:// URI whenever it changes, coalescing changes within %s, until unwatch is called or the session ends. Directories are watched without their subdirectories.", WATCH_DEBOUNCE)),
+		mcp.WithString("path",
+			mcp.Description("Path of the file or directory to watch"),
+			mcp.Required(),
+		),
+	), h.handleWatch)
+
+	s.AddTool(mcp.NewTool(
+		"unwatch",
+		mcp.WithDescription("Stop watching a file or directory previously watched with watch."),
+		mcp.WithString("path",
+			mcp.Description("Path of the watched file or directory"),
+			mcp.Required(),
+		),
+	), h.handleUnwatch)
 
 	return s, nil
 }
@@ -1,9 +1,13 @@
 package filesystemserver_test
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,3 +30,42 @@ func TestReadMultipleFilesSchema(t *testing.T) {
 	_, ok = pathsMap["items"]
 	assert.True(t, ok)
 }
+
+func TestResources(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "guide.txt"), []byte("hello resources"), 0644))
+
+	fsserver, err := filesystemserver.NewFilesystemServer([]string{dir})
+	require.NoError(t, err)
+
+	mcpClient := startTestClient(t, fsserver)
+
+	// the allowed directories are listed as resource roots
+	resources, err := mcpClient.ListResources(context.Background(), mcp.ListResourcesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resources.Resources, 1)
+	assert.Equal(t, "file://"+dir, resources.Resources[0].URI)
+
+	templates, err := mcpClient.ListResourceTemplates(context.Background(), mcp.ListResourceTemplatesRequest{})
+	require.NoError(t, err)
+	require.Len(t, templates.ResourceTemplates, 1)
+
+	read := func(uri string) (*mcp.ReadResourceResult, error) {
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = uri
+		return mcpClient.ReadResource(context.Background(), request)
+	}
+
+	result, err := read("file://" + dir)
+	require.NoError(t, err)
+	assert.Contains(t, result.Contents[0].(mcp.TextResourceContents).Text, "file://"+filepath.Join(dir, "docs"))
+
+	result, err = read("file://" + filepath.Join(dir, "docs", "guide.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello resources", result.Contents[0].(mcp.TextResourceContents).Text)
+
+	_, err = read("file://" + filepath.Dir(dir))
+	assert.ErrorContains(t, err, "access denied")
+}
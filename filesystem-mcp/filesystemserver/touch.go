@@ -0,0 +1,163 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/djherbis/times"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TouchResult is the output of touch
+type TouchResult struct {
+	Path     string    `json:"path"`
+	Created  bool      `json:"created"`
+	Modified time.Time `json:"modified"`
+	Accessed time.Time `json:"accessed"`
+}
+
+// parseTouchTime parses an optional RFC 3339 timestamp argument of touch,
+// defaulting to now
+func parseTouchTime(request mcp.CallToolRequest, name string, now time.Time) (time.Time, error) {
+	value := request.GetString(name, "")
+	if value == "" {
+		return now, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: expected an RFC 3339 timestamp such as 2024-01-02T15:04:05Z", name, value)
+	}
+	return t, nil
+}
+
+// touchFile creates path as an empty file if it does not exist and sets its
+// access and modification times. It reports whether the file was created.
+func touchFile(path string, accessed, modified time.Time) (bool, error) {
+	created := false
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return false, err
+		}
+		if err := file.Close(); err != nil {
+			return false, err
+		}
+		created = true
+	} else if err != nil {
+		return false, err
+	}
+
+	return created, os.Chtimes(path, accessed, modified)
+}
+
+func (fs *FilesystemHandler) handleTouch(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if result := fs.readOnlyError(); result != nil {
+		return result, nil
+	}
+
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	modified, err := parseTouchTime(request, "modified", now)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	accessed, err := parseTouchTime(request, "accessed", now)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	created, err := touchFile(validPath, accessed, modified)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error touching %s: %v", validPath, err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	// Report the times as stored, which may be rounded by the file system
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	timespec := times.Get(info)
+	result := TouchResult{
+		Path:     validPath,
+		Created:  created,
+		Modified: timespec.ModTime(),
+		Accessed: timespec.AccessTime(),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,68 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTouch(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	call := func(args map[string]any) (*mcp.CallToolResult, TouchResult) {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleTouch(context.Background(), request)
+		require.NoError(t, err)
+		var output TouchResult
+		if !result.IsError {
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output))
+		}
+		return result, output
+	}
+
+	// a missing file is created empty with the current time
+	path := filepath.Join(dir, "stamp")
+	before := time.Now().Add(-time.Second)
+	result, output := call(map[string]any{"path": path})
+	require.False(t, result.IsError, result.Content)
+	assert.True(t, output.Created)
+	assert.True(t, output.Modified.After(before))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	// existing files keep their content and get the requested times
+	require.NoError(t, os.WriteFile(path, []byte("keep"), 0644))
+	result, output = call(map[string]any{
+		"path":     path,
+		"modified": "2020-05-17T10:00:00Z",
+		"accessed": "2021-01-01T00:00:00+02:00",
+	})
+	require.False(t, result.IsError, result.Content)
+	assert.False(t, output.Created)
+	assert.True(t, output.Modified.Equal(time.Date(2020, 5, 17, 10, 0, 0, 0, time.UTC)), output.Modified)
+	assert.True(t, output.Accessed.Equal(time.Date(2020, 12, 31, 22, 0, 0, 0, time.UTC)), output.Accessed)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(content))
+
+	result, _ = call(map[string]any{"path": path, "modified": "yesterday"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "RFC 3339")
+
+	result, _ = call(map[string]any{"path": filepath.Join(dir, "missing", "stamp")})
+	assert.True(t, result.IsError)
+
+	result, _ = call(map[string]any{"path": filepath.Join(t.TempDir(), "stamp")})
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,241 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DEFAULT_TAB_WIDTH is the number of spaces a tab expands to in tabs_to_spaces
+const DEFAULT_TAB_WIDTH = 4
+
+var trailingWhitespacePattern = regexp.MustCompile(`[ \t]+(\r?\n|$)`)
+
+// contentTransforms are the named transforms accepted by transform_file
+var contentTransforms = map[string]func(content string, tabWidth int) string{
+	"trim_trailing_ws": func(content string, _ int) string {
+		return trailingWhitespacePattern.ReplaceAllString(content, "$1")
+	},
+	"normalize_eol": func(content string, _ int) string {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		return strings.ReplaceAll(content, "\r", "\n")
+	},
+	"tabs_to_spaces": expandTabs,
+	"lowercase": func(content string, _ int) string {
+		return strings.ToLower(content)
+	},
+	"uppercase": func(content string, _ int) string {
+		return strings.ToUpper(content)
+	},
+	"ensure_final_newline": func(content string, _ int) string {
+		if content == "" || strings.HasSuffix(content, "\n") {
+			return content
+		}
+		return content + "\n"
+	},
+}
+
+// transformNames returns the names of all transforms in sorted order
+func transformNames() []string {
+	names := make([]string, 0, len(contentTransforms))
+	for name := range contentTransforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyTransforms runs the named transforms over content in order
+func applyTransforms(content string, names []string, tabWidth int) (string, error) {
+	for _, name := range names {
+		transform, ok := contentTransforms[name]
+		if !ok {
+			return "", fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(transformNames(), ", "))
+		}
+		content = transform(content, tabWidth)
+	}
+	return content, nil
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop
+func expandTabs(content string, tabWidth int) string {
+	if tabWidth <= 0 {
+		tabWidth = DEFAULT_TAB_WIDTH
+	}
+
+	var result strings.Builder
+	column := 0
+	for _, r := range content {
+		switch r {
+		case '\t':
+			spaces := tabWidth - column%tabWidth
+			result.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case '\n', '\r':
+			result.WriteRune(r)
+			column = 0
+		default:
+			result.WriteRune(r)
+			column++
+		}
+	}
+	return result.String()
+}
+
+func (fs *FilesystemHandler) handleTransformFile(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	transforms, err := request.RequireStringSlice("transforms")
+	if err != nil {
+		return nil, err
+	}
+	tabWidth := request.GetInt("tab_width", DEFAULT_TAB_WIDTH)
+	dryRun := request.GetBool("dry_run", false)
+
+	if !dryRun {
+		if result := fs.readOnlyError(); result != nil {
+			return result, nil
+		}
+	}
+
+	if len(transforms) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: at least one transform is required",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot transform a directory",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	if !isTextFile(detectMimeType(validPath)) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: Cannot transform a binary file",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	original, err := os.ReadFile(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error reading file: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	transformed, err := applyTransforms(string(original), transforms, tabWidth)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	diff := unifiedDiff(validPath, validPath, string(original), transformed)
+	if diff == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("No changes: %s is unchanged by %s", path, strings.Join(transforms, ", ")),
+				},
+			},
+		}, nil
+	}
+
+	if !dryRun {
+		if err := writeFileAtomic(validPath, []byte(transformed), info.Mode().Perm()); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					mcp.TextContent{
+						Type: "text",
+						Text: fmt.Sprintf("Error writing file: %v", err),
+					},
+				},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	var result strings.Builder
+	if dryRun {
+		result.WriteString(fmt.Sprintf("Dry run: %s would be changed by %s\n\n", path, strings.Join(transforms, ", ")))
+	} else {
+		result.WriteString(fmt.Sprintf("Transformed %s with %s\n\n", path, strings.Join(transforms, ", ")))
+	}
+	result.WriteString(diff)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: result.String(),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,144 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTransforms(t *testing.T) {
+	tests := []struct {
+		transform string
+		input     string
+		expected  string
+	}{
+		{"trim_trailing_ws", "a  \nb\t\r\nc \t", "a\nb\r\nc"},
+		{"normalize_eol", "a\r\nb\rc\n", "a\nb\nc\n"},
+		{"tabs_to_spaces", "\tx\n ab\tc", "    x\n ab c"},
+		{"lowercase", "Hello World", "hello world"},
+		{"uppercase", "Hello World", "HELLO WORLD"},
+		{"ensure_final_newline", "a\nb", "a\nb\n"},
+		{"ensure_final_newline", "a\n", "a\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transform, func(t *testing.T) {
+			result, err := applyTransforms(tt.input, []string{tt.transform}, DEFAULT_TAB_WIDTH)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestApplyTransforms_Pipeline(t *testing.T) {
+	input := "Line One  \r\n\tLine Two\t\r\nLine Three"
+
+	result, err := applyTransforms(input, []string{"normalize_eol", "trim_trailing_ws", "tabs_to_spaces", "lowercase", "ensure_final_newline"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "line one\n  line two\nline three\n", result)
+
+	_, err = applyTransforms(input, []string{"normalize_eol", "reverse"}, 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reverse")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	newText := "one\nTWO\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven"
+
+	expected := "--- a\n+++ b\n" +
+		"@@ -1,5 +1,5 @@\n one\n-two\n+TWO\n three\n four\n five\n" +
+		"@@ -8,3 +8,4 @@\n eight\n nine\n ten\n+eleven\n\\ No newline at end of file\n"
+	assert.Equal(t, expected, unifiedDiff("a", "b", oldText, newText))
+
+	assert.Empty(t, unifiedDiff("a", "b", oldText, oldText))
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+new\n", unifiedDiff("a", "b", "", "new\n"))
+}
+
+func TestTransformFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("package main  \r\n\r\nfunc main() {}\r\n"), 0640))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "transform_file"
+	request.Params.Arguments = map[string]any{
+		"path":       file,
+		"transforms": []any{"normalize_eol", "trim_trailing_ws"},
+		"dry_run":    true,
+	}
+
+	result, err := handler.handleTransformFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content[0]))
+	assert.Contains(t, fmt.Sprint(result.Content[0]), "+package main\n")
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "package main  \r\n\r\nfunc main() {}\r\n", string(content), "dry run must not write")
+
+	request.Params.Arguments.(map[string]any)["dry_run"] = false
+	result, err = handler.handleTransformFile(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, fmt.Sprint(result.Content[0]))
+
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {}\n", string(content))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
+
+func TestDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.txt")
+	newFile := filepath.Join(dir, "new.txt")
+	require.NoError(t, os.WriteFile(oldFile, []byte("a\nb\nc\nd\ne\n"), 0644))
+	require.NoError(t, os.WriteFile(newFile, []byte("a\nB\nc\nd\nE\n"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+	realDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+
+	diff := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleDiffFiles(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := diff(map[string]any{"old_path": oldFile, "new_path": newFile, "context_lines": 0})
+	require.False(t, result.IsError, fmt.Sprint(result.Content[0]))
+	assert.Equal(t, "--- "+filepath.Join(realDir, "old.txt")+"\n+++ "+filepath.Join(realDir, "new.txt")+"\n"+
+		"@@ -2,1 +2,1 @@\n-b\n+B\n@@ -5,1 +5,1 @@\n-e\n+E\n", result.Content[0].(mcp.TextContent).Text)
+
+	// with the default context both changes fall into one hunk
+	result = diff(map[string]any{"old_path": oldFile, "new_path": newFile})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "@@ -1,5 +1,5 @@\n")
+
+	result = diff(map[string]any{"old_path": filepath.Join(dir, "missing.txt"), "new_path": newFile})
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "--- /dev/null\n")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "@@ -0,0 +1,5 @@\n")
+
+	result = diff(map[string]any{"old_path": oldFile, "new_path": oldFile})
+	require.False(t, result.IsError)
+	assert.Equal(t, "Files are identical", result.Content[0].(mcp.TextContent).Text)
+
+	result = diff(map[string]any{"old_path": filepath.Join(dir, "x"), "new_path": filepath.Join(dir, "y")})
+	assert.True(t, result.IsError)
+}
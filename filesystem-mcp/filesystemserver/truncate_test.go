@@ -0,0 +1,61 @@
+package filesystemserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("hello world"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleTruncateFile(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]any{"path": path, "size": float64(5)})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "from 11 to 5 bytes")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	// growing pads with zero bytes
+	result = call(map[string]any{"path": path, "size": float64(8)})
+	require.False(t, result.IsError, result.Content)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\x00\x00\x00", string(content))
+
+	result = call(map[string]any{"path": path, "size": float64(0)})
+	require.False(t, result.IsError, result.Content)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	for _, args := range []map[string]any{
+		{"path": path, "size": float64(-1)},
+		{"path": path, "size": 1.5},
+		{"path": dir, "size": float64(0)},
+		{"path": filepath.Join(dir, "missing"), "size": float64(0)},
+		{"path": filepath.Join(t.TempDir(), "other"), "size": float64(0)},
+	} {
+		assert.True(t, call(args).IsError, args)
+	}
+	_, err = os.Stat(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err), "truncate_file must not create files")
+}
@@ -0,0 +1,246 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Default number of largest files and directories disk_usage reports
+const DEFAULT_USAGE_TOP = 10
+
+// UsageEntry is the size of a file or directory below the measured directory
+type UsageEntry struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// DiskUsage is the output of disk_usage
+type DiskUsage struct {
+	Path               string       `json:"path"`
+	TotalBytes         int64        `json:"totalBytes"`
+	Files              int          `json:"files"`
+	Directories        int          `json:"directories"`
+	LargestFiles       []UsageEntry `json:"largestFiles"`
+	LargestDirectories []UsageEntry `json:"largestDirectories"`
+	// DepthLimited is set when directories below max_depth were not measured
+	DepthLimited bool `json:"depthLimited,omitempty"`
+	// Unreadable counts the entries that could not be read and were skipped
+	Unreadable int `json:"unreadable,omitempty"`
+}
+
+// topEntries keeps the n largest entries added to it
+type topEntries struct {
+	n       int
+	entries []UsageEntry
+}
+
+func (t *topEntries) add(entry UsageEntry) {
+	t.entries = append(t.entries, entry)
+	// Trim in batches so large trees are not sorted on every entry
+	if len(t.entries) >= 4*t.n+64 {
+		t.trim()
+	}
+}
+
+func (t *topEntries) trim() {
+	sort.Slice(t.entries, func(i, j int) bool {
+		if t.entries[i].Size != t.entries[j].Size {
+			return t.entries[i].Size > t.entries[j].Size
+		}
+		return t.entries[i].Path < t.entries[j].Path
+	})
+	if len(t.entries) > t.n {
+		t.entries = t.entries[:t.n]
+	}
+}
+
+func (t *topEntries) result() []UsageEntry {
+	t.trim()
+	if t.entries == nil {
+		return []UsageEntry{}
+	}
+	return t.entries
+}
+
+// diskUsage measures the apparent size of the files below root. Entries
+// matching exclude are skipped, symlinks are counted but not followed, and
+// directories deeper than maxDepth are not descended into when maxDepth > 0.
+func diskUsage(ctx context.Context, root string, maxDepth, top int, exclude []string) (DiskUsage, error) {
+	excludeGlobs, err := compileGlobs(exclude)
+	if err != nil {
+		return DiskUsage{}, err
+	}
+
+	usage := DiskUsage{Path: root}
+	files := &topEntries{n: top}
+	dirs := &topEntries{n: top}
+	// Sizes of the directories being walked, indexed by relative path
+	dirSizes := make(map[string]int64)
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		// Stop walking once the request is cancelled
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			usage.Unreadable++
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if matchesAnyGlob(excludeGlobs, relPath) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			usage.Directories++
+			dirSizes[relPath] = 0
+			if maxDepth > 0 && strings.Count(filepath.ToSlash(relPath), "/")+1 >= maxDepth {
+				if entries, err := os.ReadDir(path); err == nil && len(entries) > 0 {
+					usage.DepthLimited = true
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			usage.Unreadable++
+			return nil
+		}
+		size := info.Size()
+		usage.Files++
+		usage.TotalBytes += size
+		files.add(UsageEntry{Path: filepath.ToSlash(relPath), Size: size})
+
+		// Add the size to every enclosing directory below root
+		for dir := filepath.Dir(relPath); dir != "."; dir = filepath.Dir(dir) {
+			dirSizes[dir] += size
+		}
+		return nil
+	})
+	if err != nil {
+		return DiskUsage{}, err
+	}
+
+	for dir, size := range dirSizes {
+		dirs.add(UsageEntry{Path: filepath.ToSlash(dir), Size: size})
+	}
+	usage.LargestFiles = files.result()
+	usage.LargestDirectories = dirs.result()
+	return usage, nil
+}
+
+func (fs *FilesystemHandler) handleDiskUsage(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, err
+	}
+	maxDepth := request.GetInt("max_depth", 0)
+	top := request.GetInt("top", DEFAULT_USAGE_TOP)
+	if maxDepth < 0 || top < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: max_depth and top must not be negative",
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+	if !info.IsDir() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %s is not a directory", validPath),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	usage, err := diskUsage(ctx, validPath, maxDepth, top, request.GetStringSlice("exclude", nil))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	jsonData, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error generating JSON: %v", err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: string(jsonData),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,76 @@
+package filesystemserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644))
+	}
+	write("small.txt", 10)
+	write("src/main.go", 100)
+	write("src/pkg/util.go", 50)
+	write("node_modules/lib/index.js", 1000)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "empty"), 0755))
+
+	usage, err := diskUsage(context.Background(), dir, 0, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1160), usage.TotalBytes)
+	assert.Equal(t, 4, usage.Files)
+	assert.Equal(t, 5, usage.Directories)
+	assert.Equal(t, []UsageEntry{{"node_modules/lib/index.js", 1000}, {"src/main.go", 100}}, usage.LargestFiles)
+	assert.Equal(t, []UsageEntry{{"node_modules", 1000}, {"node_modules/lib", 1000}}, usage.LargestDirectories)
+	assert.False(t, usage.DepthLimited)
+
+	usage, err = diskUsage(context.Background(), dir, 0, 10, []string{"node_modules"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(160), usage.TotalBytes)
+	assert.Equal(t, []UsageEntry{{"src", 150}, {"src/pkg", 50}, {"empty", 0}}, usage.LargestDirectories)
+
+	// only the first level is measured
+	usage, err = diskUsage(context.Background(), dir, 1, 10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), usage.TotalBytes)
+	assert.Equal(t, 1, usage.Files)
+	assert.True(t, usage.DepthLimited)
+}
+
+func TestHandleDiskUsage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir))
+	require.NoError(t, err)
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler.handleDiskUsage(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]any{"path": dir})
+	require.False(t, result.IsError, result.Content)
+	var usage DiskUsage
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &usage))
+	assert.Equal(t, int64(5), usage.TotalBytes)
+	assert.Equal(t, []UsageEntry{}, usage.LargestDirectories)
+
+	assert.True(t, call(map[string]any{"path": filepath.Join(dir, "a.txt")}).IsError)
+	assert.True(t, call(map[string]any{"path": t.TempDir()}).IsError)
+	assert.True(t, call(map[string]any{"path": dir, "exclude": []any{"["}}).IsError)
+}
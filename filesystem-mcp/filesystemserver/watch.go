@@ -0,0 +1,269 @@
+package filesystemserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// Default maximum number of paths watched at once, across all sessions
+	DEFAULT_MAX_WATCHERS = 32
+	// Changes within this window are coalesced into a single notification
+	WATCH_DEBOUNCE = 250 * time.Millisecond
+)
+
+// watchKey identifies a watched path of one client session
+type watchKey struct {
+	sessionID string
+	path      string
+}
+
+// watchSubscription is a single watched file or directory
+type watchSubscription struct {
+	watcher *fsnotify.Watcher
+	// file is set when a file is watched through its parent directory, so
+	// that replacing the file by a rename is still noticed
+	file string
+	done chan struct{}
+}
+
+// matches reports whether an event on name concerns the watched path
+func (sub *watchSubscription) matches(name string) bool {
+	return sub.file == "" || filepath.Clean(name) == sub.file
+}
+
+// watchManager keeps the fsnotify watchers of all subscriptions and sends a
+// resources/updated notification to the subscribed session on changes
+type watchManager struct {
+	mu       sync.Mutex
+	subs     map[watchKey]*watchSubscription
+	max      int
+	debounce time.Duration
+	// notify sends the update of the resource uri to a session. It is set
+	// once the MCP server has been created.
+	notify func(sessionID, uri string)
+}
+
+func newWatchManager(maxWatchers int) *watchManager {
+	return &watchManager{
+		subs:     make(map[watchKey]*watchSubscription),
+		max:      maxWatchers,
+		debounce: WATCH_DEBOUNCE,
+		notify:   func(string, string) {},
+	}
+}
+
+// add starts watching a validated path for a session. Watching a path that
+// is already watched by the session is a no-op.
+func (m *watchManager) add(sessionID, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := watchKey{sessionID: sessionID, path: path}
+	if _, ok := m.subs[key]; ok {
+		return nil
+	}
+	if len(m.subs) >= m.max {
+		return fmt.Errorf("too many watched paths (limit %d); unwatch a path first", m.max)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	sub := &watchSubscription{watcher: watcher, done: make(chan struct{})}
+	target := path
+	if !info.IsDir() {
+		sub.file = path
+		target = filepath.Dir(path)
+	}
+	if err := watcher.Add(target); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	m.subs[key] = sub
+	go m.run(key, sub)
+	return nil
+}
+
+// remove stops watching a path for a session and reports whether it was
+// watched
+func (m *watchManager) remove(sessionID, path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := watchKey{sessionID: sessionID, path: path}
+	sub, ok := m.subs[key]
+	if ok {
+		close(sub.done)
+		delete(m.subs, key)
+	}
+	return ok
+}
+
+// removeSession stops all watches of a session, e.g. when it disconnects
+func (m *watchManager) removeSession(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, sub := range m.subs {
+		if key.sessionID == sessionID {
+			close(sub.done)
+			delete(m.subs, key)
+		}
+	}
+}
+
+// run forwards the events of a subscription until it is removed. The first
+// event starts the debounce window and every event within it is coalesced
+// into one notification, so a continuously written log is reported at most
+// once per window.
+func (m *watchManager) run(key watchKey, sub *watchSubscription) {
+	defer sub.watcher.Close()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-sub.done:
+			return
+		case event, ok := <-sub.watcher.Events:
+			if !ok {
+				return
+			}
+			if sub.matches(event.Name) && pending == nil {
+				pending = time.After(m.debounce)
+			}
+		case _, ok := <-sub.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-pending:
+			pending = nil
+			m.notify(key.sessionID, pathToResourceURI(key.path))
+		}
+	}
+}
+
+// watchTarget validates the path argument of watch and unwatch and returns
+// the calling session
+func (fs *FilesystemHandler) watchTarget(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (server.ClientSession, string, *mcp.CallToolResult) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return nil, "", &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return nil, "", &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: "Error: watching requires a client session that can receive notifications",
+				},
+			},
+			IsError: true,
+		}
+	}
+
+	validPath, err := fs.validatePath(path)
+	if err != nil {
+		return nil, "", &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %v", err),
+				},
+			},
+			IsError: true,
+		}
+	}
+	return session, validPath, nil
+}
+
+func (fs *FilesystemHandler) handleWatch(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	session, validPath, result := fs.watchTarget(ctx, request)
+	if result != nil {
+		return result, nil
+	}
+
+	if err := fs.watches.add(session.SessionID(), validPath); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error watching %s: %v", validPath, err),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	resourceURI := pathToResourceURI(validPath)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Watching %s. A %s notification for %s is sent when it changes.", validPath, mcp.MethodNotificationResourceUpdated, resourceURI),
+			},
+		},
+	}, nil
+}
+
+func (fs *FilesystemHandler) handleUnwatch(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	session, validPath, result := fs.watchTarget(ctx, request)
+	if result != nil {
+		return result, nil
+	}
+
+	if !fs.watches.remove(session.SessionID(), validPath) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Error: %s is not being watched", validPath),
+				},
+			},
+			IsError: true,
+		}, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Stopped watching %s", validPath),
+			},
+		},
+	}, nil
+}
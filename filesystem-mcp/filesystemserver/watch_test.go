@@ -0,0 +1,116 @@
+package filesystemserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSession is a client session that is never sent notifications directly;
+// the tests capture them through watchManager.notify instead
+type fakeSession struct {
+	id string
+}
+
+func (s *fakeSession) Initialize()                                         {}
+func (s *fakeSession) Initialized() bool                                   { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *fakeSession) SessionID() string                                   { return s.id }
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(file, []byte("start\n"), 0644))
+
+	handler, err := NewFilesystemHandler(resolveAllowedDirs(t, dir), WithMaxWatchers(2))
+	require.NoError(t, err)
+	handler.watches.debounce = 50 * time.Millisecond
+
+	type update struct{ sessionID, uri string }
+	updates := make(chan update, 16)
+	handler.watches.notify = func(sessionID, uri string) {
+		updates <- update{sessionID, uri}
+	}
+
+	ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), &fakeSession{id: "s1"})
+	call := func(handle func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), ctx context.Context, path string) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"path": path}
+		result, err := handle(ctx, request)
+		require.NoError(t, err)
+		return result
+	}
+
+	validFile, err := handler.validatePath(file)
+	require.NoError(t, err)
+
+	result := call(handler.handleWatch, ctx, file)
+	require.False(t, result.IsError, result.Content)
+
+	// several writes within the debounce window produce one notification
+	for i := 0; i < 3; i++ {
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString("line\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+	select {
+	case u := <-updates:
+		assert.Equal(t, update{"s1", pathToResourceURI(validFile)}, u)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification for a changed file")
+	}
+	select {
+	case u := <-updates:
+		t.Fatalf("unexpected second notification: %v", u)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// changes to other files in the same directory are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("x"), 0644))
+	select {
+	case u := <-updates:
+		t.Fatalf("unexpected notification: %v", u)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// the number of watched paths is limited
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	result = call(handler.handleWatch, ctx, filepath.Join(dir, "sub"))
+	require.False(t, result.IsError, result.Content)
+	result = call(handler.handleWatch, ctx, dir)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "too many watched paths (limit 2)")
+
+	result = call(handler.handleUnwatch, ctx, file)
+	require.False(t, result.IsError, result.Content)
+	result = call(handler.handleUnwatch, ctx, file)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "is not being watched")
+
+	require.NoError(t, os.WriteFile(file, []byte("rewritten\n"), 0644))
+	select {
+	case u := <-updates:
+		t.Fatalf("unexpected notification after unwatch: %v", u)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// watches end with their session
+	handler.watches.removeSession("s1")
+	handler.watches.mu.Lock()
+	assert.Empty(t, handler.watches.subs)
+	handler.watches.mu.Unlock()
+
+	// watching needs a session to notify
+	result = call(handler.handleWatch, context.Background(), file)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "requires a client session")
+}
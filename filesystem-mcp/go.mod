@@ -4,6 +4,7 @@ go 1.24.2
 
 require (
 	github.com/djherbis/times v1.6.0
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/gabriel-vasile/mimetype v1.4.9
 	github.com/gobwas/glob v0.2.3
 	github.com/mark3labs/mcp-go v0.32.0
@@ -4,24 +4,56 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/mark3labs/mcp-filesystem-server/filesystemserver"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
-	// Parse command line arguments
-	if len(os.Args) < 2 {
+	// Allowed directories are taken from the command line arguments and
+	// FS_ALLOWED_DIRS, a list separated like PATH
+	allowedDirs := os.Args[1:]
+	for _, dir := range filepath.SplitList(os.Getenv("FS_ALLOWED_DIRS")) {
+		if dir != "" {
+			allowedDirs = append(allowedDirs, dir)
+		}
+	}
+	if len(allowedDirs) == 0 {
 		fmt.Fprintf(
 			os.Stderr,
-			"Usage: %s <allowed-directory> [additional-directories...]\n",
+			"Usage: %s <allowed-directory> [additional-directories...]\n"+
+				"Allowed directories can also be set in FS_ALLOWED_DIRS.\n",
 			os.Args[0],
 		)
 		os.Exit(1)
 	}
 
+	// Optional behaviour is configured through the environment
+	var opts []filesystemserver.Option
+	if readOnly, ok := envBool("FS_READ_ONLY"); ok {
+		opts = append(opts, filesystemserver.WithReadOnly(readOnly))
+	}
+	if maxReadBytes, ok := envInt("FS_MAX_READ_BYTES"); ok {
+		opts = append(opts, filesystemserver.WithMaxReadBytes(maxReadBytes))
+	}
+	if maxWatchers, ok := envInt("FS_MAX_WATCHERS"); ok {
+		opts = append(opts, filesystemserver.WithMaxWatchers(int(maxWatchers)))
+	}
+	if maxConcurrency, ok := envInt("FS_MAX_CONCURRENCY"); ok {
+		opts = append(opts, filesystemserver.WithMaxConcurrency(int(maxConcurrency)))
+	}
+	if value := os.Getenv("FS_SYMLINK_POLICY"); value != "" {
+		policy, err := filesystemserver.ParseSymlinkPolicy(value)
+		if err != nil {
+			log.Fatalf("Invalid FS_SYMLINK_POLICY: %v", err)
+		}
+		opts = append(opts, filesystemserver.WithSymlinkPolicy(policy))
+	}
+
 	// Create and start the server
-	fss, err := filesystemserver.NewFilesystemServer(os.Args[1:])
+	fss, err := filesystemserver.NewFilesystemServer(allowedDirs, opts...)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
@@ -31,3 +63,32 @@ func main() {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// envBool returns the boolean value of the environment variable name and
+// whether it is set. A value that is set but not a boolean stops the server,
+// so that e.g. a mistyped FS_READ_ONLY never starts a writable server.
+func envBool(name string) (bool, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return false, false
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: must be true or false", name, value)
+	}
+	return parsed, true
+}
+
+// envInt returns the integer value of the environment variable name and
+// whether it is set. A value that is set but not an integer stops the server.
+func envInt(name string) (int64, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s %q: must be an integer", name, value)
+	}
+	return parsed, true
+}
@@ -1,26 +1,37 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/mark3labs/mcp-go/server"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/intelops/sonarqube-mcp/pkg/tools"
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
 )
 
+const serverName = "SonarQube MCP Server"
+
 var (
-	version                  = "v1.0.0"
-	transport, port, baseURL string
+	version                                = "v1.0.0"
+	transport, port, baseURL, sonarQubeURL string
 )
 
 func main() {
-	flag.StringVar(&transport, "t", "stdio", "Transport type (stdio or sse)")
-	flag.StringVar(&port, "p", "2222", "Port for SSE transport")
+	flag.StringVar(&transport, "t", "stdio", "Transport type (stdio, sse or http)")
+	flag.StringVar(&port, "p", "2222", "Port for the SSE and HTTP transports")
 	flag.StringVar(&baseURL, "b", "http://localhost:2222", "Base URL for SSE transport")
+	flag.StringVar(&sonarQubeURL, "url", "", "SonarQube base URL (default: $SONARQUBE_URL or "+tools.DEFAULT_SONARQUBE_URL+")")
 	flag.Parse()
 
+	if err := utils.ConfigureLogging(); err != nil {
+		log.Fatal(err)
+	}
+
 	if envPort, ok := os.LookupEnv("PORT"); ok {
 		port = envPort
 	}
@@ -29,9 +40,24 @@ func main() {
 		baseURL = envBaseURL
 	}
 
+	// the -url flag takes precedence over the SONARQUBE_URL environment variable
+	if sonarQubeURL == "" {
+		sonarQubeURL = os.Getenv("SONARQUBE_URL")
+	}
+	if sonarQubeURL != "" {
+		if err := tools.SetSonarQubeURL(sonarQubeURL); err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Infof("Using SonarQube at %s", tools.SONARQUBE_URL)
+
+	if err := utils.ConfigureHTTPClient(); err != nil {
+		log.Fatal(err)
+	}
+
 	// -- build your MCP server
 	mcpServer := server.NewMCPServer(
-		"SonarQube MCP Server",
+		serverName,
 		version,
 		server.WithLogging(),
 		server.WithRecovery(),
@@ -39,25 +65,41 @@ func main() {
 	)
 
 	// -- register tools in one shot (needs tools package to export ServerTool values)
+	tools.AddServerInfo(mcpServer, serverName, version)
+	tools.AddSystemStatus(mcpServer)
+	tools.AddOrganizations(mcpServer)
 	tools.AddProjects(mcpServer)
+	tools.AddFavorites(mcpServer)
+	tools.AddProjectCreate(mcpServer)
+	tools.AddProjectDelete(mcpServer)
 	tools.AddDuplications(mcpServer)
+	tools.AddPullRequests(mcpServer)
+	tools.AddEvents(mcpServer)
 	tools.AddIssues(mcpServer)
+	tools.AddIssuesByRule(mcpServer)
+	tools.AddIssueTransition(mcpServer)
+	tools.AddIssueComment(mcpServer)
+	tools.AddIssueAssign(mcpServer)
+	tools.AddIssueSetTags(mcpServer)
+	tools.AddIssueBulkTags(mcpServer)
 	tools.AddHotspots(mcpServer)
+	tools.AddHotspotShow(mcpServer)
+	tools.AddHotspotChangeStatus(mcpServer)
 	tools.AddMeasures(mcpServer)
+	tools.AddMeasuresHistory(mcpServer)
+	tools.AddMetrics(mcpServer)
+	tools.AddRuleRemediation(mcpServer)
+	tools.AddRules(mcpServer)
+	tools.AddQualityProfiles(mcpServer)
+	tools.AddSource(mcpServer)
+	tools.AddComponentsTree(mcpServer)
+	// -- SIGINT/SIGTERM cancel the root context, which stops the transport
+	// gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	// -- pick transport
-	if transport == "sse" {
-		sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL(baseURL))
-		sseEndpoint := "0.0.0.0:" + port
-		log.Infof("SonarQube MCP Server running on %s", sseEndpoint)
-		if err := sseServer.Start(sseEndpoint); err != nil {
-			log.Fatalf("Sonar MCP Server (SSE) error: %v", err)
-		}
-		log.Infof("SonarQube SSE MCP Server started on %v:%v", baseURL, port)
-	} else {
-		if err := server.ServeStdio(mcpServer); err != nil {
-			log.Fatalf("error starting SonarQube MCP Server: %v", err)
-		}
-		log.Info("SonarQube STDIO MCP Server started ...")
+	if err := serve(ctx, mcpServer, transport, port, baseURL); err != nil {
+		log.Fatalf("SonarQube MCP Server error: %v", err)
 	}
-	log.Info("SonarQube MCP Server started ...")
 }
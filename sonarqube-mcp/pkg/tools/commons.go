@@ -1,5 +1,17 @@
 package tools
 
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	log "github.com/sirupsen/logrus"
+)
+
 type Component struct {
 	Organization string `json:"organization"`
 	Key          string `json:"key"`
@@ -10,5 +22,83 @@ type Component struct {
 	Path         string `json:"path"`
 }
 
-// const SONARQUBE_URL = "https://sonarcloud.io/"
-const SONARQUBE_URL = "http://localhost:9000/"
+const (
+	// maxPageSize is the largest page size the Sonar list endpoints accept
+	maxPageSize = 500
+	// defaultMaxItems caps the elements collected with fetch_all
+	defaultMaxItems = 1000
+)
+
+// DEFAULT_SONARQUBE_URL is used when no SonarQube URL is configured
+const DEFAULT_SONARQUBE_URL = "https://sonarcloud.io/"
+
+// SONARQUBE_URL is the base URL, with a trailing slash, that all tools build
+// their API URLs from. Use SetSonarQubeURL to change it.
+var SONARQUBE_URL = DEFAULT_SONARQUBE_URL
+
+// SetSonarQubeURL validates and sets the base URL of the SonarQube instance
+func SetSonarQubeURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid SonarQube URL %q: %w", rawURL, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid SonarQube URL %q: must be an absolute http or https URL", rawURL)
+	}
+
+	if !strings.HasSuffix(rawURL, "/") {
+		rawURL += "/"
+	}
+	SONARQUBE_URL = rawURL
+	return nil
+}
+
+// sonarDateLayouts are the date formats accepted by the Sonar web API
+var sonarDateLayouts = []string{"2006-01-02", "2006-01-02T15:04:05-0700"}
+
+// validateSonarDate checks that date is empty or in a format the Sonar web API accepts
+func validateSonarDate(date string) error {
+	if date == "" {
+		return nil
+	}
+	for _, layout := range sonarDateLayouts {
+		if _, err := time.Parse(layout, date); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q: expected YYYY-MM-DD or YYYY-MM-DDThh:mm:ss+hhmm", date)
+}
+
+// noCacheOption is the no_cache parameter shared by the read-only tools
+func noCacheOption() mcp.ToolOption {
+	return mcp.WithBoolean("no_cache",
+		mcp.Description("Bypass the response cache and fetch fresh data from the API. Only relevant when SONAR_CACHE_TTL is set."),
+		mcp.DefaultBool(false),
+	)
+}
+
+// applyDeprecatedAliases copies arguments passed under a deprecated name,
+// the key of aliases, to their current name so clients written against
+// older versions keep working. An argument given under both names keeps the
+// value of the current name.
+func applyDeprecatedAliases(request mcp.CallToolRequest, aliases map[string]string) {
+	args := request.GetArguments()
+	for deprecated, current := range aliases {
+		value, ok := args[deprecated]
+		if !ok {
+			continue
+		}
+		log.Warnf("parameter %s is deprecated, use %s", deprecated, current)
+		if _, set := args[current]; !set {
+			args[current] = value
+		}
+	}
+}
+
+// cacheContext returns ctx, bypassing the response cache when the request sets no_cache
+func cacheContext(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	if request.GetBool("no_cache", false) {
+		return utils.WithoutCache(ctx)
+	}
+	return ctx
+}
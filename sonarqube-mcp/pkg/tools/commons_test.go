@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestSonarServer starts a test server answering with handler, points
+// SONARQUBE_URL at it and sets a token. Everything is restored when the test
+// ends.
+func newTestSonarServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	original := SONARQUBE_URL
+	t.Cleanup(func() { SONARQUBE_URL = original })
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if err := SetSonarQubeURL(server.URL); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return server
+}
+
+func TestSetSonarQubeURL(t *testing.T) {
+	defer func(original string) { SONARQUBE_URL = original }(SONARQUBE_URL)
+
+	if SONARQUBE_URL != DEFAULT_SONARQUBE_URL {
+		t.Errorf("expected default %s, got %s", DEFAULT_SONARQUBE_URL, SONARQUBE_URL)
+	}
+
+	if err := SetSonarQubeURL("https://sonar.example.com/sonarqube"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if SONARQUBE_URL != "https://sonar.example.com/sonarqube/" {
+		t.Errorf("expected a trailing slash to be added, got %s", SONARQUBE_URL)
+	}
+
+	for _, invalid := range []string{"sonar.example.com", "ftp://sonar.example.com/", "://"} {
+		if err := SetSonarQubeURL(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+	if SONARQUBE_URL != "https://sonar.example.com/sonarqube/" {
+		t.Errorf("invalid URLs must not change the configured URL, got %s", SONARQUBE_URL)
+	}
+}
+
+func TestApplyDeprecatedAliases(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"fetchAll":  true,
+		"maxIssues": float64(50),
+		"pageSize":  float64(10),
+		"page_size": float64(20),
+	}
+	applyDeprecatedAliases(request, issuesParamAliases)
+
+	if !request.GetBool("fetch_all", false) || request.GetInt("max_items", 0) != 50 {
+		t.Errorf("expected the deprecated names to be copied, got %v", request.GetArguments())
+	}
+	if request.GetInt("page_size", 0) != 20 {
+		t.Errorf("expected page_size to win over pageSize, got %v", request.GetArguments())
+	}
+}
+
+func TestToolsUseConfiguredURL(t *testing.T) {
+	var requestedPath string
+	server := newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if requestedPath == "" {
+			requestedPath = r.URL.Path
+		}
+		w.Write([]byte(issuesRuleFacetFixture))
+	})
+
+	if err := SetSonarQubeURL(server.URL + "/sonar"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := countIssuesByRule(context.Background(), "", "my_project", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestedPath != "/sonar/api/issues/search" {
+		t.Errorf("expected request to the configured instance, got path %q", requestedPath)
+	}
+}
+
+// toolServer returns a server with the tools registered by add
+func toolServer(add ...func(*server.MCPServer)) *server.MCPServer {
+	s := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(false))
+	for _, register := range add {
+		register(s)
+	}
+	return s
+}
+
+// handleJSONRPC sends a JSON-RPC request for method to s, as a client would,
+// and returns the result
+func handleJSONRPC(t *testing.T, s *server.MCPServer, method string, params any) any {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := s.HandleMessage(context.Background(), message)
+	result, ok := response.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("%s failed: %+v", method, response)
+	}
+	return result.Result
+}
+
+var snakeCasePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+func TestToolParamsAreSnakeCase(t *testing.T) {
+	s := toolServer(
+		AddComponentsTree, AddDuplications, AddEvents, AddFavorites, AddHotspotChangeStatus,
+		AddHotspots, AddHotspotShow, AddIssueTransition, AddIssueComment, AddIssueAssign,
+		AddIssueSetTags, AddIssueBulkTags, AddIssues, AddIssuesByRule, AddMeasures,
+		AddMeasuresHistory, AddMetrics, AddOrganizations, AddProjectCreate, AddProjectDelete,
+		AddProjects, AddPullRequests, AddQualityProfiles, AddRuleRemediation, AddRules,
+		AddSource, AddSystemStatus,
+		func(s *server.MCPServer) { AddServerInfo(s, "test", "0.0.0") },
+	)
+
+	list := handleJSONRPC(t, s, "tools/list", map[string]any{}).(mcp.ListToolsResult)
+	if len(list.Tools) != 28 {
+		t.Fatalf("expected 28 tools, got %d", len(list.Tools))
+	}
+	for _, tool := range list.Tools {
+		for name := range tool.InputSchema.Properties {
+			if !snakeCasePattern.MatchString(name) {
+				t.Errorf("%s: parameter %s is not snake_case", tool.Name, name)
+			}
+		}
+	}
+}
+
+func TestDeprecatedParamAliases(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "measures.json")
+	tests := []struct {
+		add  func(*server.MCPServer)
+		tool string
+		args map[string]any
+		want url.Values
+	}{
+		{AddIssues, "sonar_issues",
+			map[string]any{"projectKey": "my_project", "pageSize": 5, "issueStatus": []any{"CONFIRMED"}},
+			url.Values{"projectKey": {"my_project"}, "ps": {"5"}, "issueStatuses": {"CONFIRMED"}}},
+		{AddIssuesByRule, "sonar_issues_by_rule",
+			map[string]any{"projectKey": "my_project"},
+			url.Values{"projectKey": {"my_project"}}},
+		{AddHotspots, "sonar_hotspots",
+			map[string]any{"projectKey": "my_project"},
+			url.Values{"projectKey": {"my_project"}}},
+		{AddComponentsTree, "sonar_components_tree",
+			map[string]any{"component": "my_project", "pageSize": 7},
+			url.Values{"component": {"my_project"}, "ps": {"7"}}},
+		{AddMeasures, "sonar_measures",
+			map[string]any{"projectKey": "my_project", "outputFile": outputFile, "metricKeys": []any{"complexity"}},
+			url.Values{"component": {"my_project"}, "metricKeys": {"complexity"}}},
+		{AddMeasuresHistory, "sonar_measures_history",
+			map[string]any{"projectKey": "my_project", "metricKeys": []any{"coverage"}},
+			url.Values{"component": {"my_project"}, "metrics": {"coverage"}}},
+		{AddRules, "sonar_rules",
+			map[string]any{"ruleKey": "go:S3776", "pageSize": 3},
+			url.Values{"rule_key": {"go:S3776"}, "ps": {"3"}}},
+		{AddMetrics, "sonar_metrics",
+			map[string]any{"pageSize": 9},
+			url.Values{"ps": {"9"}}},
+		{AddDuplications, "sonar_duplications",
+			map[string]any{"branch": "main", "key": "", "pullRequest": "5461"},
+			url.Values{"pullRequest": {"5461"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			var query url.Values
+			newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if query == nil {
+					query = r.URL.Query()
+				}
+				w.Write([]byte(`{}`))
+			})
+
+			result := handleJSONRPC(t, toolServer(tt.add), "tools/call", map[string]any{"name": tt.tool, "arguments": tt.args}).(mcp.CallToolResult)
+			if result.IsError {
+				t.Fatalf("unexpected tool error: %+v", result.Content)
+			}
+			for key, values := range tt.want {
+				if got := query[key]; len(got) != 1 || got[0] != values[0] {
+					t.Errorf("expected %s=%s from the deprecated names, got %v", key, values[0], query)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type ComponentsTreeResponse struct {
+	Paging        Paging      `json:"paging"`
+	BaseComponent Component   `json:"baseComponent"`
+	Components    []Component `json:"components"`
+}
+
+// ComponentsTreePage is the sonar_components_tree output
+type ComponentsTreePage struct {
+	Paging        Paging      `json:"paging"`
+	HasMore       bool        `json:"hasMore"`
+	Truncated     bool        `json:"truncated,omitempty"`
+	BaseComponent Component   `json:"baseComponent"`
+	Components    []Component `json:"components"`
+}
+
+// ComponentsTreeOptions are the filters and paging of a components tree request
+type ComponentsTreeOptions struct {
+	Component  string
+	Qualifiers []string
+	Strategy   string
+	Branch     string
+	Page       int
+	PageSize   int
+	// FetchAll follows the paging information until MaxItems components are collected
+	FetchAll bool
+	MaxItems int
+}
+
+// componentsTreeParamAliases maps the deprecated camelCase parameters of
+// sonar_components_tree to their current names
+var componentsTreeParamAliases = map[string]string{
+	"pageSize": "page_size",
+}
+
+func AddComponentsTree(s *server.MCPServer) {
+	// create a new MCP tool for navigating the component hierarchy
+	treeTool := mcp.NewTool("sonar_components_tree",
+		mcp.WithDescription("Navigate the directory and file structure of a Sonar project. Returns the components below a base component, whose keys can be passed to sonar_measures, sonar_source or sonar_hotspots."),
+		mcp.WithString("component",
+			mcp.Description("Key of the base component, e.g. my_project or my_project:src/foo."),
+			mcp.Required(),
+		),
+		mcp.WithArray("qualifiers",
+			mcp.Description("Only return components with these qualifiers: FIL (files), DIR (directories), UTS (test files). This parameter is optional."),
+			mcp.Enum("FIL", "DIR", "UTS"),
+		),
+		mcp.WithString("strategy",
+			mcp.Description("Which components to return: all descendants, only direct children, or only leaves."),
+			mcp.DefaultString("all"),
+			mcp.Enum("all", "children", "leaves"),
+		),
+		mcp.WithString("branch",
+			mcp.Description("The SCM branch key or name (optional), e.g. feature/my_branch"),
+			mcp.DefaultString(""),
+		),
+		mcp.WithNumber("page",
+			mcp.Description("1-based page number to retrieve."),
+			mcp.DefaultNumber(1),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("Number of components per page (max 500). Defaults to the server's page size."),
+		),
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch all pages of components, up to max_items components."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of components to collect when fetch_all is set."),
+			mcp.DefaultNumber(defaultMaxItems),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(treeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, componentsTreeParamAliases)
+		args := request.GetArguments()
+
+		component, ok := args["component"].(string)
+		if !ok || component == "" {
+			return mcp.NewToolResultError("missing component parameter"), nil
+		}
+		// qualifiers are empty when omitted
+		qualifiers, _ := args["qualifiers"].([]any)
+
+		opts := ComponentsTreeOptions{
+			Component:  component,
+			Qualifiers: utils.InterfacesToStringsOrEmpty(qualifiers),
+			Strategy:   request.GetString("strategy", "all"),
+			Branch:     request.GetString("branch", ""),
+			Page:       request.GetInt("page", 1),
+			PageSize:   request.GetInt("page_size", 0),
+			FetchAll:   request.GetBool("fetch_all", false),
+			MaxItems:   request.GetInt("max_items", defaultMaxItems),
+		}
+
+		tree, err := fetchComponentsTree(ctx, opts)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve the components tree.", err), nil
+		}
+
+		return mcp.NewToolResultText(tree), nil
+	})
+}
+
+func componentsTreeURL(opts ComponentsTreeOptions, page, pageSize int) string {
+	params := url.Values{}
+	params.Set("component", opts.Component)
+	if len(opts.Qualifiers) > 0 {
+		params.Set("qualifiers", strings.Join(opts.Qualifiers, ","))
+	}
+	if opts.Strategy != "" {
+		params.Set("strategy", opts.Strategy)
+	}
+	if opts.Branch != "" {
+		params.Set("branch", opts.Branch)
+	}
+	if page > 0 {
+		params.Set("p", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		params.Set("ps", strconv.Itoa(pageSize))
+	}
+	return SONARQUBE_URL + "api/components/tree?" + params.Encode()
+}
+
+func fetchComponentsTree(ctx context.Context, opts ComponentsTreeOptions) (string, error) {
+	// the first page also carries the base component
+	pageSize := opts.PageSize
+	page := opts.Page
+	if opts.FetchAll {
+		page = 1
+		if pageSize <= 0 {
+			pageSize = maxPageSize
+		}
+	}
+
+	body, err := utils.MakeGetRequest(ctx, componentsTreeURL(opts, page, pageSize))
+	if err != nil {
+		return "", err
+	}
+	var response ComponentsTreeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	result := ComponentsTreePage{
+		Paging:        response.Paging,
+		HasMore:       response.Paging.PageIndex*response.Paging.PageSize < response.Paging.Total,
+		BaseComponent: response.BaseComponent,
+		Components:    response.Components,
+	}
+
+	maxItems := opts.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultMaxItems
+	}
+	if opts.FetchAll && (result.HasMore || len(result.Components) > maxItems) {
+		components, total, truncated, err := utils.MakePaginatedGetRequest[Component](ctx, componentsTreeURL(opts, 0, 0), "components", pageSize, maxItems)
+		if err != nil {
+			return "", err
+		}
+		result.Components = components
+		result.Paging = Paging{PageIndex: 1, PageSize: len(components), Total: total}
+		result.Truncated = truncated
+		result.HasMore = truncated
+	}
+
+	return utils.PrettyPrint(result)
+}
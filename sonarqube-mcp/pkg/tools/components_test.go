@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestFetchComponentsTree(t *testing.T) {
+	// five files below the project, served in pages of the requested size
+	var query string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/components/tree" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("ps"))
+
+		response := ComponentsTreeResponse{
+			Paging:        Paging{PageIndex: page, PageSize: pageSize, Total: 5},
+			BaseComponent: Component{Key: "my_project", Qualifier: "TRK", Name: "My Project"},
+		}
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= 5; i++ {
+			path := fmt.Sprintf("src/file%d.go", i)
+			response.Components = append(response.Components, Component{Key: "my_project:" + path, Qualifier: "FIL", Path: path})
+		}
+		json.NewEncoder(w).Encode(response)
+	})
+
+	opts := ComponentsTreeOptions{Component: "my_project", Qualifiers: []string{"FIL"}, Strategy: "leaves", Page: 2, PageSize: 2}
+	output, err := fetchComponentsTree(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "component=my_project&p=2&ps=2&qualifiers=FIL&strategy=leaves" {
+		t.Errorf("unexpected query %q", query)
+	}
+
+	var result ComponentsTreePage
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if result.BaseComponent.Key != "my_project" || len(result.Components) != 2 || result.Components[0].Path != "src/file3.go" || !result.HasMore {
+		t.Errorf("unexpected second page: %+v", result)
+	}
+
+	// fetch_all collects every page
+	opts.FetchAll = true
+	opts.MaxItems = 100
+	output, err = fetchComponentsTree(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if result.BaseComponent.Key != "my_project" || len(result.Components) != 5 || result.HasMore {
+		t.Errorf("expected all 5 components, got %+v", result)
+	}
+}
@@ -28,6 +28,12 @@ type DuplicationsResponse struct {
 	Files        map[string]File `json:"files"`
 }
 
+// duplicationsParamAliases maps the deprecated camelCase parameters of
+// sonar_duplications to their current names
+var duplicationsParamAliases = map[string]string{
+	"pullRequest": "pull_request",
+}
+
 func AddDuplications(s *server.MCPServer) {
 	// create a new MCP tool for showing duplications
 	duplicationsTool := mcp.NewTool("sonar_duplications",
@@ -41,22 +47,25 @@ func AddDuplications(s *server.MCPServer) {
 			mcp.Description("The file key (optional), e.g. my_project:/src/foo/Bar.php"),
 			mcp.DefaultString(""),
 		),
-		mcp.WithString("pullRequest",
+		mcp.WithString("pull_request",
 			mcp.Description("The pull request key (optional), e.g. 5461"),
 			mcp.DefaultString(""),
 		),
+		noCacheOption(),
 	)
 
 	// add the tool to the server
 	s.AddTool(duplicationsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, duplicationsParamAliases)
 		args := request.GetArguments()
 		// extract the parameters from the request
 		branch := args["branch"].(string)
 		key := args["key"].(string)
-		pullRequest := args["pullRequest"].(string)
+		pullRequest := request.GetString("pull_request", "")
 
 		// call the Sonarcloud API to get the duplications
-		duplications, err := showDuplications(branch, key, pullRequest)
+		duplications, err := showDuplications(ctx, branch, key, pullRequest)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("unable to retrieve duplications.", err), nil
 		}
@@ -65,7 +74,7 @@ func AddDuplications(s *server.MCPServer) {
 	})
 }
 
-func showDuplications(branch, key, pullRequest string) (string, error) {
+func showDuplications(ctx context.Context, branch, key, pullRequest string) (string, error) {
 	keyParam := ""
 	if key != "" {
 		keyParam = fmt.Sprintf("&key=%s", key)
@@ -77,7 +86,7 @@ func showDuplications(branch, key, pullRequest string) (string, error) {
 
 	url := fmt.Sprintf(SONARQUBE_URL+"api/duplications/show?branch=%s%s%s", branch, keyParam, pullRequestParam)
 
-	body, err := utils.MakeGetRequest(url)
+	body, err := utils.MakeGetRequest(ctx, url)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// eventCategories are the event categories api/project_analyses/search accepts
+var eventCategories = []string{"VERSION", "QUALITY_GATE", "QUALITY_PROFILE", "DEFINITION_CHANGE", "ISSUE_DETECTION", "SQ_UPGRADE", "OTHER"}
+
+type FailingCondition struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// EventQualityGate is the quality gate status change recorded by a
+// QUALITY_GATE event
+type EventQualityGate struct {
+	Status       string             `json:"status"`
+	StillFailing bool               `json:"stillFailing"`
+	Failing      []FailingCondition `json:"failing,omitempty"`
+}
+
+type AnalysisEvent struct {
+	Key         string            `json:"key"`
+	Category    string            `json:"category"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	QualityGate *EventQualityGate `json:"qualityGate,omitempty"`
+}
+
+type ProjectAnalysis struct {
+	Key            string          `json:"key"`
+	Date           string          `json:"date"`
+	ProjectVersion string          `json:"projectVersion"`
+	Events         []AnalysisEvent `json:"events"`
+}
+
+// ProjectEvent is an event of a project analysis, flattened with the date and
+// version of the analysis that recorded it
+type ProjectEvent struct {
+	Key            string            `json:"key"`
+	Category       string            `json:"category"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description,omitempty"`
+	Date           string            `json:"date"`
+	Analysis       string            `json:"analysis"`
+	ProjectVersion string            `json:"projectVersion,omitempty"`
+	QualityGate    *EventQualityGate `json:"qualityGate,omitempty"`
+}
+
+type ProjectEventsResponse struct {
+	Truncated bool           `json:"truncated,omitempty"`
+	Events    []ProjectEvent `json:"events"`
+}
+
+func AddEvents(s *server.MCPServer) {
+	// create a new MCP tool for listing the events of a project
+	eventsTool := mcp.NewTool("sonar_events",
+		mcp.WithDescription("List the events of a project's analyses, newest first: version changes, quality gate status changes, quality profile changes and custom events, each with the date of the analysis that recorded it."),
+		mcp.WithString("project",
+			mcp.Description("The project key, e.g. my_project."),
+			mcp.Required(),
+		),
+		mcp.WithString("category",
+			mcp.Description("Only return events of this category, e.g. VERSION or QUALITY_GATE. Custom events have the category OTHER. This parameter is optional."),
+			mcp.DefaultString(""),
+			mcp.Enum(eventCategories...),
+		),
+		mcp.WithString("from",
+			mcp.Description("Only return events of analyses on or after this date, e.g. 2024-01-31 or 2024-01-31T13:00:00+0100. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("to",
+			mcp.Description("Only return events of analyses on or before this date, e.g. 2024-06-30. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("branch",
+			mcp.Description("The SCM branch key or name (optional), e.g. feature/my_branch"),
+			mcp.DefaultString(""),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of analyses to read events from."),
+			mcp.DefaultNumber(defaultMaxItems),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(eventsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		args := request.GetArguments()
+
+		project, ok := args["project"].(string)
+		if !ok || project == "" {
+			return mcp.NewToolResultError("missing project parameter"), nil
+		}
+		category, _ := args["category"].(string)
+		from, _ := args["from"].(string)
+		to, _ := args["to"].(string)
+		branch, _ := args["branch"].(string)
+		maxItems := request.GetInt("max_items", defaultMaxItems)
+
+		for _, date := range []string{from, to} {
+			if err := validateSonarDate(date); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		events, err := searchEvents(ctx, project, category, from, to, branch, maxItems)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve project events.", err), nil
+		}
+
+		return mcp.NewToolResultText(events), nil
+	})
+}
+
+func searchEvents(ctx context.Context, project, category, from, to, branch string, maxItems int) (string, error) {
+	params := url.Values{}
+	params.Set("project", project)
+	if category != "" {
+		params.Set("category", category)
+	}
+	if from != "" {
+		params.Set("from", from)
+	}
+	if to != "" {
+		params.Set("to", to)
+	}
+	if branch != "" {
+		params.Set("branch", branch)
+	}
+
+	endpoint := SONARQUBE_URL + "api/project_analyses/search?" + params.Encode()
+	analyses, _, truncated, err := utils.MakePaginatedGetRequest[ProjectAnalysis](ctx, endpoint, "analyses", maxPageSize, maxItems)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(ProjectEventsResponse{
+		Truncated: truncated,
+		Events:    flattenEvents(analyses, category),
+	})
+}
+
+// flattenEvents lists the events of analyses, keeping only those of category
+// when set. The category parameter selects analyses with at least one such
+// event, which may record events of other categories as well.
+func flattenEvents(analyses []ProjectAnalysis, category string) []ProjectEvent {
+	events := []ProjectEvent{}
+	for _, analysis := range analyses {
+		for _, event := range analysis.Events {
+			if category != "" && event.Category != category {
+				continue
+			}
+			events = append(events, ProjectEvent{
+				Key:            event.Key,
+				Category:       event.Category,
+				Name:           event.Name,
+				Description:    event.Description,
+				Date:           analysis.Date,
+				Analysis:       analysis.Key,
+				ProjectVersion: analysis.ProjectVersion,
+				QualityGate:    event.QualityGate,
+			})
+		}
+	}
+	return events
+}
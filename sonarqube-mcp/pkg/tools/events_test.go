@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSearchEvents(t *testing.T) {
+	var query string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/project_analyses/search" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.Query().Get("category") + "|" + r.URL.Query().Get("from")
+		w.Write([]byte(`{"paging":{"pageIndex":1,"pageSize":500,"total":2},"analyses":[
+			{"key":"A2","date":"2024-05-02T10:00:00+0000","projectVersion":"1.1","events":[
+				{"key":"E3","category":"VERSION","name":"1.1"},
+				{"key":"E4","category":"QUALITY_GATE","name":"Failed","qualityGate":{"status":"ERROR","stillFailing":false,"failing":[{"key":"my_project","name":"My Project","branch":"main"}]}}
+			]},
+			{"key":"A1","date":"2024-05-01T10:00:00+0000","projectVersion":"1.0","events":[
+				{"key":"E1","category":"OTHER","name":"Release candidate","description":"Tagged for QA"}
+			]}
+		]}`))
+	})
+
+	output, err := searchEvents(context.Background(), "my_project", "", "2024-05-01", "", "", defaultMaxItems)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "|2024-05-01" {
+		t.Errorf("unexpected category and from parameters %q", query)
+	}
+
+	var response ProjectEventsResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if len(response.Events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", response.Events)
+	}
+	version := response.Events[0]
+	if version.Key != "E3" || version.Category != "VERSION" || version.Date != "2024-05-02T10:00:00+0000" || version.Analysis != "A2" || version.ProjectVersion != "1.1" {
+		t.Errorf("unexpected version event %+v", version)
+	}
+	gate := response.Events[1].QualityGate
+	if gate == nil || gate.Status != "ERROR" || len(gate.Failing) != 1 || gate.Failing[0].Branch != "main" {
+		t.Errorf("unexpected quality gate event %+v", response.Events[1])
+	}
+	custom := response.Events[2]
+	if custom.Category != "OTHER" || custom.Name != "Release candidate" || custom.Description != "Tagged for QA" {
+		t.Errorf("unexpected custom event %+v", custom)
+	}
+
+	// analyses matching the category may record events of other categories
+	output, err = searchEvents(context.Background(), "my_project", "QUALITY_GATE", "", "", "", defaultMaxItems)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "QUALITY_GATE|" {
+		t.Errorf("unexpected category and from parameters %q", query)
+	}
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	for _, event := range response.Events {
+		if event.Category != "QUALITY_GATE" {
+			t.Errorf("expected only QUALITY_GATE events, got %+v", event)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Favorite is a component the authenticated user marked as favorite
+type Favorite struct {
+	Key          string `json:"key"`
+	Name         string `json:"name"`
+	Qualifier    string `json:"qualifier"`
+	Organization string `json:"organization,omitempty"`
+}
+
+type FavoritesResponse struct {
+	Truncated bool       `json:"truncated,omitempty"`
+	Favorites []Favorite `json:"favorites"`
+}
+
+func AddFavorites(s *server.MCPServer) {
+	// create a new MCP tool for listing the favorite components of the token's user
+	favoritesTool := mcp.NewTool("sonar_favorites",
+		mcp.WithDescription("List the favorite projects and other components of the user the token belongs to. A quick starting set of projects to work with, instead of listing an entire organization."),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(favoritesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+
+		favorites, err := searchFavorites(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve favorites.", err), nil
+		}
+
+		return mcp.NewToolResultText(favorites), nil
+	})
+}
+
+func searchFavorites(ctx context.Context) (string, error) {
+	favorites, _, truncated, err := utils.MakePaginatedGetRequest[Favorite](ctx, SONARQUBE_URL+"api/favorites/search", "favorites", maxPageSize, defaultMaxItems)
+	if err != nil {
+		return "", err
+	}
+	if favorites == nil {
+		favorites = []Favorite{}
+	}
+
+	return utils.PrettyPrint(FavoritesResponse{
+		Truncated: truncated,
+		Favorites: favorites,
+	})
+}
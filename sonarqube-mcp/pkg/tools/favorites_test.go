@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSearchFavorites(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/favorites/search" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.URL.Query().Get("p") != "1" {
+			t.Errorf("unexpected page %q", r.URL.Query().Get("p"))
+		}
+		w.Write([]byte(`{"paging":{"pageIndex":1,"pageSize":500,"total":2},"favorites":[{"organization":"my-org","key":"my_project","name":"My Project","qualifier":"TRK"},{"key":"my_project:src/main.go","name":"main.go","qualifier":"FIL"}]}`))
+	})
+
+	output, err := searchFavorites(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response FavoritesResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	want := []Favorite{
+		{Key: "my_project", Name: "My Project", Qualifier: "TRK", Organization: "my-org"},
+		{Key: "my_project:src/main.go", Name: "main.go", Qualifier: "FIL"},
+	}
+	if response.Truncated || len(response.Favorites) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, response)
+	}
+	for i := range want {
+		if response.Favorites[i] != want[i] {
+			t.Errorf("expected %+v, got %+v", want[i], response.Favorites[i])
+		}
+	}
+}
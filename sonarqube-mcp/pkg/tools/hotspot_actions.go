@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// HotspotStatus is the result of sonar_hotspot_change_status
+type HotspotStatus struct {
+	Hotspot    string `json:"hotspot"`
+	Status     string `json:"status"`
+	Resolution string `json:"resolution,omitempty"`
+}
+
+func AddHotspotChangeStatus(s *server.MCPServer) {
+	// create a new MCP tool for reviewing a security hotspot
+	changeStatusTool := mcp.NewTool("sonar_hotspot_change_status",
+		mcp.WithDescription("Change the review status of a security hotspot, e.g. mark it as reviewed and safe or fixed. Returns the hotspot's new status."),
+		mcp.WithString("hotspot",
+			mcp.Description("Key of the security hotspot, e.g. AU-TpxcA-iU5OvuD2FL0."),
+			mcp.Required(),
+		),
+		mcp.WithString("status",
+			mcp.Description("The new status of the hotspot. Possible values: TO_REVIEW, REVIEWED."),
+			mcp.Required(),
+			mcp.Enum("TO_REVIEW", "REVIEWED"),
+		),
+		mcp.WithString("resolution",
+			mcp.Description("The resolution of a reviewed hotspot, required when status is REVIEWED and not allowed otherwise. Possible values: FIXED, SAFE, ACKNOWLEDGED."),
+			mcp.Enum("FIXED", "SAFE", "ACKNOWLEDGED"),
+		),
+		mcp.WithString("comment",
+			mcp.Description("Comment explaining the review. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(changeStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		hotspot, ok := args["hotspot"].(string)
+		if !ok || hotspot == "" {
+			return mcp.NewToolResultError("missing hotspot parameter"), nil
+		}
+		status, ok := args["status"].(string)
+		if !ok || status == "" {
+			return mcp.NewToolResultError("missing status parameter"), nil
+		}
+		resolution, _ := args["resolution"].(string)
+		comment, _ := args["comment"].(string)
+
+		if err := validateHotspotStatus(status, resolution); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		updated, err := changeHotspotStatus(ctx, hotspot, status, resolution, comment)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to change hotspot status.", err), nil
+		}
+
+		return mcp.NewToolResultText(updated), nil
+	})
+}
+
+// validateHotspotStatus checks that a resolution is given exactly when the
+// hotspot is marked as reviewed, as api/hotspots/change_status requires
+func validateHotspotStatus(status, resolution string) error {
+	switch status {
+	case "REVIEWED":
+		switch resolution {
+		case "FIXED", "SAFE", "ACKNOWLEDGED":
+		case "":
+			return fmt.Errorf("resolution is required when status is REVIEWED")
+		default:
+			return fmt.Errorf("invalid resolution %q: must be FIXED, SAFE or ACKNOWLEDGED", resolution)
+		}
+	case "TO_REVIEW":
+		if resolution != "" {
+			return fmt.Errorf("resolution is only allowed when status is REVIEWED")
+		}
+	default:
+		return fmt.Errorf("invalid status %q: must be TO_REVIEW or REVIEWED", status)
+	}
+	return nil
+}
+
+func changeHotspotStatus(ctx context.Context, hotspot, status, resolution, comment string) (string, error) {
+	form := url.Values{}
+	form.Set("hotspot", hotspot)
+	form.Set("status", status)
+	if resolution != "" {
+		form.Set("resolution", resolution)
+	}
+	if comment != "" {
+		form.Set("comment", comment)
+	}
+
+	// the endpoint answers with no content, so read the hotspot back to confirm
+	if _, err := utils.MakePostRequest(ctx, SONARQUBE_URL+"api/hotspots/change_status", form); err != nil {
+		return "", err
+	}
+
+	body, err := utils.MakeGetRequest(utils.WithoutCache(ctx), SONARQUBE_URL+"api/hotspots/show?hotspot="+url.QueryEscape(hotspot))
+	if err != nil {
+		return "", err
+	}
+	details, err := parseHotspotDetails(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(HotspotStatus{Hotspot: details.Key, Status: details.Status, Resolution: details.Resolution})
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestValidateHotspotStatus(t *testing.T) {
+	tests := []struct {
+		status     string
+		resolution string
+		valid      bool
+	}{
+		{"REVIEWED", "SAFE", true},
+		{"REVIEWED", "ACKNOWLEDGED", true},
+		{"REVIEWED", "", false},
+		{"REVIEWED", "WONTFIX", false},
+		{"TO_REVIEW", "", true},
+		{"TO_REVIEW", "FIXED", false},
+		{"TO_REVIEW", "SAFE", false},
+		{"CLOSED", "", false},
+	}
+	for _, tt := range tests {
+		err := validateHotspotStatus(tt.status, tt.resolution)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateHotspotStatus(%q, %q) returned %v, expected valid=%v", tt.status, tt.resolution, err, tt.valid)
+		}
+	}
+}
+
+func TestChangeHotspotStatus(t *testing.T) {
+	changed := false
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/hotspots/change_status":
+			r.ParseForm()
+			if r.PostForm.Get("hotspot") != "AX-hotspot" || r.PostForm.Get("status") != "REVIEWED" ||
+				r.PostForm.Get("resolution") != "SAFE" || r.PostForm.Get("comment") != "Input is a constant" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+			changed = true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/hotspots/show":
+			if !changed {
+				t.Error("expected the hotspot to be read back after the change")
+			}
+			w.Write([]byte(`{"key":"AX-hotspot","status":"REVIEWED","resolution":"SAFE","rule":{"key":"go:S2077"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	output, err := changeHotspotStatus(context.Background(), "AX-hotspot", "REVIEWED", "SAFE", "Input is a constant")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var status HotspotStatus
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if status != (HotspotStatus{Hotspot: "AX-hotspot", Status: "REVIEWED", Resolution: "SAFE"}) {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/intelops/sonarqube-mcp/pkg/utils"
@@ -33,17 +34,62 @@ type Hotspot struct {
 	TextRange                TextRange `json:"textRange"`
 	RuleKey                  string    `json:"ruleKey"`
 }
+type HotspotRule struct {
+	Key                      string `json:"key"`
+	Name                     string `json:"name"`
+	SecurityCategory         string `json:"securityCategory"`
+	VulnerabilityProbability string `json:"vulnerabilityProbability"`
+	RiskDescription          string `json:"riskDescription"`
+	VulnerabilityDescription string `json:"vulnerabilityDescription"`
+	FixRecommendations       string `json:"fixRecommendations"`
+}
+type ChangelogDiff struct {
+	Key      string `json:"key"`
+	OldValue string `json:"oldValue,omitempty"`
+	NewValue string `json:"newValue,omitempty"`
+}
+type ChangelogEntry struct {
+	User         string          `json:"user"`
+	UserName     string          `json:"userName"`
+	CreationDate string          `json:"creationDate"`
+	Diffs        []ChangelogDiff `json:"diffs"`
+}
+type HotspotDetails struct {
+	Key             string           `json:"key"`
+	Component       Component        `json:"component"`
+	Project         Component        `json:"project"`
+	Rule            HotspotRule      `json:"rule"`
+	Status          string           `json:"status"`
+	Resolution      string           `json:"resolution,omitempty"`
+	Line            int              `json:"line"`
+	Message         string           `json:"message"`
+	Assignee        string           `json:"assignee,omitempty"`
+	Author          string           `json:"author"`
+	CreationDate    string           `json:"creationDate"`
+	UpdateDate      string           `json:"updateDate"`
+	TextRange       TextRange        `json:"textRange"`
+	Changelog       []ChangelogEntry `json:"changelog"`
+	Comment         []Comment        `json:"comment"`
+	CanChangeStatus bool             `json:"canChangeStatus"`
+}
 type HotspotsResponse struct {
 	Paging     Paging      `json:"paging"`
+	Truncated  bool        `json:"truncated,omitempty"`
 	Hotspots   []Hotspot   `json:"hotspots"`
 	Components []Component `json:"components"`
 }
 
+// hotspotsParamAliases maps the deprecated camelCase parameters of
+// sonar_hotspots to their current names
+var hotspotsParamAliases = map[string]string{
+	"projectKey": "project_key",
+}
+
 func AddHotspots(s *server.MCPServer) {
 	// create a new MCP tool for searching security hotspots
 	hotspotsTool := mcp.NewTool("sonar_hotspots",
 		mcp.WithDescription("Search and get security hotpots in the source files of a specified Sonar project."),
-		mcp.WithString("projectKey",
+		mcp.WithString("project_key",
 			mcp.Description("Key of the project or application, e.g. my_project."),
 			mcp.Required(),
 		),
@@ -56,31 +102,47 @@ func AddHotspots(s *server.MCPServer) {
 			mcp.DefaultString(""),
 			mcp.Enum("TO_REVIEW", "REVIEWED"),
 		),
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch all pages of hotspots, up to max_items hotspots. By default only the first page is returned."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of hotspots to collect when fetch_all is set."),
+			mcp.DefaultNumber(defaultMaxItems),
+		),
+		noCacheOption(),
 	)
 
 	// add the tool to the server
-	s.AddTool(hotspotsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		// extract the parameters from the request
-		args := request.GetArguments()
+	s.AddTool(hotspotsTool, handleHotspots)
+}
 
-		projectKey, ok := args["projectKey"].(string)
-		if !ok {
-			return nil, fmt.Errorf("missing projectKey parameter")
-		}
-		files := args["files"].([]any)
-		status := args["status"].(string)
+func handleHotspots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx = cacheContext(ctx, request)
+	applyDeprecatedAliases(request, hotspotsParamAliases)
+	// extract the parameters from the request
+	args := request.GetArguments()
 
-		// call the Sonarcloud API to get the hotspots
-		duplications, err := searchHotspots(projectKey, files, status)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("unable to retrieve security hotspots.", err), nil
-		}
+	projectKey, ok := args["project_key"].(string)
+	if !ok || projectKey == "" {
+		return mcp.NewToolResultError("missing project_key parameter"), nil
+	}
+	// optional parameters fall back to their zero value when omitted
+	files, _ := args["files"].([]any)
+	status, _ := args["status"].(string)
+	fetchAll := request.GetBool("fetch_all", false)
+	maxItems := request.GetInt("max_items", defaultMaxItems)
 
-		return mcp.NewToolResultText(duplications), nil
-	})
+	// call the Sonarcloud API to get the hotspots
+	hotspots, err := searchHotspots(ctx, projectKey, files, status, fetchAll, maxItems)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("unable to retrieve security hotspots.", err), nil
+	}
+
+	return mcp.NewToolResultText(hotspots), nil
 }
 
-func searchHotspots(projectKey string, files []any, status string) (string, error) {
+func searchHotspots(ctx context.Context, projectKey string, files []any, status string, fetchAll bool, maxItems int) (string, error) {
 	filesParam := ""
 	fs := utils.InterfacesToStringsOrEmpty(files)
 
@@ -94,7 +156,20 @@ func searchHotspots(projectKey string, files []any, status string) (string, erro
 
 	url := fmt.Sprintf(SONARQUBE_URL+"api/hotspots/search?projectKey=%s%s%s", projectKey, filesParam, statusParam)
 
-	body, err := utils.MakeGetRequest(url)
+	if fetchAll {
+		// components are listed per page, so only the hotspots are combined
+		hotspots, total, truncated, err := utils.MakePaginatedGetRequest[Hotspot](ctx, url, "hotspots", maxPageSize, maxItems)
+		if err != nil {
+			return "", err
+		}
+		return utils.PrettyPrint(HotspotsResponse{
+			Paging:    Paging{PageIndex: 1, PageSize: len(hotspots), Total: total},
+			Truncated: truncated,
+			Hotspots:  hotspots,
+		})
+	}
+
+	body, err := utils.MakeGetRequest(ctx, url)
 	if err != nil {
 		return "", err
 	}
@@ -107,3 +182,61 @@ func searchHotspots(projectKey string, files []any, status string) (string, erro
 
 	return utils.PrettyPrint(response)
 }
+
+func AddHotspotShow(s *server.MCPServer) {
+	// create a new MCP tool for showing a security hotspot
+	showTool := mcp.NewTool("sonar_hotspot_show",
+		mcp.WithDescription("Get the full detail of a security hotspot: the rule's risk, vulnerability and fix recommendation sections, its location, comments and changelog. Use it to explain a security concern and how to fix it."),
+		mcp.WithString("hotspot",
+			mcp.Description("Key of the security hotspot, e.g. AU-TpxcA-iU5OvuD2FL0."),
+			mcp.Required(),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(showTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		args := request.GetArguments()
+
+		hotspot, ok := args["hotspot"].(string)
+		if !ok || hotspot == "" {
+			return mcp.NewToolResultError("missing hotspot parameter"), nil
+		}
+
+		details, err := showHotspot(ctx, hotspot)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve security hotspot.", err), nil
+		}
+
+		return mcp.NewToolResultText(details), nil
+	})
+}
+
+func showHotspot(ctx context.Context, hotspot string) (string, error) {
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/hotspots/show?hotspot="+url.QueryEscape(hotspot))
+	if err != nil {
+		return "", err
+	}
+
+	details, err := parseHotspotDetails(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(details)
+}
+
+// parseHotspotDetails decodes api/hotspots/show, turning the HTML rule
+// sections into plain text
+func parseHotspotDetails(body []byte) (HotspotDetails, error) {
+	var details HotspotDetails
+	if err := json.Unmarshal(body, &details); err != nil {
+		return details, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	details.Rule.RiskDescription = htmlToText(details.Rule.RiskDescription)
+	details.Rule.VulnerabilityDescription = htmlToText(details.Rule.VulnerabilityDescription)
+	details.Rule.FixRecommendations = htmlToText(details.Rule.FixRecommendations)
+	return details, nil
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandleHotspots_OptionalParamsOmitted(t *testing.T) {
+	var query string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"paging":{"pageIndex":1,"pageSize":100,"total":0},"hotspots":[]}`))
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "sonar_hotspots"
+	request.Params.Arguments = map[string]any{
+		"project_key": "my_project",
+	}
+
+	result, err := handleHotspots(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+	if query != "projectKey=my_project" {
+		t.Errorf("expected only the project key to be sent, got %q", query)
+	}
+}
+
+func TestHandleHotspots_FetchAll(t *testing.T) {
+	// 750 hotspots served in pages of the requested size
+	pages := 0
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("ps"))
+
+		response := HotspotsResponse{Paging: Paging{PageIndex: page, PageSize: pageSize, Total: 750}}
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= 750; i++ {
+			response.Hotspots = append(response.Hotspots, Hotspot{Key: fmt.Sprintf("hotspot-%d", i)})
+		}
+		json.NewEncoder(w).Encode(response)
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "sonar_hotspots"
+	request.Params.Arguments = map[string]any{
+		"project_key": "my_project",
+		"fetch_all":   true,
+	}
+
+	result, err := handleHotspots(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+
+	var response HotspotsResponse
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatalf("unexpected output: %v", err)
+	}
+	if len(response.Hotspots) != 750 || response.Paging.Total != 750 || response.Truncated {
+		t.Errorf("expected all 750 hotspots, got %d (total %d, truncated %v)", len(response.Hotspots), response.Paging.Total, response.Truncated)
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages to be requested, got %d", pages)
+	}
+}
+
+const hotspotShowFixture = `{
+  "key": "AX-hotspot",
+  "component": {"key": "my_project:src/db.go", "qualifier": "FIL", "name": "db.go", "path": "src/db.go"},
+  "project": {"key": "my_project", "qualifier": "TRK", "name": "My Project"},
+  "rule": {
+    "key": "go:S2077",
+    "name": "Formatting SQL queries is security-sensitive",
+    "securityCategory": "sql-injection",
+    "vulnerabilityProbability": "HIGH",
+    "riskDescription": "<p>Formatted SQL queries can be <strong>hard</strong> to maintain.</p>",
+    "vulnerabilityDescription": "<h2>Ask Yourself Whether</h2><ul><li>Parts of the query come from untrusted input</li></ul>",
+    "fixRecommendations": "<p>Use <code>db.Query(q, args...)</code> &amp; parameters.</p>"
+  },
+  "status": "REVIEWED",
+  "resolution": "SAFE",
+  "line": 42,
+  "message": "Make sure using a dynamically formatted SQL query is safe here.",
+  "author": "alice@example.com",
+  "changelog": [{"user": "bob", "userName": "Bob", "creationDate": "2024-02-01T10:00:00+0000", "diffs": [{"key": "status", "oldValue": "TO_REVIEW", "newValue": "REVIEWED"}]}],
+  "comment": [{"key": "c1", "login": "bob", "markdown": "Input is a constant"}],
+  "canChangeStatus": true
+}`
+
+func TestParseHotspotDetails(t *testing.T) {
+	details, err := parseHotspotDetails([]byte(hotspotShowFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if details.Key != "AX-hotspot" || details.Component.Path != "src/db.go" || details.Resolution != "SAFE" || details.Line != 42 {
+		t.Errorf("unexpected hotspot: %+v", details)
+	}
+	if details.Rule.RiskDescription != "Formatted SQL queries can be hard to maintain." {
+		t.Errorf("unexpected risk description: %q", details.Rule.RiskDescription)
+	}
+	if details.Rule.FixRecommendations != "Use db.Query(q, args...) & parameters." {
+		t.Errorf("unexpected fix recommendations: %q", details.Rule.FixRecommendations)
+	}
+	if len(details.Changelog) != 1 || details.Changelog[0].Diffs[0].NewValue != "REVIEWED" {
+		t.Errorf("unexpected changelog: %+v", details.Changelog)
+	}
+	if len(details.Comment) != 1 || details.Comment[0].Markdown != "Input is a constant" {
+		t.Errorf("unexpected comments: %+v", details.Comment)
+	}
+}
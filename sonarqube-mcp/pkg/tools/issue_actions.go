@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// issueTransitions are the transitions accepted by api/issues/do_transition
+var issueTransitions = []string{"confirm", "unconfirm", "reopen", "resolve", "falsepositive", "wontfix", "accept", "close"}
+
+type IssueResponse struct {
+	Issue Issue `json:"issue"`
+}
+
+// IssueAssignment is the result of sonar_issue_assign
+type IssueAssignment struct {
+	Issue    string `json:"issue"`
+	Assignee string `json:"assignee"`
+}
+
+func AddIssueTransition(s *server.MCPServer) {
+	// create a new MCP tool for changing the status of a Sonar issue
+	transitionTool := mcp.NewTool("sonar_issue_transition",
+		mcp.WithDescription("Change the status of a Sonar issue by applying a workflow transition, e.g. confirm, resolve or mark as false positive. Returns the updated issue."),
+		mcp.WithString("issue",
+			mcp.Description("Key of the issue, e.g. AU-Tpxb--iU5OvuD2FLy."),
+			mcp.Required(),
+		),
+		mcp.WithString("transition",
+			mcp.Description("The transition to apply. Possible values: "+strings.Join(issueTransitions, ", ")+"."),
+			mcp.Required(),
+			mcp.Enum(issueTransitions...),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(transitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		issue, ok := args["issue"].(string)
+		if !ok || issue == "" {
+			return mcp.NewToolResultError("missing issue parameter"), nil
+		}
+		transition, ok := args["transition"].(string)
+		if !ok || transition == "" {
+			return mcp.NewToolResultError("missing transition parameter"), nil
+		}
+
+		updated, err := transitionIssue(ctx, issue, transition)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to transition issue.", err), nil
+		}
+
+		return mcp.NewToolResultText(updated), nil
+	})
+}
+
+func transitionIssue(ctx context.Context, issue, transition string) (string, error) {
+	form := url.Values{}
+	form.Set("issue", issue)
+	form.Set("transition", transition)
+
+	body, err := utils.MakePostRequest(ctx, SONARQUBE_URL+"api/issues/do_transition", form)
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := parseIssueResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(updated)
+}
+
+// parseIssueResponse extracts the issue returned by the api/issues write endpoints
+func parseIssueResponse(body []byte) (Issue, error) {
+	var response IssueResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Issue{}, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return response.Issue, nil
+}
+
+func AddIssueComment(s *server.MCPServer) {
+	// create a new MCP tool for commenting on a Sonar issue
+	commentTool := mcp.NewTool("sonar_issue_comment",
+		mcp.WithDescription("Add a comment to a Sonar issue. Returns the created comment."),
+		mcp.WithString("issue",
+			mcp.Description("Key of the issue, e.g. AU-Tpxb--iU5OvuD2FLy."),
+			mcp.Required(),
+		),
+		mcp.WithString("text",
+			mcp.Description("The comment text, in markdown."),
+			mcp.Required(),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(commentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		issue, ok := args["issue"].(string)
+		if !ok || issue == "" {
+			return mcp.NewToolResultError("missing issue parameter"), nil
+		}
+		text, ok := args["text"].(string)
+		if !ok || strings.TrimSpace(text) == "" {
+			return mcp.NewToolResultError("missing text parameter"), nil
+		}
+
+		comment, err := commentIssue(ctx, issue, text)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to comment on issue.", err), nil
+		}
+
+		return mcp.NewToolResultText(comment), nil
+	})
+}
+
+func commentIssue(ctx context.Context, issue, text string) (string, error) {
+	form := url.Values{}
+	form.Set("issue", issue)
+	form.Set("text", text)
+
+	body, err := utils.MakePostRequest(ctx, SONARQUBE_URL+"api/issues/add_comment", form)
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := parseIssueResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	// the response contains the whole issue; comments are ordered oldest first
+	if len(updated.Comments) == 0 {
+		return "", fmt.Errorf("the response for issue %s contains no comments", issue)
+	}
+
+	return utils.PrettyPrint(updated.Comments[len(updated.Comments)-1])
+}
+
+func AddIssueAssign(s *server.MCPServer) {
+	// create a new MCP tool for assigning a Sonar issue
+	assignTool := mcp.NewTool("sonar_issue_assign",
+		mcp.WithDescription("Assign a Sonar issue to a user, or unassign it. Returns the issue's updated assignee."),
+		mcp.WithString("issue",
+			mcp.Description("Key of the issue, e.g. AU-Tpxb--iU5OvuD2FLy."),
+			mcp.Required(),
+		),
+		mcp.WithString("assignee",
+			mcp.Description("Login of the new assignee. Leave empty to unassign the issue."),
+			mcp.DefaultString(""),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(assignTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		issue, ok := args["issue"].(string)
+		if !ok || issue == "" {
+			return mcp.NewToolResultError("missing issue parameter"), nil
+		}
+		assignee, _ := args["assignee"].(string)
+
+		assignment, err := assignIssue(ctx, issue, assignee)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to assign issue.", err), nil
+		}
+
+		return mcp.NewToolResultText(assignment), nil
+	})
+}
+
+func assignIssue(ctx context.Context, issue, assignee string) (string, error) {
+	form := url.Values{}
+	form.Set("issue", issue)
+	// an absent assignee unassigns the issue
+	if assignee != "" {
+		form.Set("assignee", assignee)
+	}
+
+	body, err := utils.MakePostRequest(ctx, SONARQUBE_URL+"api/issues/assign", form)
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := parseIssueResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(IssueAssignment{Issue: updated.Key, Assignee: updated.Assignee})
+}
+
+// IssueTags is the result of setting the tags of one issue
+type IssueTags struct {
+	Issue string   `json:"issue"`
+	Tags  []string `json:"tags"`
+	Error string   `json:"error,omitempty"`
+}
+
+// BulkIssueTags is the result of sonar_issue_bulk_tags
+type BulkIssueTags struct {
+	Succeeded int         `json:"succeeded"`
+	Failed    int         `json:"failed"`
+	Issues    []IssueTags `json:"issues"`
+}
+
+type SetTagsResponse struct {
+	Tags []string `json:"tags"`
+}
+
+func AddIssueSetTags(s *server.MCPServer) {
+	// create a new MCP tool for replacing the tags of a Sonar issue
+	setTagsTool := mcp.NewTool("sonar_issue_set_tags",
+		mcp.WithDescription("Replace the tags of a Sonar issue. Returns the issue's updated tags."),
+		mcp.WithString("issue",
+			mcp.Description("Key of the issue, e.g. AU-Tpxb--iU5OvuD2FLy."),
+			mcp.Required(),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("The new tags of the issue, e.g. security, convention. An empty list removes all tags."),
+			mcp.Required(),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(setTagsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		issue, ok := args["issue"].(string)
+		if !ok || issue == "" {
+			return mcp.NewToolResultError("missing issue parameter"), nil
+		}
+		tags, ok := args["tags"].([]interface{})
+		if !ok {
+			return mcp.NewToolResultError("missing tags parameter"), nil
+		}
+
+		updated, err := setIssueTags(ctx, issue, utils.InterfacesToStringsOrEmpty(tags))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to set issue tags.", err), nil
+		}
+
+		return mcp.NewToolResultText(updated), nil
+	})
+}
+
+func AddIssueBulkTags(s *server.MCPServer) {
+	// create a new MCP tool for replacing the tags of several Sonar issues
+	bulkTagsTool := mcp.NewTool("sonar_issue_bulk_tags",
+		mcp.WithDescription("Replace the tags of several Sonar issues at once, e.g. while triaging. A failing issue does not stop the others; returns the updated tags or the error of each issue."),
+		mcp.WithArray("issues",
+			mcp.Description("Keys of the issues, e.g. AU-Tpxb--iU5OvuD2FLy."),
+			mcp.Required(),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("The new tags of the issues, e.g. security, convention. An empty list removes all tags."),
+			mcp.Required(),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(bulkTagsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		issues, _ := args["issues"].([]interface{})
+		if len(issues) == 0 {
+			return mcp.NewToolResultError("missing issues parameter"), nil
+		}
+		tags, ok := args["tags"].([]interface{})
+		if !ok {
+			return mcp.NewToolResultError("missing tags parameter"), nil
+		}
+
+		updated, err := bulkSetIssueTags(ctx, utils.InterfacesToStringsOrEmpty(issues), utils.InterfacesToStringsOrEmpty(tags))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to set issue tags.", err), nil
+		}
+
+		return mcp.NewToolResultText(updated), nil
+	})
+}
+
+func setIssueTags(ctx context.Context, issue string, tags []string) (string, error) {
+	updated, err := postIssueTags(ctx, issue, tags)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(IssueTags{Issue: issue, Tags: updated})
+}
+
+func bulkSetIssueTags(ctx context.Context, issues, tags []string) (string, error) {
+	result := BulkIssueTags{Issues: make([]IssueTags, 0, len(issues))}
+	for _, issue := range issues {
+		updated, err := postIssueTags(ctx, issue, tags)
+		if err != nil {
+			result.Failed++
+			result.Issues = append(result.Issues, IssueTags{Issue: issue, Error: err.Error()})
+			continue
+		}
+		result.Succeeded++
+		result.Issues = append(result.Issues, IssueTags{Issue: issue, Tags: updated})
+	}
+
+	return utils.PrettyPrint(result)
+}
+
+// postIssueTags replaces the tags of an issue and returns the tags reported
+// back by the server
+func postIssueTags(ctx context.Context, issue string, tags []string) ([]string, error) {
+	form := url.Values{}
+	form.Set("issue", issue)
+	// an empty value removes all tags
+	form.Set("tags", strings.Join(tags, ","))
+
+	body, err := utils.MakePostRequest(ctx, SONARQUBE_URL+"api/issues/set_tags", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var response SetTagsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	return response.Tags, nil
+}
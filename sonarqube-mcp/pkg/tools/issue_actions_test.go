@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTransitionIssue(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/issues/do_transition" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.PostForm.Get("issue") != "AX1" || r.PostForm.Get("transition") != "falsepositive" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Write([]byte(`{"issue":{"key":"AX1","rule":"go:S1135","status":"RESOLVED","resolution":"FALSE-POSITIVE"},"components":[]}`))
+	})
+
+	output, err := transitionIssue(context.Background(), "AX1", "falsepositive")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(output), &issue); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if issue.Key != "AX1" || issue.Status != "RESOLVED" || issue.Resolution != "FALSE-POSITIVE" {
+		t.Errorf("expected the updated issue, got %+v", issue)
+	}
+}
+
+func TestCommentIssue(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/issues/add_comment" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.PostForm.Get("issue") != "AX1" || r.PostForm.Get("text") != "Fixed in **#42**" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Write([]byte(`{"issue":{"key":"AX1","comments":[
+			{"key":"c1","login":"alice","markdown":"older","createdAt":"2024-01-01T10:00:00+0000"},
+			{"key":"c2","login":"bot","markdown":"Fixed in **#42**","htmlText":"Fixed in <strong>#42</strong>","createdAt":"2024-02-01T10:00:00+0000"}
+		]}}`))
+	})
+
+	output, err := commentIssue(context.Background(), "AX1", "Fixed in **#42**")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var comment Comment
+	if err := json.Unmarshal([]byte(output), &comment); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if comment.Key != "c2" || comment.Login != "bot" || comment.CreatedAt != "2024-02-01T10:00:00+0000" {
+		t.Errorf("expected the created comment, got %+v", comment)
+	}
+}
+
+func TestAssignIssue(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.URL.Path != "/api/issues/assign" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if assignee, ok := r.PostForm["assignee"]; ok {
+			w.Write([]byte(`{"issue":{"key":"AX1","assignee":"` + assignee[0] + `"}}`))
+			return
+		}
+		w.Write([]byte(`{"issue":{"key":"AX1"}}`))
+	})
+
+	for _, assignee := range []string{"alice", ""} {
+		output, err := assignIssue(context.Background(), "AX1", assignee)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var assignment IssueAssignment
+		if err := json.Unmarshal([]byte(output), &assignment); err != nil {
+			t.Fatalf("unexpected output %s: %v", output, err)
+		}
+		if assignment != (IssueAssignment{Issue: "AX1", Assignee: assignee}) {
+			t.Errorf("expected assignee %q, got %+v", assignee, assignment)
+		}
+	}
+}
+
+func TestSetIssueTags(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/issues/set_tags" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.PostForm.Get("issue") != "AX1" || r.PostForm.Get("tags") != "security,triaged" {
+			t.Errorf("unexpected form %v", r.PostForm)
+		}
+		w.Write([]byte(`{"tags":["security","triaged"]}`))
+	})
+
+	output, err := setIssueTags(context.Background(), "AX1", []string{"security", "triaged"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var tags IssueTags
+	if err := json.Unmarshal([]byte(output), &tags); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if tags.Issue != "AX1" || len(tags.Tags) != 2 || tags.Tags[0] != "security" || tags.Tags[1] != "triaged" {
+		t.Errorf("expected the updated tags, got %+v", tags)
+	}
+}
+
+func TestBulkSetIssueTags(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("tags") != "" {
+			t.Errorf("expected empty tags, got %v", r.PostForm)
+		}
+		if r.PostForm.Get("issue") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"msg":"Issue with key 'missing' does not exist"}]}`))
+			return
+		}
+		w.Write([]byte(`{"tags":[]}`))
+	})
+
+	output, err := bulkSetIssueTags(context.Background(), []string{"AX1", "missing", "AX2"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result BulkIssueTags
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if result.Succeeded != 2 || result.Failed != 1 || len(result.Issues) != 3 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Issues[1].Issue != "missing" || result.Issues[1].Error == "" {
+		t.Errorf("expected an error for the missing issue, got %+v", result.Issues[1])
+	}
+	if result.Issues[2].Issue != "AX2" || result.Issues[2].Tags == nil || len(result.Issues[2].Tags) != 0 {
+		t.Errorf("expected cleared tags for AX2, got %+v", result.Issues[2])
+	}
+}
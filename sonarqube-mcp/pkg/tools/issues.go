@@ -4,11 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/intelops/sonarqube-mcp/pkg/utils"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	log "github.com/sirupsen/logrus"
 )
 
 type Rule struct {
@@ -59,6 +65,7 @@ type Issue struct {
 	Line                       int               `json:"line"`
 	Hash                       string            `json:"hash"`
 	Author                     string            `json:"author"`
+	Assignee                   string            `json:"assignee,omitempty"`
 	Effort                     string            `json:"effort"`
 	CreationDate               string            `json:"creationDate"`
 	UpdateDate                 string            `json:"updateDate"`
@@ -76,19 +83,60 @@ type Issue struct {
 	Impacts                    []Impact          `json:"impacts"`
 }
 
+type FacetValue struct {
+	Val   string `json:"val"`
+	Count int    `json:"count"`
+}
+type Facet struct {
+	Property string       `json:"property"`
+	Values   []FacetValue `json:"values"`
+}
+
+// IssueWithGuidance is an issue together with the fix guidance of its rule
+type IssueWithGuidance struct {
+	Issue
+	RuleGuidance string `json:"ruleGuidance,omitempty"`
+}
+
+// defaultMaxRuleLookups bounds the rule lookups made for include_rule_guidance
+const defaultMaxRuleLookups = 20
+
 type IssuesResponse struct {
 	Paging     Paging      `json:"paging"`
 	Issues     []Issue     `json:"issues,omitempty"`
 	Components []Component `json:"components,omitempty"`
 	Rules      []Rule      `json:"rules,omitempty"`
 	Users      []User      `json:"users,omitempty"`
+	Facets     []Facet     `json:"facets,omitempty"`
+}
+
+type RuleIssueCount struct {
+	Rule  string `json:"rule"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// issuesParamAliases maps the deprecated camelCase parameters of sonar_issues
+// to their current names
+var issuesParamAliases = map[string]string{
+	"projectKey":       "project_key",
+	"impactSeverities": "impact_severities",
+	"issueStatus":      "issue_status",
+	"createdAfter":     "created_after",
+	"createdBefore":    "created_before",
+	"createdInLast":    "created_in_last",
+	"pageSize":         "page_size",
+	"fetchAll":         "fetch_all",
+	"maxIssues":        "max_items",
+	"countOnly":        "count_only",
+	"outputFile":       "output_file",
 }
 
 func AddIssues(s *server.MCPServer) {
 	// create a new MCP tool for searching Sonar issues
 	issuesTool := mcp.NewTool("sonar_issues",
 		mcp.WithDescription("Search and get all issues for a specified Sonar project."),
-		mcp.WithString("projectKey",
+		mcp.WithString("project_key",
 			mcp.Description("Key of the project or application, e.g. my_project."),
 			mcp.DefaultString(""),
 			mcp.Required(),
@@ -101,12 +149,12 @@ func AddIssues(s *server.MCPServer) {
 			mcp.Description("The SCM branch key or name (optional), e.g. feature/my_branch"),
 			mcp.DefaultString("main"),
 		),
-		mcp.WithArray("impactSeverities",
+		mcp.WithArray("impact_severities",
 			mcp.Description("The severity of the issues to be retrieved. Possible values: BLOCKER, HIGH, MEDIUM, LOW, INFO."),
 			mcp.DefaultArray([]string{"BLOCKER", "HIGH"}),
 			mcp.Enum("BLOCKER", "HIGH", "MEDIUM", "LOW", "INFO"),
 		),
-		mcp.WithArray("issueStatus",
+		mcp.WithArray("issue_status",
 			mcp.Description("The status of the issues to be retrieved. Possible values: OPEN, CONFIRMED, FALSE_POSITIVE, ACCEPTED, FIXED."),
 			mcp.DefaultArray([]string{"OPEN"}),
 			mcp.Enum("OPEN", "CONFIRMED", "FALSE_POSITIVE", "ACCEPTED", "FIXED"),
@@ -116,75 +164,533 @@ func AddIssues(s *server.MCPServer) {
 			mcp.DefaultString(""),
 			mcp.Enum("true", "false", "yes", "no"),
 		),
+		mcp.WithArray("types",
+			mcp.Description("The types of the issues to be retrieved. Possible values: CODE_SMELL, BUG, VULNERABILITY. This parameter is optional."),
+			mcp.Enum("CODE_SMELL", "BUG", "VULNERABILITY"),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("Only return issues with one of these tags, e.g. security, convention. This parameter is optional."),
+		),
+		mcp.WithArray("authors",
+			mcp.Description("Only return issues introduced by one of these SCM authors (as recorded in SCM, e.g. an email address). This parameter is optional."),
+		),
+		mcp.WithArray("rules",
+			mcp.Description("Only return issues raised by one of these rules, e.g. go:S1135. This parameter is optional."),
+		),
+		mcp.WithString("created_after",
+			mcp.Description("Only return issues created on or after this date, e.g. 2024-01-31 or 2024-01-31T13:00:00+0100. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("created_before",
+			mcp.Description("Only return issues created before this date, e.g. 2024-02-14. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("created_in_last",
+			mcp.Description("Only return issues created during a time span before the current time, e.g. 30d, 2w, 1m2w (y = years, m = months, w = weeks, d = days). Cannot be combined with created_after. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithNumber("page",
+			mcp.Description("1-based page number to retrieve."),
+			mcp.DefaultNumber(1),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("Number of issues per page (max 500). Defaults to the server's page size."),
+		),
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch all pages of results, up to max_items issues."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of issues to collect when fetch_all is set."),
+			mcp.DefaultNumber(defaultMaxItems),
+		),
+		mcp.WithBoolean("include_rule_guidance",
+			mcp.Description("Attach a concise description of how to fix each issue, fetched once per distinct rule."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("max_rule_lookups",
+			mcp.Description("Maximum number of distinct rules to fetch guidance for when include_rule_guidance is set."),
+			mcp.DefaultNumber(defaultMaxRuleLookups),
+		),
+		mcp.WithArray("facets",
+			mcp.Description("Facets to compute over all matching issues, returned as value counts, e.g. severities, types, rules, tags, impactSeverities, impactSoftwareQualities, directories, files, author. This parameter is optional."),
+		),
+		mcp.WithBoolean("count_only",
+			mcp.Description("Return only the total number of matching issues and the requested facets, without listing the issues."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format. csv flattens each issue into a spreadsheet row and requires output_file."),
+			mcp.DefaultString("json"),
+			mcp.Enum("json", "csv"),
+		),
+		mcp.WithString("output_file",
+			mcp.Description("Path of a file to write the issues to instead of returning them. Required when format is csv."),
+			mcp.DefaultString(""),
+		),
+		noCacheOption(),
 	)
 
 	// add the tool to the server
 	s.AddTool(issuesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, issuesParamAliases)
 		// extract the parameters from the request
 		args := request.GetArguments()
 
-		projectKey := args["projectKey"].(string)
-		organization := args["organization"].(string)
-		branch := args["branch"].(string)
-		issueStatus := args["issueStatus"].([]interface{})
-		impactSeverities := args["impactSeverities"].([]interface{})
-		resolved := args["resolved"].(string)
+		projectKey, ok := args["project_key"].(string)
+		if !ok || projectKey == "" {
+			return mcp.NewToolResultError("missing project_key parameter"), nil
+		}
+		organization := request.GetString("organization", "")
+		branch := request.GetString("branch", "main")
+		issueStatus, _ := args["issue_status"].([]interface{})
+		impactSeverities, _ := args["impact_severities"].([]interface{})
+		resolved := request.GetString("resolved", "")
+		// optional filters are empty when omitted
+		types, _ := args["types"].([]interface{})
+		tags, _ := args["tags"].([]interface{})
+		authors, _ := args["authors"].([]interface{})
+		rules, _ := args["rules"].([]interface{})
+		facets, _ := args["facets"].([]interface{})
+
+		opts := IssueSearchOptions{
+			Organization:     organization,
+			ProjectKey:       projectKey,
+			Branch:           branch,
+			IssueStatus:      utils.InterfacesToStringsOrEmpty(issueStatus),
+			Resolved:         resolved,
+			ImpactSeverities: utils.InterfacesToStringsOrEmpty(impactSeverities),
+			Types:            utils.InterfacesToStringsOrEmpty(types),
+			Tags:             utils.InterfacesToStringsOrEmpty(tags),
+			Authors:          utils.InterfacesToStringsOrEmpty(authors),
+			Rules:            utils.InterfacesToStringsOrEmpty(rules),
+			CreatedAfter:     request.GetString("created_after", ""),
+			CreatedBefore:    request.GetString("created_before", ""),
+			CreatedInLast:    request.GetString("created_in_last", ""),
+			Facets:           utils.InterfacesToStringsOrEmpty(facets),
+			Page:             request.GetInt("page", 1),
+			PageSize:         request.GetInt("page_size", 0),
+			FetchAll:         request.GetBool("fetch_all", false),
+			MaxItems:         request.GetInt("max_items", defaultMaxItems),
+			IncludeGuidance:  request.GetBool("include_rule_guidance", false),
+			MaxRuleLookups:   request.GetInt("max_rule_lookups", defaultMaxRuleLookups),
+		}
+
+		if err := validateCreationDates(opts); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if request.GetBool("count_only", false) {
+			counts, err := countIssues(ctx, opts)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("unable to count issues.", err), nil
+			}
+			return mcp.NewToolResultText(counts), nil
+		}
+
+		format := request.GetString("format", "json")
+		outputFile := request.GetString("output_file", "")
+		if format == "csv" {
+			if outputFile == "" {
+				return mcp.NewToolResultError("output_file is required when format is csv"), nil
+			}
+			written, err := exportIssuesCSV(ctx, opts, outputFile)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("unable to export issues.", err), nil
+			}
+			return mcp.NewToolResultText(written), nil
+		}
 
 		// call the Sonarcloud API to get the issues
-		issues, err := searchIssues(organization, projectKey, branch, issueStatus, resolved, impactSeverities)
+		issues, err := searchIssues(ctx, opts)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("unable to retrieve issues.", err), nil
 		}
+		if outputFile != "" {
+			if err := os.WriteFile(outputFile, []byte(issues), 0o644); err != nil {
+				return mcp.NewToolResultErrorFromErr("unable to write issues.", fmt.Errorf("failed to write JSON to %s: %w", outputFile, err)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Written Issues output to: %s", outputFile)), nil
+		}
 
 		return mcp.NewToolResultText(issues), nil
 	})
 }
 
-func searchIssues(organization string, projectKey string, branch string, issueStatus []interface{}, resolved string, impactSeverities []interface{}) (string, error) {
-	organizationParam := ""
-	if organization != "" {
-		organizationParam = fmt.Sprintf("&organization=%s", organization)
+// IssueSearchOptions holds the parameters of a sonar_issues search
+type IssueSearchOptions struct {
+	Organization     string
+	ProjectKey       string
+	Branch           string
+	IssueStatus      []string
+	Resolved         string
+	ImpactSeverities []string
+	Types            []string
+	Tags             []string
+	Authors          []string
+	Rules            []string
+	CreatedAfter     string
+	CreatedBefore    string
+	CreatedInLast    string
+	Facets           []string
+	Page             int
+	PageSize         int
+	// FetchAll follows the paging information until MaxItems issues are collected
+	FetchAll        bool
+	MaxItems        int
+	IncludeGuidance bool
+	MaxRuleLookups  int
+}
+
+// IssuesPage is the sonar_issues output: the issues together with the paging
+// information needed to request further pages
+type IssuesPage struct {
+	Paging    Paging  `json:"paging"`
+	HasMore   bool    `json:"hasMore"`
+	Truncated bool    `json:"truncated,omitempty"`
+	Facets    []Facet `json:"facets,omitempty"`
+	Issues    any     `json:"issues"`
+}
+
+// IssueCounts is the sonar_issues output with count_only: the number of
+// matching issues and the requested facets, without the issues themselves
+type IssueCounts struct {
+	Total  int     `json:"total"`
+	Facets []Facet `json:"facets,omitempty"`
+}
+
+// createdInLastPattern matches the duration shorthand of created_in_last, e.g. 30d or 1m2w
+var createdInLastPattern = regexp.MustCompile(`^(\d+[ymwd])+$`)
+
+// validateCreationDates checks the creation date filters before they are sent
+func validateCreationDates(opts IssueSearchOptions) error {
+	if err := validateSonarDate(opts.CreatedAfter); err != nil {
+		return fmt.Errorf("created_after: %w", err)
 	}
-	branchParam := ""
-	if branch != "" {
-		branchParam = fmt.Sprintf("&branch=%s", branch)
+	if err := validateSonarDate(opts.CreatedBefore); err != nil {
+		return fmt.Errorf("created_before: %w", err)
+	}
+	if opts.CreatedInLast != "" {
+		if !createdInLastPattern.MatchString(opts.CreatedInLast) {
+			return fmt.Errorf("created_in_last: invalid duration %q: expected e.g. 30d, 2w or 1m2w", opts.CreatedInLast)
+		}
+		if opts.CreatedAfter != "" {
+			return fmt.Errorf("created_in_last cannot be combined with created_after")
+		}
+	}
+	return nil
+}
+
+// issuesSearchURL builds the api/issues/search URL for one page of results
+func issuesSearchURL(opts IssueSearchOptions, page, pageSize int) string {
+	params := url.Values{}
+	params.Set("projectKey", opts.ProjectKey)
+	if opts.Organization != "" {
+		params.Set("organization", opts.Organization)
+	}
+	if opts.Branch != "" {
+		params.Set("branch", opts.Branch)
+	}
+	if len(opts.IssueStatus) > 0 {
+		params.Set("issueStatuses", strings.Join(opts.IssueStatus, ","))
+	}
+	if opts.Resolved != "" {
+		params.Set("resolved", opts.Resolved)
+	}
+	if len(opts.ImpactSeverities) > 0 {
+		params.Set("impactSeverities", strings.Join(opts.ImpactSeverities, ","))
+	}
+	if len(opts.Types) > 0 {
+		params.Set("types", strings.Join(opts.Types, ","))
+	}
+	if len(opts.Tags) > 0 {
+		params.Set("tags", strings.Join(opts.Tags, ","))
 	}
-	issueStatusParam := ""
-	if len(issueStatus) > 0 {
-		is := utils.InterfacesToStringsOrEmpty(issueStatus)
-		// join the issue statuses with commas
-		issueStatusParam = fmt.Sprintf("&issueStatuses=%s", strings.Join(is, ","))
+	if len(opts.Authors) > 0 {
+		// author is repeated once per value rather than comma-separated,
+		// since SCM accounts may themselves contain commas
+		params["author"] = opts.Authors
 	}
-	resolvedParam := ""
-	if resolved != "" {
-		resolvedParam = fmt.Sprintf("&resolved=%s", resolved)
+	if len(opts.Rules) > 0 {
+		params.Set("rules", strings.Join(opts.Rules, ","))
 	}
-	impactSeveritiesParam := ""
-	if len(impactSeverities) > 0 {
-		imps := utils.InterfacesToStringsOrEmpty(impactSeverities)
-		// join the impact severities with commas
-		impactSeveritiesParam = fmt.Sprintf("&impactSeverities=%s", strings.Join(imps, ","))
+	if opts.CreatedAfter != "" {
+		params.Set("createdAfter", opts.CreatedAfter)
 	}
+	if opts.CreatedBefore != "" {
+		params.Set("createdBefore", opts.CreatedBefore)
+	}
+	if opts.CreatedInLast != "" {
+		params.Set("createdInLast", opts.CreatedInLast)
+	}
+	if len(opts.Facets) > 0 {
+		params.Set("facets", strings.Join(opts.Facets, ","))
+	}
+	if page > 0 {
+		params.Set("p", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		params.Set("ps", strconv.Itoa(pageSize))
+	}
+	return SONARQUBE_URL + "api/issues/search?" + params.Encode()
+}
 
-	// construct the URL for the Sonarcloud API
-	url := fmt.Sprintf(SONARQUBE_URL+"api/issues/search?projectKey=%s%s%s%s%s%s",
-		projectKey, organizationParam, branchParam, issueStatusParam, resolvedParam, impactSeveritiesParam)
+func fetchIssuesPage(ctx context.Context, opts IssueSearchOptions, page, pageSize int) (IssuesResponse, error) {
+	var response IssuesResponse
 
-	body, err := utils.MakeGetRequest(url)
+	body, err := utils.MakeGetRequest(ctx, issuesSearchURL(opts, page, pageSize))
 	if err != nil {
-		return "", err
+		return response, err
 	}
 
-	var response IssuesResponse
-	err = json.Unmarshal(body, &response)
+	if err := json.Unmarshal(body, &response); err != nil {
+		return response, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return response, nil
+}
+
+// findIssues retrieves the issues matching opts, following the paging when
+// FetchAll is set
+func findIssues(ctx context.Context, opts IssueSearchOptions) (IssuesPage, []Issue, error) {
+	var result IssuesPage
+	var issues []Issue
+
+	if opts.FetchAll {
+		pageSize := opts.PageSize
+		if pageSize <= 0 {
+			pageSize = maxPageSize
+		}
+		maxItems := opts.MaxItems
+		if maxItems <= 0 {
+			maxItems = defaultMaxItems
+		}
+
+		// facets cover all matching issues, so they are fetched once rather than with every page
+		pageOpts := opts
+		pageOpts.Facets = nil
+		all, total, truncated, err := utils.MakePaginatedGetRequest[Issue](ctx, issuesSearchURL(pageOpts, 0, 0), "issues", pageSize, maxItems)
+		if err != nil {
+			return result, nil, err
+		}
+		if len(opts.Facets) > 0 {
+			response, err := fetchIssuesPage(ctx, opts, 1, 1)
+			if err != nil {
+				return result, nil, err
+			}
+			result.Facets = response.Facets
+		}
+		issues = all
+		result.Paging = Paging{PageIndex: 1, PageSize: len(all), Total: total}
+		result.Truncated = truncated
+		result.HasMore = truncated
+	} else {
+		response, err := fetchIssuesPage(ctx, opts, opts.Page, opts.PageSize)
+		if err != nil {
+			return result, nil, err
+		}
+		issues = response.Issues
+		result.Paging = response.Paging
+		result.HasMore = response.Paging.PageIndex*response.Paging.PageSize < response.Paging.Total
+		result.Facets = response.Facets
+	}
+
+	return result, issues, nil
+}
+
+func searchIssues(ctx context.Context, opts IssueSearchOptions) (string, error) {
+	result, issues, err := findIssues(ctx, opts)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+		return "", err
 	}
 
 	// check if the response contains issues
-	if len(response.Issues) == 0 {
+	if len(issues) == 0 && len(result.Facets) == 0 {
+		return "No issues found.", nil
+	}
+
+	if opts.IncludeGuidance {
+		fetch := func(rule string) (RuleDetails, error) {
+			return fetchRule(ctx, rule, opts.Organization)
+		}
+		result.Issues = attachRuleGuidance(issues, fetch, opts.MaxRuleLookups)
+	} else {
+		result.Issues = issues
+	}
+
+	return utils.PrettyPrint(result)
+}
+
+// countIssues returns the number of issues matching opts together with the
+// requested facets, fetching a single issue to keep the response small
+func countIssues(ctx context.Context, opts IssueSearchOptions) (string, error) {
+	response, err := fetchIssuesPage(ctx, opts, 1, 1)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(IssueCounts{Total: response.Paging.Total, Facets: response.Facets})
+}
+
+// attachRuleGuidance attaches the fix guidance of each issue's rule. Every
+// distinct rule is fetched at most once and at most maxLookups rules are
+// fetched; issues of further rules, or of rules that fail to load, are
+// returned without guidance.
+func attachRuleGuidance(issues []Issue, fetch func(rule string) (RuleDetails, error), maxLookups int) []IssueWithGuidance {
+	guidance := make(map[string]string)
+	lookups := 0
+
+	result := make([]IssueWithGuidance, 0, len(issues))
+	for _, issue := range issues {
+		text, cached := guidance[issue.Rule]
+		if !cached && lookups < maxLookups {
+			lookups++
+			rule, err := fetch(issue.Rule)
+			if err != nil {
+				log.Warnf("unable to fetch guidance for rule %s: %v", issue.Rule, err)
+			} else {
+				text = ruleGuidance(rule)
+			}
+			guidance[issue.Rule] = text
+		}
+		result = append(result, IssueWithGuidance{Issue: issue, RuleGuidance: text})
+	}
+	return result
+}
+
+// issuesByRuleParamAliases maps the deprecated camelCase parameters of
+// sonar_issues_by_rule to their current names
+var issuesByRuleParamAliases = map[string]string{
+	"projectKey": "project_key",
+}
+
+func AddIssuesByRule(s *server.MCPServer) {
+	// create a new MCP tool for counting Sonar issues per rule
+	issuesByRuleTool := mcp.NewTool("sonar_issues_by_rule",
+		mcp.WithDescription("Count the issues of a Sonar project per rule, sorted by count descending, to decide which rules to address first."),
+		mcp.WithString("project_key",
+			mcp.Description("Key of the project or application, e.g. my_project."),
+			mcp.Required(),
+		),
+		mcp.WithString("organization",
+			mcp.Description("The Sonar cloud organization key or name (optional), e.g. my_organization."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("branch",
+			mcp.Description("The SCM branch key or name (optional), e.g. feature/my_branch"),
+			mcp.DefaultString(""),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(issuesByRuleTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, issuesByRuleParamAliases)
+		args := request.GetArguments()
+
+		projectKey, ok := args["project_key"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing project_key parameter")
+		}
+		organization, _ := args["organization"].(string)
+		branch, _ := args["branch"].(string)
+
+		// call the Sonarcloud API to get the rules facet
+		counts, err := countIssuesByRule(ctx, organization, projectKey, branch)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve issue counts by rule.", err), nil
+		}
+
+		return mcp.NewToolResultText(counts), nil
+	})
+}
+
+func countIssuesByRule(ctx context.Context, organization, projectKey, branch string) (string, error) {
+	params := url.Values{}
+	params.Set("projectKey", projectKey)
+	params.Set("facets", "rules")
+	// names of the rules of the returned issue; the other rules of the facet
+	// are looked up separately
+	params.Set("additionalFields", "rules")
+	// only the facet is needed, not the issues themselves
+	params.Set("ps", "1")
+	if organization != "" {
+		params.Set("organization", organization)
+	}
+	if branch != "" {
+		params.Set("branch", branch)
+	}
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/issues/search?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	counts, err := parseIssuesByRule(body)
+	if err != nil {
+		return "", err
+	}
+	resolveRuleNames(counts, func(rule string) (RuleDetails, error) {
+		return fetchRule(ctx, rule, organization)
+	}, defaultMaxRuleLookups)
+
+	if len(counts) == 0 {
 		return "No issues found.", nil
 	}
 
-	return utils.PrettyPrint(response.Issues)
+	return utils.PrettyPrint(counts)
+}
+
+// resolveRuleNames fills in the names missing from counts. At most
+// maxLookups rules are fetched; rules beyond that, or that fail to load, keep
+// an empty name.
+func resolveRuleNames(counts []RuleIssueCount, fetch func(rule string) (RuleDetails, error), maxLookups int) {
+	lookups := 0
+	for i := range counts {
+		if counts[i].Name != "" || lookups >= maxLookups {
+			continue
+		}
+		lookups++
+		rule, err := fetch(counts[i].Rule)
+		if err != nil {
+			log.Warnf("unable to fetch the name of rule %s: %v", counts[i].Rule, err)
+			continue
+		}
+		counts[i].Name = rule.Name
+	}
+}
+
+// parseIssuesByRule extracts the rules facet from an issues search response,
+// resolves rule names from the response's rules array and sorts by count
+func parseIssuesByRule(body []byte) ([]RuleIssueCount, error) {
+	var response IssuesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	names := make(map[string]string, len(response.Rules))
+	for _, rule := range response.Rules {
+		names[rule.Key] = rule.Name
+	}
+
+	var counts []RuleIssueCount
+	for _, facet := range response.Facets {
+		if facet.Property != "rules" {
+			continue
+		}
+		for _, value := range facet.Values {
+			counts = append(counts, RuleIssueCount{
+				Rule:  value.Val,
+				Name:  names[value.Val],
+				Count: value.Count,
+			})
+		}
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+
+	return counts, nil
 }
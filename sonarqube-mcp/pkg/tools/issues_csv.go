@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// issueCSVHeader lists the columns written by exportIssuesCSV
+var issueCSVHeader = []string{"key", "rule", "severity", "file", "line", "message", "status", "author", "creationDate"}
+
+// exportIssuesCSV writes the issues matching opts to outputFile as CSV, one row per issue
+func exportIssuesCSV(ctx context.Context, opts IssueSearchOptions, outputFile string) (string, error) {
+	result, issues, err := findIssues(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeIssuesCSV(outputFile, issues); err != nil {
+		return "", err
+	}
+
+	written := fmt.Sprintf("Written %d issue rows to: %s", len(issues), outputFile)
+	if result.HasMore {
+		written += fmt.Sprintf(" (%d issues in total, use fetch_all to export all of them)", result.Paging.Total)
+	}
+	return written, nil
+}
+
+func writeIssuesCSV(outputFile string, issues []Issue) error {
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputFile, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write(issueCSVHeader)
+	for _, issue := range issues {
+		writer.Write(issueCSVRow(issue))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV to %s: %w", outputFile, err)
+	}
+	return file.Close()
+}
+
+// issueCSVRow flattens an issue into the issueCSVHeader columns
+func issueCSVRow(issue Issue) []string {
+	// components are keyed project:path, the file is the path part
+	file := issue.Component
+	if _, path, ok := strings.Cut(issue.Component, ":"); ok {
+		file = path
+	}
+
+	// issues analyzed under the clean code taxonomy only carry impact severities
+	severity := issue.Severity
+	if severity == "" {
+		var severities []string
+		for _, impact := range issue.Impacts {
+			severities = append(severities, impact.Severity)
+		}
+		severity = strings.Join(severities, "|")
+	}
+
+	status := issue.IssueStatus
+	if status == "" {
+		status = issue.Status
+	}
+
+	line := ""
+	if issue.Line > 0 {
+		line = strconv.Itoa(issue.Line)
+	}
+
+	return []string{issue.Key, issue.Rule, severity, file, line, issue.Message, status, issue.Author, issue.CreationDate}
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportIssuesCSV(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(IssuesResponse{
+			Paging: Paging{PageIndex: 1, PageSize: 100, Total: 2},
+			Issues: []Issue{
+				{Key: "AX1", Rule: "go:S1135", Severity: "INFO", Component: "my_project:src/main.go", Line: 12, Message: "Complete the task, \"TODO\"", IssueStatus: "OPEN", Author: "alice@example.com", CreationDate: "2024-01-02T10:00:00+0000"},
+				{Key: "AX2", Rule: "go:S3776", Component: "my_project", Impacts: []Impact{{SoftwareQuality: "MAINTAINABILITY", Severity: "HIGH"}}, Status: "CONFIRMED"},
+			},
+		})
+	})
+
+	outputFile := filepath.Join(t.TempDir(), "issues.csv")
+	written, err := exportIssuesCSV(context.Background(), IssueSearchOptions{ProjectKey: "my_project"}, outputFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(written, "Written 2 issue rows to: "+outputFile) {
+		t.Errorf("unexpected result %q", written)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("failed to open CSV: %v", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+
+	want := [][]string{
+		issueCSVHeader,
+		{"AX1", "go:S1135", "INFO", "src/main.go", "12", "Complete the task, \"TODO\"", "OPEN", "alice@example.com", "2024-01-02T10:00:00+0000"},
+		{"AX2", "go:S3776", "HIGH", "my_project", "", "", "CONFIRMED", "", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %v", len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "\x00") != strings.Join(want[i], "\x00") {
+			t.Errorf("row %d: expected %q, got %q", i, want[i], rows[i])
+		}
+	}
+}
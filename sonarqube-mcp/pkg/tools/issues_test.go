@@ -0,0 +1,333 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// issuesRuleFacetFixture is a response to facets=rules&additionalFields=rules&ps=1:
+// the rules array only covers the rule of the single returned issue
+const issuesRuleFacetFixture = `{
+  "total": 42,
+  "p": 1,
+  "ps": 1,
+  "paging": {"pageIndex": 1, "pageSize": 1, "total": 42},
+  "issues": [
+    {"key": "AX1", "rule": "go:S3776", "severity": "CRITICAL", "component": "my_project:main.go", "project": "my_project", "status": "OPEN", "type": "CODE_SMELL"}
+  ],
+  "rules": [
+    {"key": "go:S3776", "name": "Cognitive Complexity of functions should not be too high", "status": "READY", "lang": "go", "langName": "Go"}
+  ],
+  "facets": [
+    {
+      "property": "rules",
+      "values": [
+        {"val": "go:S1192", "count": 7},
+        {"val": "go:S3776", "count": 30},
+        {"val": "go:S1135", "count": 5}
+      ]
+    }
+  ]
+}`
+
+func TestParseIssuesByRule(t *testing.T) {
+	counts, err := parseIssuesByRule([]byte(issuesRuleFacetFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []RuleIssueCount{
+		{Rule: "go:S3776", Name: "Cognitive Complexity of functions should not be too high", Count: 30},
+		{Rule: "go:S1192", Name: "", Count: 7},
+		{Rule: "go:S1135", Name: "", Count: 5},
+	}
+	if len(counts) != len(want) {
+		t.Fatalf("expected %d rules, got %d: %+v", len(want), len(counts), counts)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want[i], counts[i])
+		}
+	}
+}
+
+func TestCountIssuesByRule(t *testing.T) {
+	var lookups []string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/issues/search":
+			if r.URL.Query().Get("facets") != "rules" || r.URL.Query().Get("additionalFields") != "rules" {
+				t.Errorf("unexpected query %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(issuesRuleFacetFixture))
+		case "/api/rules/show":
+			key := r.URL.Query().Get("key")
+			lookups = append(lookups, key)
+			if key == "go:S1135" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"msg":"Rule not found"}]}`))
+				return
+			}
+			w.Write([]byte(`{"rule":{"key":"go:S1192","name":"String literals should not be duplicated"}}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	output, err := countIssuesByRule(context.Background(), "", "my_project", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var counts []RuleIssueCount
+	if err := json.Unmarshal([]byte(output), &counts); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+
+	want := []RuleIssueCount{
+		{Rule: "go:S3776", Name: "Cognitive Complexity of functions should not be too high", Count: 30},
+		{Rule: "go:S1192", Name: "String literals should not be duplicated", Count: 7},
+		{Rule: "go:S1135", Name: "", Count: 5},
+	}
+	for i := range want {
+		if i >= len(counts) || counts[i] != want[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want[i], counts)
+		}
+	}
+	// the rule named in the search response is not looked up again
+	if strings.Join(lookups, ",") != "go:S1192,go:S1135" {
+		t.Errorf("unexpected rule lookups %v", lookups)
+	}
+}
+
+func TestAttachRuleGuidance(t *testing.T) {
+	issues := []Issue{
+		{Key: "AX1", Rule: "go:S3776"},
+		{Key: "AX2", Rule: "go:S1135"},
+		{Key: "AX3", Rule: "go:S3776"},
+		{Key: "AX4", Rule: "go:S1192"},
+	}
+
+	lookups := map[string]int{}
+	fetch := func(rule string) (RuleDetails, error) {
+		lookups[rule]++
+		return RuleDetails{Key: rule, GapDescription: "fix " + rule}, nil
+	}
+
+	result := attachRuleGuidance(issues, fetch, 2)
+	if len(result) != len(issues) {
+		t.Fatalf("expected %d issues, got %d", len(issues), len(result))
+	}
+
+	want := []string{"fix go:S3776", "fix go:S1135", "fix go:S3776", ""}
+	for i, issue := range result {
+		if issue.Key != issues[i].Key {
+			t.Errorf("issue %d: expected key %s, got %s", i, issues[i].Key, issue.Key)
+		}
+		if issue.RuleGuidance != want[i] {
+			t.Errorf("issue %d: expected guidance %q, got %q", i, want[i], issue.RuleGuidance)
+		}
+	}
+
+	if lookups["go:S3776"] != 1 {
+		t.Errorf("expected go:S3776 to be fetched once, got %d", lookups["go:S3776"])
+	}
+	if lookups["go:S1192"] != 0 {
+		t.Errorf("expected go:S1192 to be skipped past the lookup limit, got %d", lookups["go:S1192"])
+	}
+}
+
+func TestRuleGuidance(t *testing.T) {
+	rule := RuleDetails{
+		Key:            "go:S1135",
+		GapDescription: "fallback",
+		DescriptionSections: []RuleDescriptionSection{
+			{Key: "root_cause", Content: "<p>Why this is an issue</p>"},
+			{Key: "how_to_fix", Content: "<p>Resolve the <code>TODO</code> &amp; remove it.</p>"},
+		},
+	}
+	if got := ruleGuidance(rule); got != "Resolve the TODO & remove it." {
+		t.Errorf("unexpected guidance: %q", got)
+	}
+
+	rule.DescriptionSections = nil
+	if got := ruleGuidance(rule); got != "fallback" {
+		t.Errorf("expected gap description fallback, got %q", got)
+	}
+}
+
+func TestTruncateGuidance(t *testing.T) {
+	// "é" is two bytes, the second of which falls on the cut
+	text := strings.Repeat("a", maxGuidanceLength-1) + "é remains"
+	got := truncateGuidance(text)
+	if !utf8.ValidString(got) {
+		t.Fatalf("expected valid UTF-8, got %q", got[len(got)-10:])
+	}
+	if got != strings.Repeat("a", maxGuidanceLength-1)+"..." {
+		t.Errorf("expected the cut before the split rune, got %q", got[len(got)-10:])
+	}
+
+	short := "Évitez les caractères non ASCII"
+	if got := truncateGuidance(short); got != short {
+		t.Errorf("expected short text unchanged, got %q", got)
+	}
+}
+
+func TestSearchIssues_FetchAll(t *testing.T) {
+	// five issues served two per page
+	var requestedPages []string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("ps"))
+		requestedPages = append(requestedPages, r.URL.Query().Get("p"))
+
+		response := IssuesResponse{Paging: Paging{PageIndex: page, PageSize: pageSize, Total: 5}}
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= 5; i++ {
+			response.Issues = append(response.Issues, Issue{Key: fmt.Sprintf("issue-%d", i)})
+		}
+		json.NewEncoder(w).Encode(response)
+	})
+
+	output, err := searchIssues(context.Background(), IssueSearchOptions{ProjectKey: "my_project", PageSize: 2, FetchAll: true, MaxItems: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Paging  Paging  `json:"paging"`
+		HasMore bool    `json:"hasMore"`
+		Issues  []Issue `json:"issues"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if len(result.Issues) != 5 || result.HasMore {
+		t.Errorf("expected all 5 issues and no more pages, got %d issues, hasMore=%v", len(result.Issues), result.HasMore)
+	}
+	if strings.Join(requestedPages, ",") != "1,2,3" {
+		t.Errorf("expected pages 1,2,3 to be requested, got %v", requestedPages)
+	}
+
+	// the cap stops paging early and reports the truncation
+	requestedPages = nil
+	output, err = searchIssues(context.Background(), IssueSearchOptions{ProjectKey: "my_project", PageSize: 2, FetchAll: true, MaxItems: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if len(result.Issues) != 3 || !result.HasMore {
+		t.Errorf("expected 3 issues and more pages, got %d issues, hasMore=%v", len(result.Issues), result.HasMore)
+	}
+	if len(requestedPages) != 2 {
+		t.Errorf("expected 2 pages to be requested, got %v", requestedPages)
+	}
+}
+
+func TestIssuesSearchURL_Filters(t *testing.T) {
+	defer func(original string) { SONARQUBE_URL = original }(SONARQUBE_URL)
+	SONARQUBE_URL = "https://sonar.example.com/"
+
+	fullURL := issuesSearchURL(IssueSearchOptions{
+		ProjectKey: "my_project",
+		Types:      []string{"BUG", "VULNERABILITY"},
+		Tags:       []string{"security"},
+		Authors:    []string{"alice@example.com", "bob@example.com"},
+		Rules:      []string{"go:S1135"},
+	}, 1, 0)
+
+	parsed, err := url.Parse(fullURL)
+	if err != nil {
+		t.Fatalf("invalid URL %s: %v", fullURL, err)
+	}
+	query := parsed.Query()
+	want := map[string]string{
+		"projectKey": "my_project",
+		"types":      "BUG,VULNERABILITY",
+		"tags":       "security",
+		"rules":      "go:S1135",
+		"p":          "1",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got)
+		}
+	}
+
+	if authors := query["author"]; len(authors) != 2 || authors[0] != "alice@example.com" || authors[1] != "bob@example.com" {
+		t.Errorf("expected one author parameter per value, got %v", authors)
+	}
+
+	// omitted filters are not sent
+	plain := issuesSearchURL(IssueSearchOptions{ProjectKey: "my_project"}, 0, 0)
+	if plain != "https://sonar.example.com/api/issues/search?projectKey=my_project" {
+		t.Errorf("unexpected URL without filters: %s", plain)
+	}
+}
+
+func TestValidateCreationDates(t *testing.T) {
+	valid := []IssueSearchOptions{
+		{},
+		{CreatedAfter: "2024-01-01", CreatedBefore: "2024-01-15T00:00:00+0000"},
+		{CreatedInLast: "30d"},
+		{CreatedInLast: "1m2w", CreatedBefore: "2024-01-15"},
+	}
+	for _, opts := range valid {
+		if err := validateCreationDates(opts); err != nil {
+			t.Errorf("expected %+v to be valid: %v", opts, err)
+		}
+	}
+
+	invalid := []IssueSearchOptions{
+		{CreatedAfter: "last monday"},
+		{CreatedBefore: "2024/01/15"},
+		{CreatedInLast: "30 days"},
+		{CreatedInLast: "d30"},
+		{CreatedInLast: "30d", CreatedAfter: "2024-01-01"},
+	}
+	for _, opts := range invalid {
+		if err := validateCreationDates(opts); err == nil {
+			t.Errorf("expected %+v to be invalid", opts)
+		}
+	}
+}
+
+func TestCountIssues(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("facets") != "severities,types" || query.Get("ps") != "1" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{
+			"paging": {"pageIndex": 1, "pageSize": 1, "total": 42},
+			"issues": [{"key": "AX1"}],
+			"facets": [
+				{"property": "severities", "values": [{"val": "MAJOR", "count": 30}, {"val": "BLOCKER", "count": 12}]},
+				{"property": "types", "values": [{"val": "CODE_SMELL", "count": 40}, {"val": "BUG", "count": 2}]}
+			]
+		}`))
+	})
+
+	output, err := countIssues(context.Background(), IssueSearchOptions{ProjectKey: "my_project", Facets: []string{"severities", "types"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var counts IssueCounts
+	if err := json.Unmarshal([]byte(output), &counts); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if counts.Total != 42 || len(counts.Facets) != 2 || counts.Facets[0].Values[1] != (FacetValue{Val: "BLOCKER", Count: 12}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+	if strings.Contains(output, "AX1") {
+		t.Errorf("expected no issues in the output, got %s", output)
+	}
+}
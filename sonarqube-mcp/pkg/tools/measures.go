@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -13,49 +14,77 @@ import (
 	"github.com/intelops/sonarqube-mcp/pkg/utils"
 )
 
+type HistoryPoint struct {
+	Date  string `json:"date"`
+	Value string `json:"value,omitempty"`
+}
+type MeasureHistory struct {
+	Metric  string         `json:"metric"`
+	History []HistoryPoint `json:"history"`
+}
+type MeasuresHistoryResponse struct {
+	Paging   Paging           `json:"paging"`
+	Measures []MeasureHistory `json:"measures"`
+}
+
+// measuresParamAliases maps the deprecated camelCase parameters of
+// sonar_measures to their current names
+var measuresParamAliases = map[string]string{
+	"projectKey": "project_key",
+	"outputFile": "output_file",
+	"metricKeys": "metric_keys",
+}
+
 func AddMeasures(s *server.MCPServer) {
 	measureTool := mcp.NewTool("sonar_measures",
 		mcp.WithDescription("Fetch measure for metrics from Sonar scan results"),
-		mcp.WithString("projectKey",
+		mcp.WithString("project_key",
 			mcp.Description("Project or applucation identification key. eg my_project"),
 			mcp.Required(),
 		),
-		mcp.WithString("outputFile",
+		mcp.WithString("output_file",
 			mcp.Description("output path to store the fetched measures JSON file"),
 			mcp.DefaultString(""),
 			mcp.Required(),
 		),
-		mcp.WithArray("metricKeys",
+		mcp.WithArray("metric_keys",
 			mcp.Description("Comma saperated list of metric keys, eg: complexity,violations,security"),
 			mcp.DefaultArray([]any{}),
 			mcp.Required(),
 		),
+		noCacheOption(),
 	)
 
 	// Add tool to the server
+	s.AddTool(measureTool, handleMeasures)
+}
 
-	s.AddTool(measureTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args := request.GetArguments()
+func handleMeasures(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ctx = cacheContext(ctx, request)
+	applyDeprecatedAliases(request, measuresParamAliases)
+	args := request.GetArguments()
 
-		projectKey, ok := args["projectKey"].(string)
-		if !ok {
-			return nil, fmt.Errorf("missing projectKey parameter")
-		}
-		outputFile, ok := args["outputPath"].(string)
-		if !ok {
-			return nil, fmt.Errorf("missing outputFile parameter")
-		}
-		metricKeys := args["metricKeys"].([]any)
+	projectKey, ok := args["project_key"].(string)
+	if !ok || projectKey == "" {
+		return mcp.NewToolResultError("missing project_key parameter"), nil
+	}
+	outputFile, ok := args["output_file"].(string)
+	if !ok || outputFile == "" {
+		return mcp.NewToolResultError("missing output_file parameter"), nil
+	}
+	metricKeys, ok := args["metric_keys"].([]any)
+	if !ok {
+		return mcp.NewToolResultError("missing metric_keys parameter"), nil
+	}
 
-		measures, err := fetchMeasures(projectKey, metricKeys, outputFile)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("unable to fetch measures", err), nil
-		}
-		return mcp.NewToolResultText(measures), nil
-	})
+	measures, err := fetchMeasures(ctx, projectKey, metricKeys, outputFile)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("unable to fetch measures", err), nil
+	}
+	return mcp.NewToolResultText(measures), nil
 }
 
-func fetchMeasures(projectKey string, metricKeys []any, outputFile string) (string, error) {
+func fetchMeasures(ctx context.Context, projectKey string, metricKeys []any, outputFile string) (string, error) {
 	mks := utils.InterfacesToStringsOrEmpty(metricKeys)
 
 	encodedMetrics := ""
@@ -67,7 +96,7 @@ func fetchMeasures(projectKey string, metricKeys []any, outputFile string) (stri
 	base := SONARQUBE_URL + "api/measures/component?"
 	params := fmt.Sprintf("metricKeys=%s&component=%s", encodedMetrics, url.QueryEscape(projectKey))
 	fullURL := base + params
-	body, err := utils.MakeGetRequest(fullURL)
+	body, err := utils.MakeGetRequest(ctx, fullURL)
 	if err != nil {
 		return "", err
 	}
@@ -78,3 +107,105 @@ func fetchMeasures(projectKey string, metricKeys []any, outputFile string) (stri
 	}
 	return fmt.Sprintf("Written Measures output to: %s", outputFile), nil
 }
+
+// measuresHistoryParamAliases maps the deprecated camelCase parameters of
+// sonar_measures_history to their current names
+var measuresHistoryParamAliases = map[string]string{
+	"projectKey": "project_key",
+	"metricKeys": "metric_keys",
+}
+
+func AddMeasuresHistory(s *server.MCPServer) {
+	historyTool := mcp.NewTool("sonar_measures_history",
+		mcp.WithDescription("Fetch the history of measures for metrics of a Sonar project, to see whether e.g. coverage is improving or regressing."),
+		mcp.WithString("project_key",
+			mcp.Description("Project or application identification key. eg my_project"),
+			mcp.Required(),
+		),
+		mcp.WithArray("metric_keys",
+			mcp.Description("List of metric keys, eg: coverage,bugs,code_smells"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("from",
+			mcp.Description("Only return datapoints on or after this date, e.g. 2024-01-31 or 2024-01-31T13:00:00+0100. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("to",
+			mcp.Description("Only return datapoints on or before this date, e.g. 2024-06-30. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("branch",
+			mcp.Description("The SCM branch key or name (optional), e.g. feature/my_branch"),
+			mcp.DefaultString(""),
+		),
+		noCacheOption(),
+	)
+
+	s.AddTool(historyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, measuresHistoryParamAliases)
+		args := request.GetArguments()
+
+		projectKey, ok := args["project_key"].(string)
+		if !ok || projectKey == "" {
+			return mcp.NewToolResultError("missing project_key parameter"), nil
+		}
+		metricKeys, ok := args["metric_keys"].([]any)
+		if !ok || len(metricKeys) == 0 {
+			return mcp.NewToolResultError("missing metric_keys parameter"), nil
+		}
+		from, _ := args["from"].(string)
+		to, _ := args["to"].(string)
+		branch, _ := args["branch"].(string)
+
+		for _, date := range []string{from, to} {
+			if err := validateSonarDate(date); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		history, err := fetchMeasuresHistory(ctx, projectKey, utils.InterfacesToStringsOrEmpty(metricKeys), from, to, branch)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to fetch measures history", err), nil
+		}
+		return mcp.NewToolResultText(history), nil
+	})
+}
+
+func fetchMeasuresHistory(ctx context.Context, projectKey string, metricKeys []string, from, to, branch string) (string, error) {
+	params := url.Values{}
+	params.Set("component", projectKey)
+	params.Set("metrics", strings.Join(metricKeys, ","))
+	// the maximum page size, so long histories are not cut short
+	params.Set("ps", "1000")
+	if from != "" {
+		params.Set("from", from)
+	}
+	if to != "" {
+		params.Set("to", to)
+	}
+	if branch != "" {
+		params.Set("branch", branch)
+	}
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/measures/search_history?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	measures, err := parseMeasuresHistory(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(measures)
+}
+
+func parseMeasuresHistory(body []byte) ([]MeasureHistory, error) {
+	var response MeasuresHistoryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return response.Measures, nil
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const measuresFixture = `{"component":{"key":"my_project","measures":[{"metric":"complexity","value":"42"}]}}`
+
+func TestHandleMeasures_WritesOutputFile(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/measures/component" || r.URL.Query().Get("component") != "my_project" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(measuresFixture))
+	})
+
+	outputFile := filepath.Join(t.TempDir(), "measures.json")
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "sonar_measures"
+	request.Params.Arguments = map[string]any{
+		"project_key": "my_project",
+		"output_file": outputFile,
+		"metric_keys": []any{"complexity"},
+	}
+
+	result, err := handleMeasures(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("expected measures to be written: %v", err)
+	}
+	if string(content) != measuresFixture {
+		t.Errorf("unexpected file content: %s", content)
+	}
+}
+
+func TestHandleMeasures_MissingOutputFile(t *testing.T) {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "sonar_measures"
+	request.Params.Arguments = map[string]any{
+		"project_key": "my_project",
+		"metric_keys": []any{"complexity"},
+	}
+
+	result, err := handleMeasures(context.Background(), request)
+	if err != nil {
+		t.Fatalf("expected a tool error rather than an error, got %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected a tool error for a missing output_file")
+	}
+}
+
+const measuresHistoryFixture = `{
+  "paging": {"pageIndex": 1, "pageSize": 1000, "total": 2},
+  "measures": [
+    {
+      "metric": "coverage",
+      "history": [
+        {"date": "2024-01-02T10:00:00+0000", "value": "71.5"},
+        {"date": "2024-02-02T10:00:00+0000", "value": "74.0"}
+      ]
+    },
+    {
+      "metric": "bugs",
+      "history": [
+        {"date": "2024-01-02T10:00:00+0000", "value": "3"},
+        {"date": "2024-02-02T10:00:00+0000"}
+      ]
+    }
+  ]
+}`
+
+func TestParseMeasuresHistory(t *testing.T) {
+	measures, err := parseMeasuresHistory([]byte(measuresHistoryFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(measures) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(measures))
+	}
+
+	coverage := measures[0]
+	if coverage.Metric != "coverage" || len(coverage.History) != 2 {
+		t.Fatalf("unexpected coverage history: %+v", coverage)
+	}
+	if coverage.History[1] != (HistoryPoint{Date: "2024-02-02T10:00:00+0000", Value: "74.0"}) {
+		t.Errorf("unexpected datapoint: %+v", coverage.History[1])
+	}
+	if measures[1].History[1].Value != "" {
+		t.Errorf("expected a datapoint without value, got %+v", measures[1].History[1])
+	}
+}
+
+func TestValidateSonarDate(t *testing.T) {
+	for _, valid := range []string{"", "2024-01-31", "2024-01-31T13:00:00+0100"} {
+		if err := validateSonarDate(valid); err != nil {
+			t.Errorf("expected %q to be valid: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"31/01/2024", "2024-13-01", "yesterday"} {
+		if err := validateSonarDate(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type Metric struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Domain      string `json:"domain,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// MetricsResponse is the api/metrics/search response, which reports its paging
+// at the top level rather than in a paging object
+type MetricsResponse struct {
+	Metrics  []Metric `json:"metrics"`
+	Total    int      `json:"total"`
+	Page     int      `json:"p"`
+	PageSize int      `json:"ps"`
+}
+
+// MetricsPage is the sonar_metrics output
+type MetricsPage struct {
+	Paging  Paging   `json:"paging"`
+	HasMore bool     `json:"hasMore"`
+	Metrics []Metric `json:"metrics"`
+}
+
+// metricsParamAliases maps the deprecated camelCase parameters of
+// sonar_metrics to their current names
+var metricsParamAliases = map[string]string{
+	"pageSize": "page_size",
+}
+
+func AddMetrics(s *server.MCPServer) {
+	// create a new MCP tool for listing metrics
+	metricsTool := mcp.NewTool("sonar_metrics",
+		mcp.WithDescription("List the metrics available on the Sonar server with their key, name, type and domain. Call it to discover valid metric keys, e.g. coverage, ncloc or sqale_index, before using sonar_measures or sonar_measures_history."),
+		mcp.WithNumber("page",
+			mcp.Description("1-based page number to retrieve."),
+			mcp.DefaultNumber(1),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("Number of metrics per page (max 500)."),
+			mcp.DefaultNumber(maxPageSize),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(metricsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, metricsParamAliases)
+
+		page := request.GetInt("page", 1)
+		pageSize := request.GetInt("page_size", maxPageSize)
+		if page < 1 || pageSize < 1 || pageSize > maxPageSize {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid page %d or page_size %d: page must be at least 1 and page_size between 1 and %d", page, pageSize, maxPageSize)), nil
+		}
+
+		metrics, err := searchMetrics(ctx, page, pageSize)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve metrics.", err), nil
+		}
+
+		return mcp.NewToolResultText(metrics), nil
+	})
+}
+
+func searchMetrics(ctx context.Context, page, pageSize int) (string, error) {
+	params := url.Values{}
+	params.Set("p", strconv.Itoa(page))
+	params.Set("ps", strconv.Itoa(pageSize))
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/metrics/search?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	metrics, err := parseMetrics(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(metrics)
+}
+
+func parseMetrics(body []byte) (MetricsPage, error) {
+	var response MetricsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return MetricsPage{}, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	return MetricsPage{
+		Paging:  Paging{PageIndex: response.Page, PageSize: response.PageSize, Total: response.Total},
+		HasMore: response.Page*response.PageSize < response.Total,
+		Metrics: response.Metrics,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"testing"
+)
+
+const metricsFixture = `{
+  "metrics": [
+    {"id": "23", "key": "coverage", "type": "PERCENT", "name": "Coverage", "description": "Coverage by tests", "domain": "Coverage", "direction": 1, "qualitative": true, "hidden": false},
+    {"id": "2", "key": "ncloc", "type": "INT", "name": "Lines of Code", "domain": "Size"}
+  ],
+  "total": 3,
+  "p": 1,
+  "ps": 2
+}`
+
+func TestParseMetrics(t *testing.T) {
+	page, err := parseMetrics([]byte(metricsFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if page.Paging != (Paging{PageIndex: 1, PageSize: 2, Total: 3}) || !page.HasMore {
+		t.Errorf("unexpected paging %+v, hasMore=%v", page.Paging, page.HasMore)
+	}
+	want := []Metric{
+		{Key: "coverage", Name: "Coverage", Type: "PERCENT", Domain: "Coverage", Description: "Coverage by tests"},
+		{Key: "ncloc", Name: "Lines of Code", Type: "INT", Domain: "Size"},
+	}
+	if len(page.Metrics) != len(want) {
+		t.Fatalf("expected %d metrics, got %+v", len(want), page.Metrics)
+	}
+	for i := range want {
+		if page.Metrics[i] != want[i] {
+			t.Errorf("metric %d: expected %+v, got %+v", i, want[i], page.Metrics[i])
+		}
+	}
+}
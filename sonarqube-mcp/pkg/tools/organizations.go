@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Organization is a SonarCloud organization
+type Organization struct {
+	Key          string `json:"key"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Subscription string `json:"subscription,omitempty"`
+}
+
+// OrganizationsResponse is the sonar_organizations output. Organizations only
+// exist on SonarCloud, so against SonarQube Supported is false and Message
+// explains why no organizations are listed.
+type OrganizationsResponse struct {
+	Supported     bool           `json:"supported"`
+	Message       string         `json:"message,omitempty"`
+	Truncated     bool           `json:"truncated,omitempty"`
+	Organizations []Organization `json:"organizations"`
+}
+
+func AddOrganizations(s *server.MCPServer) {
+	// create a new MCP tool for listing SonarCloud organizations
+	organizationsTool := mcp.NewTool("sonar_organizations",
+		mcp.WithDescription("List the SonarCloud organizations the token can access, with each organization's key, which sonar_projects takes as organization, its name and subscription. SonarQube servers have no organizations; against them the tool reports that it is not supported."),
+		mcp.WithBoolean("member",
+			mcp.Description("Only list the organizations the token's user is a member of. Set to false to search all organizations."),
+			mcp.DefaultBool(true),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(organizationsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		member := request.GetBool("member", true)
+
+		organizations, err := searchOrganizations(ctx, member)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve organizations.", err), nil
+		}
+
+		return mcp.NewToolResultText(organizations), nil
+	})
+}
+
+func searchOrganizations(ctx context.Context, member bool) (string, error) {
+	params := url.Values{}
+	params.Set("member", strconv.FormatBool(member))
+
+	organizations, _, truncated, err := utils.MakePaginatedGetRequest[Organization](ctx, SONARQUBE_URL+"api/organizations/search?"+params.Encode(), "organizations", maxPageSize, defaultMaxItems)
+	if err != nil {
+		// SonarQube does not know the endpoint at all
+		var apiErr *utils.SonarAPIError
+		if errors.As(err, &apiErr) && apiErr.IsNotFound() {
+			return utils.PrettyPrint(OrganizationsResponse{
+				Message:       "organizations are not supported by this server; they only exist on SonarCloud, and SonarQube projects need no organization",
+				Organizations: []Organization{},
+			})
+		}
+		return "", err
+	}
+	if organizations == nil {
+		organizations = []Organization{}
+	}
+
+	return utils.PrettyPrint(OrganizationsResponse{
+		Supported:     true,
+		Truncated:     truncated,
+		Organizations: organizations,
+	})
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSearchOrganizations(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/organizations/search" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.URL.Query().Get("member") != "true" {
+			t.Errorf("unexpected member %q", r.URL.Query().Get("member"))
+		}
+		w.Write([]byte(`{"paging":{"pageIndex":1,"pageSize":500,"total":1},"organizations":[{"key":"my-org","name":"My Org","description":"Our code","url":"https://example.com","avatar":"https://example.com/a.png","subscription":"PAID","actions":{"admin":true}}]}`))
+	})
+
+	output, err := searchOrganizations(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var response OrganizationsResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	want := Organization{Key: "my-org", Name: "My Org", Description: "Our code", URL: "https://example.com", Subscription: "PAID"}
+	if !response.Supported || len(response.Organizations) != 1 || response.Organizations[0] != want {
+		t.Errorf("expected %+v, got %+v", want, response)
+	}
+}
+
+func TestSearchOrganizations_NotSupported(t *testing.T) {
+	// SonarQube answers unknown web services with a 404
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"msg":"Unknown url : /api/organizations/search"}]}`))
+	})
+
+	output, err := searchOrganizations(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var response OrganizationsResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if response.Supported || response.Message == "" || response.Organizations == nil || len(response.Organizations) != 0 {
+		t.Errorf("expected an unsupported response, got %+v", response)
+	}
+}
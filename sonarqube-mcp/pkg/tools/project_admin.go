@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ProjectCreation is the result of sonar_project_create
+type ProjectCreation struct {
+	Key            string `json:"key"`
+	Name           string `json:"name"`
+	Visibility     string `json:"visibility,omitempty"`
+	AlreadyExisted bool   `json:"alreadyExisted"`
+}
+
+type ProjectCreateResponse struct {
+	Project struct {
+		Key        string `json:"key"`
+		Name       string `json:"name"`
+		Qualifier  string `json:"qualifier"`
+		Visibility string `json:"visibility"`
+	} `json:"project"`
+}
+
+type ComponentShowResponse struct {
+	Component struct {
+		Key        string `json:"key"`
+		Name       string `json:"name"`
+		Qualifier  string `json:"qualifier"`
+		Visibility string `json:"visibility"`
+	} `json:"component"`
+}
+
+func AddProjectCreate(s *server.MCPServer) {
+	// create a new MCP tool for provisioning a project
+	createTool := mcp.NewTool("sonar_project_create",
+		mcp.WithDescription("Create (provision) a project so it can be analyzed. Requires a token with the 'Create Projects' permission, usually an administrator's. If a project with the key already exists it is left unchanged and returned with alreadyExisted set."),
+		mcp.WithString("name",
+			mcp.Description("Display name of the project, e.g. My Project."),
+			mcp.Required(),
+		),
+		mcp.WithString("project",
+			mcp.Description("Key of the project, e.g. my_project."),
+			mcp.Required(),
+		),
+		mcp.WithString("visibility",
+			mcp.Description("Whether the project is public or private. Defaults to the organization's or instance's default visibility. This parameter is optional."),
+			mcp.DefaultString(""),
+			mcp.Enum("public", "private"),
+		),
+		mcp.WithString("organization",
+			mcp.Description("The SonarCloud organization key (required on SonarCloud), e.g. my_organization."),
+			mcp.DefaultString(""),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(createTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		name, ok := args["name"].(string)
+		if !ok || name == "" {
+			return mcp.NewToolResultError("missing name parameter"), nil
+		}
+		project, ok := args["project"].(string)
+		if !ok || project == "" {
+			return mcp.NewToolResultError("missing project parameter"), nil
+		}
+		visibility, _ := args["visibility"].(string)
+		organization, _ := args["organization"].(string)
+
+		created, err := createProject(ctx, name, project, visibility, organization)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to create project.", err), nil
+		}
+
+		return mcp.NewToolResultText(created), nil
+	})
+}
+
+func createProject(ctx context.Context, name, project, visibility, organization string) (string, error) {
+	// look the key up first, since creating an existing project fails
+	params := url.Values{}
+	params.Set("component", project)
+	body, err := utils.MakeGetRequest(utils.WithoutCache(ctx), SONARQUBE_URL+"api/components/show?"+params.Encode())
+	if err == nil {
+		var existing ComponentShowResponse
+		if err := json.Unmarshal(body, &existing); err != nil {
+			return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+		}
+		return utils.PrettyPrint(ProjectCreation{
+			Key:            existing.Component.Key,
+			Name:           existing.Component.Name,
+			Visibility:     existing.Component.Visibility,
+			AlreadyExisted: true,
+		})
+	}
+	var apiErr *utils.SonarAPIError
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("name", name)
+	form.Set("project", project)
+	if visibility != "" {
+		form.Set("visibility", visibility)
+	}
+	if organization != "" {
+		form.Set("organization", organization)
+	}
+
+	body, err = utils.MakePostRequest(ctx, SONARQUBE_URL+"api/projects/create", form)
+	if err != nil {
+		return "", err
+	}
+
+	var response ProjectCreateResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	return utils.PrettyPrint(ProjectCreation{
+		Key:        response.Project.Key,
+		Name:       response.Project.Name,
+		Visibility: response.Project.Visibility,
+	})
+}
+
+// ProjectDeletion is the result of sonar_project_delete
+type ProjectDeletion struct {
+	Key     string `json:"key"`
+	Deleted bool   `json:"deleted"`
+}
+
+func AddProjectDelete(s *server.MCPServer) {
+	// create a new MCP tool for deleting a project
+	deleteTool := mcp.NewTool("sonar_project_delete",
+		mcp.WithDescription("Delete a project with all its analyses, issues and measures, e.g. an ephemeral project created for a pull request. This cannot be undone, so confirm must be set to true. Requires a token with the 'Administer' permission on the project."),
+		mcp.WithString("project",
+			mcp.Description("Key of the project to delete, e.g. my_project."),
+			mcp.Required(),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Must be true to delete the project."),
+			mcp.Required(),
+		),
+	)
+
+	// add the tool to the server
+	s.AddTool(deleteTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+
+		project, ok := args["project"].(string)
+		if !ok || project == "" {
+			return mcp.NewToolResultError("missing project parameter"), nil
+		}
+		if confirm, _ := args["confirm"].(bool); !confirm {
+			return mcp.NewToolResultError(fmt.Sprintf("deleting project %s cannot be undone; set confirm to true to delete it", project)), nil
+		}
+
+		deleted, err := deleteProject(ctx, project)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to delete project.", err), nil
+		}
+
+		return mcp.NewToolResultText(deleted), nil
+	})
+}
+
+func deleteProject(ctx context.Context, project string) (string, error) {
+	form := url.Values{}
+	form.Set("project", project)
+
+	// the endpoint answers with no content
+	if _, err := utils.MakePostRequest(ctx, SONARQUBE_URL+"api/projects/delete", form); err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(ProjectDeletion{Key: project, Deleted: true})
+}
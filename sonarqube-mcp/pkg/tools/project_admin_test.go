@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestCreateProject(t *testing.T) {
+	var created bool
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/components/show":
+			if r.URL.Query().Get("component") != "existing" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"errors":[{"msg":"Component key 'new_project' not found"}]}`))
+				return
+			}
+			w.Write([]byte(`{"component":{"key":"existing","name":"Existing","qualifier":"TRK","visibility":"public"}}`))
+		case "/api/projects/create":
+			if r.Method != http.MethodPost {
+				t.Errorf("unexpected method %s", r.Method)
+			}
+			r.ParseForm()
+			if r.PostForm.Get("project") != "new_project" || r.PostForm.Get("name") != "New Project" || r.PostForm.Get("visibility") != "private" || r.PostForm.Get("organization") != "my-org" {
+				t.Errorf("unexpected form %v", r.PostForm)
+			}
+			created = true
+			w.Write([]byte(`{"project":{"key":"new_project","name":"New Project","qualifier":"TRK","visibility":"private"}}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	output, err := createProject(context.Background(), "New Project", "new_project", "private", "my-org")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var creation ProjectCreation
+	if err := json.Unmarshal([]byte(output), &creation); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	want := ProjectCreation{Key: "new_project", Name: "New Project", Visibility: "private"}
+	if !created || creation != want {
+		t.Errorf("expected %+v, got %+v", want, creation)
+	}
+
+	// an existing project is returned without being created again
+	created = false
+	output, err = createProject(context.Background(), "Other Name", "existing", "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(output), &creation); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	want = ProjectCreation{Key: "existing", Name: "Existing", Visibility: "public", AlreadyExisted: true}
+	if created || creation != want {
+		t.Errorf("expected %+v, got %+v", want, creation)
+	}
+}
+
+func TestDeleteProject(t *testing.T) {
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/projects/delete" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		r.ParseForm()
+		if r.PostForm.Get("project") != "pr_42" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"msg":"Project 'other' not found"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	output, err := deleteProject(context.Background(), "pr_42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var deletion ProjectDeletion
+	if err := json.Unmarshal([]byte(output), &deletion); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if deletion != (ProjectDeletion{Key: "pr_42", Deleted: true}) {
+		t.Errorf("unexpected result %+v", deletion)
+	}
+
+	if _, err := deleteProject(context.Background(), "other"); err == nil {
+		t.Error("expected an error for an unknown project")
+	}
+}
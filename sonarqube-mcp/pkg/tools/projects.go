@@ -39,10 +39,20 @@ func AddProjects(s *server.MCPServer) {
 			mcp.Description("The Sonar cloud organization name, e.g. my_organization."),
 			mcp.Required(),
 		),
+		mcp.WithBoolean("fetch_all",
+			mcp.Description("Fetch all pages of projects, up to max_items projects. By default only the first page is returned."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithNumber("max_items",
+			mcp.Description("Maximum number of projects to collect when fetch_all is set."),
+			mcp.DefaultNumber(defaultMaxItems),
+		),
+		noCacheOption(),
 	)
 
 	// Add Project tool to the server
 	s.AddTool(projectsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
 		args := request.GetArguments()
 		// Extract the organization name from the request
 		org, ok := args["organization"].(string)
@@ -50,8 +60,11 @@ func AddProjects(s *server.MCPServer) {
 			return nil, fmt.Errorf("missing organization parameter")
 		}
 
+		fetchAll := request.GetBool("fetch_all", false)
+		maxItems := request.GetInt("max_items", defaultMaxItems)
+
 		// Make a call to Sonarcloud API to get projects
-		projects, err := searchProjects(org)
+		projects, err := searchProjects(ctx, org, fetchAll, maxItems)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("unable to retrieve sonar projects.", err), nil
 		}
@@ -61,11 +74,22 @@ func AddProjects(s *server.MCPServer) {
 	})
 }
 
-func searchProjects(organization string) (string, error) {
+func searchProjects(ctx context.Context, organization string, fetchAll bool, maxItems int) (string, error) {
 	url := fmt.Sprintf(SONARQUBE_URL+"api/projects/search?organization=%s", organization)
 	log.Infof("Making request to: %v", url)
 
-	body, err := utils.MakeGetRequest(url)
+	if fetchAll {
+		projects, total, truncated, err := utils.MakePaginatedGetRequest[Projects](ctx, url, "components", maxPageSize, maxItems)
+		if err != nil {
+			return "", err
+		}
+		if truncated {
+			log.Warnf("Returning %d of %d projects, raise max_items to fetch more", len(projects), total)
+		}
+		return utils.PrettyPrint(projects)
+	}
+
+	body, err := utils.MakeGetRequest(ctx, url)
 	if err != nil {
 		return "", err
 	}
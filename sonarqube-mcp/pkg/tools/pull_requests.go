@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type PullRequestStatus struct {
+	QualityGateStatus string `json:"qualityGateStatus"`
+}
+
+type PullRequestsResponse struct {
+	PullRequests []struct {
+		Key          string            `json:"key"`
+		Title        string            `json:"title"`
+		Branch       string            `json:"branch"`
+		Base         string            `json:"base"`
+		Status       PullRequestStatus `json:"status"`
+		AnalysisDate string            `json:"analysisDate"`
+	} `json:"pullRequests"`
+}
+
+// PullRequest is the summary of an analyzed pull request returned by the tool
+type PullRequest struct {
+	Key               string `json:"key"`
+	Title             string `json:"title"`
+	Branch            string `json:"branch"`
+	Base              string `json:"base"`
+	QualityGateStatus string `json:"qualityGateStatus,omitempty"`
+	AnalysisDate      string `json:"analysisDate,omitempty"`
+}
+
+func AddPullRequests(s *server.MCPServer) {
+	// create a new MCP tool for listing the pull requests of a project
+	pullRequestsTool := mcp.NewTool("sonar_pull_requests",
+		mcp.WithDescription("List the analyzed pull requests of a project. Returns each pull request's key, which other tools accept as pull_request, together with its title, branch, base branch and quality gate status."),
+		mcp.WithString("project",
+			mcp.Description("The project key, e.g. my_project."),
+			mcp.Required(),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(pullRequestsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+
+		project := request.GetString("project", "")
+		if project == "" {
+			return mcp.NewToolResultError("missing project parameter"), nil
+		}
+
+		pullRequests, err := listPullRequests(ctx, project)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve pull requests.", err), nil
+		}
+
+		return mcp.NewToolResultText(pullRequests), nil
+	})
+}
+
+func listPullRequests(ctx context.Context, project string) (string, error) {
+	params := url.Values{}
+	params.Set("project", project)
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/project_pull_requests/list?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	var response PullRequestsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	pullRequests := make([]PullRequest, 0, len(response.PullRequests))
+	for _, pr := range response.PullRequests {
+		pullRequests = append(pullRequests, PullRequest{
+			Key:               pr.Key,
+			Title:             pr.Title,
+			Branch:            pr.Branch,
+			Base:              pr.Base,
+			QualityGateStatus: pr.Status.QualityGateStatus,
+			AnalysisDate:      pr.AnalysisDate,
+		})
+	}
+
+	return utils.PrettyPrint(pullRequests)
+}
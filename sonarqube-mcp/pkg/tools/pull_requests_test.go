@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestListPullRequests(t *testing.T) {
+	var query string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/project_pull_requests/list" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"pullRequests":[{"key":"42","title":"Add caching","branch":"feature/cache","base":"main","status":{"qualityGateStatus":"ERROR","bugs":1,"vulnerabilities":0,"codeSmells":3},"analysisDate":"2024-05-01T10:00:00+0000","url":"https://github.com/org/repo/pull/42","target":"main"}]}`))
+	})
+
+	output, err := listPullRequests(context.Background(), "my_project")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "project=my_project" {
+		t.Errorf("unexpected query %q", query)
+	}
+
+	var pullRequests []PullRequest
+	if err := json.Unmarshal([]byte(output), &pullRequests); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	want := PullRequest{Key: "42", Title: "Add caching", Branch: "feature/cache", Base: "main", QualityGateStatus: "ERROR", AnalysisDate: "2024-05-01T10:00:00+0000"}
+	if len(pullRequests) != 1 || pullRequests[0] != want {
+		t.Errorf("expected %+v, got %+v", want, pullRequests)
+	}
+}
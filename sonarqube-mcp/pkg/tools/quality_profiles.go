@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type QualityProfile struct {
+	Key             string `json:"key"`
+	Name            string `json:"name"`
+	Language        string `json:"language"`
+	LanguageName    string `json:"languageName"`
+	IsDefault       bool   `json:"isDefault"`
+	IsInherited     bool   `json:"isInherited"`
+	ParentKey       string `json:"parentKey,omitempty"`
+	ActiveRuleCount int    `json:"activeRuleCount"`
+	ProjectCount    int    `json:"projectCount,omitempty"`
+	RuleUpdatedAt   string `json:"ruleUpdatedAt,omitempty"`
+	LastUsed        string `json:"lastUsed,omitempty"`
+}
+type QualityProfilesResponse struct {
+	Profiles []QualityProfile `json:"profiles"`
+}
+
+func AddQualityProfiles(s *server.MCPServer) {
+	// create a new MCP tool for searching quality profiles
+	profilesTool := mcp.NewTool("sonar_quality_profiles",
+		mcp.WithDescription("Search quality profiles, the sets of rules active during analysis. Returns each profile's key, name, language, active rule count and whether it is the default for its language."),
+		mcp.WithString("language",
+			mcp.Description("Only return profiles for this language, e.g. go. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("project",
+			mcp.Description("Only return the profiles used by this project key, e.g. my_project. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("organization",
+			mcp.Description("The Sonar cloud organization key or name, e.g. my_organization. Required on SonarCloud."),
+			mcp.DefaultString(""),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(profilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+
+		language := request.GetString("language", "")
+		project := request.GetString("project", "")
+		organization := request.GetString("organization", "")
+
+		profiles, err := searchQualityProfiles(ctx, language, project, organization)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve quality profiles.", err), nil
+		}
+
+		return mcp.NewToolResultText(profiles), nil
+	})
+}
+
+func searchQualityProfiles(ctx context.Context, language, project, organization string) (string, error) {
+	params := url.Values{}
+	if language != "" {
+		params.Set("language", language)
+	}
+	if project != "" {
+		params.Set("project", project)
+	}
+	if organization != "" {
+		params.Set("organization", organization)
+	}
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/qualityprofiles/search?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	var response QualityProfilesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	return utils.PrettyPrint(response.Profiles)
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSearchQualityProfiles(t *testing.T) {
+	var query string
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/qualityprofiles/search" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"profiles":[{"key":"AU-go","name":"Sonar way","language":"go","languageName":"Go","isInherited":false,"isDefault":true,"activeRuleCount":65,"activeDeprecatedRuleCount":0,"isBuiltIn":true}]}`))
+	})
+
+	output, err := searchQualityProfiles(context.Background(), "go", "my_project", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query != "language=go&project=my_project" {
+		t.Errorf("unexpected query %q", query)
+	}
+
+	var profiles []QualityProfile
+	if err := json.Unmarshal([]byte(output), &profiles); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	want := QualityProfile{Key: "AU-go", Name: "Sonar way", Language: "go", LanguageName: "Go", IsDefault: true, ActiveRuleCount: 65}
+	if len(profiles) != 1 || profiles[0] != want {
+		t.Errorf("expected %+v, got %+v", want, profiles)
+	}
+}
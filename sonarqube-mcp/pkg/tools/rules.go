@@ -0,0 +1,295 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type RuleRemediation struct {
+	Key                string `json:"key"`
+	Name               string `json:"name"`
+	Type               string `json:"type"`
+	Lang               string `json:"lang"`
+	RemFnType          string `json:"remFnType"`
+	RemFnBaseEffort    string `json:"remFnBaseEffort"`
+	RemFnGapMultiplier string `json:"remFnGapMultiplier"`
+	GapDescription     string `json:"gapDescription"`
+}
+
+type RuleDescriptionSection struct {
+	Key     string `json:"key"`
+	Content string `json:"content"`
+}
+
+// RuleDetails is a rule as returned by api/rules/show, from which the
+// remediation, the fix guidance and the name used by the tools are derived
+type RuleDetails struct {
+	Key                 string                   `json:"key"`
+	Name                string                   `json:"name"`
+	Type                string                   `json:"type"`
+	Lang                string                   `json:"lang"`
+	RemFnType           string                   `json:"remFnType"`
+	RemFnBaseEffort     string                   `json:"remFnBaseEffort"`
+	RemFnGapMultiplier  string                   `json:"remFnGapMultiplier"`
+	GapDescription      string                   `json:"gapDescription"`
+	DescriptionSections []RuleDescriptionSection `json:"descriptionSections"`
+}
+type RuleDetailsResponse struct {
+	Rule RuleDetails `json:"rule"`
+}
+
+// RuleInfo describes a rule as returned by sonar_rules
+type RuleInfo struct {
+	Key                 string                   `json:"key"`
+	Name                string                   `json:"name"`
+	Severity            string                   `json:"severity"`
+	Type                string                   `json:"type"`
+	Lang                string                   `json:"lang"`
+	LangName            string                   `json:"langName"`
+	Status              string                   `json:"status"`
+	Tags                []string                 `json:"tags,omitempty"`
+	SysTags             []string                 `json:"sysTags,omitempty"`
+	HtmlDesc            string                   `json:"htmlDesc,omitempty"`
+	MdDesc              string                   `json:"mdDesc,omitempty"`
+	DescriptionSections []RuleDescriptionSection `json:"descriptionSections,omitempty"`
+	RemFnType           string                   `json:"remFnType,omitempty"`
+	RemFnBaseEffort     string                   `json:"remFnBaseEffort,omitempty"`
+	RemFnGapMultiplier  string                   `json:"remFnGapMultiplier,omitempty"`
+	GapDescription      string                   `json:"gapDescription,omitempty"`
+}
+type RulesSearchResponse struct {
+	Total int        `json:"total"`
+	P     int        `json:"p"`
+	Ps    int        `json:"ps"`
+	Rules []RuleInfo `json:"rules"`
+}
+
+// maxGuidanceLength bounds the fix description attached to each issue
+const maxGuidanceLength = 500
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func AddRuleRemediation(s *server.MCPServer) {
+	// create a new MCP tool for fetching the remediation cost of a rule
+	remediationTool := mcp.NewTool("sonar_rule_remediation",
+		mcp.WithDescription("Fetch the remediation function (type, base effort, gap multiplier) and gap description of a Sonar rule, to estimate the effort needed to fix its issues."),
+		mcp.WithString("key",
+			mcp.Description("The rule key, e.g. go:S1135."),
+			mcp.Required(),
+		),
+		mcp.WithString("organization",
+			mcp.Description("The Sonar cloud organization key or name (optional), e.g. my_organization."),
+			mcp.DefaultString(""),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(remediationTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		args := request.GetArguments()
+
+		key, ok := args["key"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing key parameter")
+		}
+		organization, _ := args["organization"].(string)
+
+		// call the Sonarcloud API to get the rule
+		remediation, err := showRuleRemediation(ctx, key, organization)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve rule remediation.", err), nil
+		}
+
+		return mcp.NewToolResultText(remediation), nil
+	})
+}
+
+func showRuleRemediation(ctx context.Context, key, organization string) (string, error) {
+	rule, err := fetchRule(ctx, key, organization)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(ruleRemediation(rule))
+}
+
+// fetchRule fetches a rule with api/rules/show
+func fetchRule(ctx context.Context, key, organization string) (RuleDetails, error) {
+	organizationParam := ""
+	if organization != "" {
+		organizationParam = fmt.Sprintf("&organization=%s", url.QueryEscape(organization))
+	}
+
+	fullURL := fmt.Sprintf(SONARQUBE_URL+"api/rules/show?key=%s%s", url.QueryEscape(key), organizationParam)
+
+	body, err := utils.MakeGetRequest(ctx, fullURL)
+	if err != nil {
+		return RuleDetails{}, err
+	}
+
+	return parseRule(body)
+}
+
+func parseRule(body []byte) (RuleDetails, error) {
+	var response RuleDetailsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return RuleDetails{}, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return response.Rule, nil
+}
+
+// ruleRemediation returns the remediation function and gap description of a rule
+func ruleRemediation(rule RuleDetails) RuleRemediation {
+	return RuleRemediation{
+		Key:                rule.Key,
+		Name:               rule.Name,
+		Type:               rule.Type,
+		Lang:               rule.Lang,
+		RemFnType:          rule.RemFnType,
+		RemFnBaseEffort:    rule.RemFnBaseEffort,
+		RemFnGapMultiplier: rule.RemFnGapMultiplier,
+		GapDescription:     rule.GapDescription,
+	}
+}
+
+// ruleGuidance extracts the "how to fix" section of a rule as plain text,
+// falling back to the rule's gap description
+func ruleGuidance(rule RuleDetails) string {
+	for _, section := range rule.DescriptionSections {
+		if section.Key == "how_to_fix" {
+			return truncateGuidance(htmlToText(section.Content))
+		}
+	}
+	return truncateGuidance(rule.GapDescription)
+}
+
+func htmlToText(s string) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// truncateGuidance cuts s to at most maxGuidanceLength bytes, backing up to
+// a rune boundary so that multi-byte characters are never split
+func truncateGuidance(s string) string {
+	if len(s) <= maxGuidanceLength {
+		return s
+	}
+	cut := maxGuidanceLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(s[:cut]) + "..."
+}
+
+// rulesParamAliases maps the deprecated camelCase parameters of sonar_rules
+// to their current names
+var rulesParamAliases = map[string]string{
+	"ruleKey":  "rule_key",
+	"pageSize": "page_size",
+}
+
+func AddRules(s *server.MCPServer) {
+	// create a new MCP tool for looking up Sonar rules
+	rulesTool := mcp.NewTool("sonar_rules",
+		mcp.WithDescription("Look up Sonar rules by key or by a text query. Returns each rule's name, description, severity, type and remediation information, to explain what an issue means."),
+		mcp.WithString("rule_key",
+			mcp.Description("Key of the rule, e.g. go:S3776. Either rule_key or query is required."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("query",
+			mcp.Description("Text to search for in rule names and descriptions, e.g. complexity."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("languages",
+			mcp.Description("Comma-separated list of languages to restrict the search to, e.g. go,java. This parameter is optional."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithString("organization",
+			mcp.Description("The Sonar cloud organization key or name (optional), e.g. my_organization."),
+			mcp.DefaultString(""),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("Maximum number of rules to return."),
+			mcp.DefaultNumber(10),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(rulesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		applyDeprecatedAliases(request, rulesParamAliases)
+		args := request.GetArguments()
+
+		ruleKey, _ := args["rule_key"].(string)
+		query, _ := args["query"].(string)
+		if ruleKey == "" && query == "" {
+			return mcp.NewToolResultError("either rule_key or query is required"), nil
+		}
+		languages, _ := args["languages"].(string)
+		organization, _ := args["organization"].(string)
+		pageSize := request.GetInt("page_size", 10)
+
+		rules, err := searchRules(ctx, ruleKey, query, languages, organization, pageSize)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve rules.", err), nil
+		}
+
+		return mcp.NewToolResultText(rules), nil
+	})
+}
+
+func searchRules(ctx context.Context, ruleKey, query, languages, organization string, pageSize int) (string, error) {
+	params := url.Values{}
+	if ruleKey != "" {
+		params.Set("rule_key", ruleKey)
+	}
+	if query != "" {
+		params.Set("q", query)
+	}
+	if languages != "" {
+		params.Set("languages", languages)
+	}
+	if organization != "" {
+		params.Set("organization", organization)
+	}
+	if pageSize > 0 {
+		params.Set("ps", strconv.Itoa(pageSize))
+	}
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/rules/search?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	rules, err := parseRulesSearch(body)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rules) == 0 {
+		return "No rules found.", nil
+	}
+
+	return utils.PrettyPrint(rules)
+}
+
+func parseRulesSearch(body []byte) ([]RuleInfo, error) {
+	var response RulesSearchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return response.Rules, nil
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"testing"
+)
+
+const ruleShowFixture = `{
+  "rule": {
+    "key": "go:S3776",
+    "repo": "go",
+    "name": "Cognitive Complexity of functions should not be too high",
+    "severity": "CRITICAL",
+    "type": "CODE_SMELL",
+    "lang": "go",
+    "remFnType": "LINEAR_OFFSET",
+    "remFnGapMultiplier": "1min",
+    "remFnBaseEffort": "5min",
+    "gapDescription": "per complexity point over the threshold"
+  },
+  "actives": []
+}`
+
+func TestRuleRemediation(t *testing.T) {
+	parsed, err := parseRule([]byte(ruleShowFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rule := ruleRemediation(parsed)
+
+	want := RuleRemediation{
+		Key:                "go:S3776",
+		Name:               "Cognitive Complexity of functions should not be too high",
+		Type:               "CODE_SMELL",
+		Lang:               "go",
+		RemFnType:          "LINEAR_OFFSET",
+		RemFnBaseEffort:    "5min",
+		RemFnGapMultiplier: "1min",
+		GapDescription:     "per complexity point over the threshold",
+	}
+	if rule != want {
+		t.Errorf("expected %+v, got %+v", want, rule)
+	}
+}
+
+const rulesSearchFixture = `{
+  "total": 1,
+  "p": 1,
+  "ps": 10,
+  "rules": [
+    {
+      "key": "go:S3776",
+      "repo": "go",
+      "name": "Cognitive Complexity of functions should not be too high",
+      "severity": "CRITICAL",
+      "type": "CODE_SMELL",
+      "lang": "go",
+      "langName": "Go",
+      "status": "READY",
+      "sysTags": ["brain-overload"],
+      "htmlDesc": "<p>Cognitive Complexity is a measure of how hard the control flow of a function is to understand.</p>",
+      "descriptionSections": [
+        {"key": "root_cause", "content": "<p>Cognitive Complexity is a measure...</p>"}
+      ],
+      "remFnType": "LINEAR_OFFSET",
+      "remFnBaseEffort": "5min",
+      "remFnGapMultiplier": "1min",
+      "gapDescription": "per complexity point over the threshold"
+    }
+  ]
+}`
+
+func TestParseRulesSearch(t *testing.T) {
+	rules, err := parseRulesSearch([]byte(rulesSearchFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(rules))
+	}
+
+	rule := rules[0]
+	if rule.Key != "go:S3776" || rule.Severity != "CRITICAL" || rule.Type != "CODE_SMELL" || rule.LangName != "Go" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+	if rule.RemFnType != "LINEAR_OFFSET" || rule.RemFnBaseEffort != "5min" {
+		t.Errorf("expected remediation information, got %+v", rule)
+	}
+	if len(rule.DescriptionSections) != 1 || rule.HtmlDesc == "" {
+		t.Errorf("expected the rule description, got %+v", rule)
+	}
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type SourceLine struct {
+	Line        int    `json:"line"`
+	Code        string `json:"code"`
+	ScmAuthor   string `json:"scmAuthor,omitempty"`
+	ScmDate     string `json:"scmDate,omitempty"`
+	ScmRevision string `json:"scmRevision,omitempty"`
+}
+type SourceLinesResponse struct {
+	Sources []SourceLine `json:"sources"`
+}
+
+func AddSource(s *server.MCPServer) {
+	// create a new MCP tool for fetching source lines
+	sourceTool := mcp.NewTool("sonar_source",
+		mcp.WithDescription("Fetch source lines of a file analyzed by Sonar, with SCM blame information when available. Combine with an issue's textRange to show the offending code."),
+		mcp.WithString("key",
+			mcp.Description("The file key, e.g. my_project:src/foo/Bar.go."),
+			mcp.Required(),
+		),
+		mcp.WithNumber("from",
+			mcp.Description("First line to return (1-based). This parameter is optional."),
+		),
+		mcp.WithNumber("to",
+			mcp.Description("Last line to return (inclusive). This parameter is optional."),
+		),
+		mcp.WithString("branch",
+			mcp.Description("The SCM branch key or name (optional), e.g. feature/my_branch"),
+			mcp.DefaultString(""),
+		),
+		noCacheOption(),
+	)
+
+	// add the tool to the server
+	s.AddTool(sourceTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = cacheContext(ctx, request)
+		args := request.GetArguments()
+
+		key, ok := args["key"].(string)
+		if !ok || key == "" {
+			return mcp.NewToolResultError("missing key parameter"), nil
+		}
+		from := request.GetInt("from", 0)
+		to := request.GetInt("to", 0)
+		if from < 0 || to < 0 || (from > 0 && to > 0 && to < from) {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid line range %d-%d", from, to)), nil
+		}
+		branch, _ := args["branch"].(string)
+
+		lines, err := fetchSourceLines(ctx, key, from, to, branch)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to retrieve source lines.", err), nil
+		}
+
+		return mcp.NewToolResultText(lines), nil
+	})
+}
+
+func fetchSourceLines(ctx context.Context, key string, from, to int, branch string) (string, error) {
+	params := url.Values{}
+	params.Set("key", key)
+	if from > 0 {
+		params.Set("from", strconv.Itoa(from))
+	}
+	if to > 0 {
+		params.Set("to", strconv.Itoa(to))
+	}
+	if branch != "" {
+		params.Set("branch", branch)
+	}
+
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/sources/lines?"+params.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	lines, err := parseSourceLines(body)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.PrettyPrint(lines)
+}
+
+// parseSourceLines decodes api/sources/lines, turning the syntax-highlighted
+// HTML of each line back into plain code
+func parseSourceLines(body []byte) ([]SourceLine, error) {
+	var response SourceLinesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	for i := range response.Sources {
+		response.Sources[i].Code = html.UnescapeString(htmlTagPattern.ReplaceAllString(response.Sources[i].Code, ""))
+	}
+	return response.Sources, nil
+}
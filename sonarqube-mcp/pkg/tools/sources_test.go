@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"testing"
+)
+
+const sourceLinesFixture = `{
+  "sources": [
+    {"line": 12, "code": "<span class=\"k\">func</span> main() {", "scmAuthor": "alice@example.com", "scmDate": "2024-01-02T10:00:00+0000", "scmRevision": "1a2b3c"},
+    {"line": 13, "code": "\t<span class=\"k\">if</span> a &lt; b &amp;&amp; c {"},
+    {"line": 14, "code": "}"}
+  ]
+}`
+
+func TestParseSourceLines(t *testing.T) {
+	lines, err := parseSourceLines([]byte(sourceLinesFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []SourceLine{
+		{Line: 12, Code: "func main() {", ScmAuthor: "alice@example.com", ScmDate: "2024-01-02T10:00:00+0000", ScmRevision: "1a2b3c"},
+		{Line: 13, Code: "\tif a < b && c {"},
+		{Line: 14, Code: "}"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d", len(want), len(lines))
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: expected %+v, got %+v", i, want[i], lines[i])
+		}
+	}
+}
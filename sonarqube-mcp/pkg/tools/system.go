@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/intelops/sonarqube-mcp/pkg/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type HealthCause struct {
+	Message string `json:"message"`
+}
+
+// SystemHealth is the api/system/health response
+type SystemHealth struct {
+	Health string        `json:"health"`
+	Causes []HealthCause `json:"causes"`
+}
+
+// SystemStatus is the sonar_system_status output. The health fields are only
+// filled in when api/system/health is available to the configured token.
+type SystemStatus struct {
+	ID           string   `json:"id"`
+	Version      string   `json:"version"`
+	Status       string   `json:"status"`
+	Health       string   `json:"health,omitempty"`
+	HealthCauses []string `json:"healthCauses,omitempty"`
+	HealthError  string   `json:"healthError,omitempty"`
+}
+
+// ServerInfo is the server_info output. The SonarQube version is reported
+// as an error rather than failing the tool when the instance is unreachable.
+type ServerInfo struct {
+	Name                  string `json:"name"`
+	Version               string `json:"version"`
+	SonarQubeURL          string `json:"sonarqubeUrl"`
+	SonarQubeVersion      string `json:"sonarqubeVersion,omitempty"`
+	SonarQubeVersionError string `json:"sonarqubeVersionError,omitempty"`
+}
+
+func AddServerInfo(s *server.MCPServer, name, version string) {
+	// create a new MCP tool reporting the server and SonarQube versions
+	infoTool := mcp.NewTool("server_info",
+		mcp.WithDescription("Report the name and version of this MCP server together with the URL and version of the SonarQube instance it talks to, to debug compatibility problems across environments."),
+	)
+
+	// add the tool to the server
+	s.AddTool(infoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		info := ServerInfo{
+			Name:         name,
+			Version:      version,
+			SonarQubeURL: SONARQUBE_URL,
+		}
+
+		// the instance may have been upgraded since the last call
+		sonarVersion, err := sonarQubeVersion(utils.WithoutCache(ctx))
+		if err != nil {
+			info.SonarQubeVersionError = err.Error()
+		} else {
+			info.SonarQubeVersion = sonarVersion
+		}
+
+		output, err := utils.PrettyPrint(info)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to format the server info.", err), nil
+		}
+		return mcp.NewToolResultText(output), nil
+	})
+}
+
+// sonarQubeVersion returns the version of the SonarQube instance, which
+// api/server/version returns as plain text
+func sonarQubeVersion(ctx context.Context) (string, error) {
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/server/version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+func AddSystemStatus(s *server.MCPServer) {
+	// create a new MCP tool for checking the Sonar server
+	statusTool := mcp.NewTool("sonar_system_status",
+		mcp.WithDescription("Check that the Sonar server is reachable and healthy. Returns the instance id, version and status (STARTING, UP, DOWN, ...), plus its health (GREEN, YELLOW, RED) when the token is allowed to read it. Call it before other tools to diagnose connectivity problems."),
+	)
+
+	// add the tool to the server
+	s.AddTool(statusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// the status must always be fresh
+		ctx = utils.WithoutCache(ctx)
+
+		status, err := systemStatus(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("unable to reach the Sonar server.", err), nil
+		}
+
+		return mcp.NewToolResultText(status), nil
+	})
+}
+
+func systemStatus(ctx context.Context) (string, error) {
+	body, err := utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/system/status")
+	if err != nil {
+		return "", err
+	}
+
+	var status SystemStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	// health needs administer rights and does not exist on SonarCloud, so a
+	// failure is reported alongside the status rather than as an error
+	body, err = utils.MakeGetRequest(ctx, SONARQUBE_URL+"api/system/health")
+	if err != nil {
+		status.HealthError = err.Error()
+		return utils.PrettyPrint(status)
+	}
+	var health SystemHealth
+	if err := json.Unmarshal(body, &health); err != nil {
+		status.HealthError = fmt.Sprintf("failed to unmarshal health: %v", err)
+		return utils.PrettyPrint(status)
+	}
+	status.Health = health.Health
+	for _, cause := range health.Causes {
+		status.HealthCauses = append(status.HealthCauses, cause.Message)
+	}
+
+	return utils.PrettyPrint(status)
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSystemStatus(t *testing.T) {
+	t.Setenv("SONAR_MAX_RETRIES", "0")
+
+	healthStatus := http.StatusOK
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/system/status":
+			w.Write([]byte(`{"id":"20150504120436","version":"10.4.1","status":"UP"}`))
+		case "/api/system/health":
+			w.WriteHeader(healthStatus)
+			if healthStatus == http.StatusOK {
+				w.Write([]byte(`{"health":"YELLOW","causes":[{"message":"Elasticsearch status is YELLOW"}]}`))
+			} else {
+				w.Write([]byte(`{"errors":[{"msg":"Insufficient privileges"}]}`))
+			}
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	})
+
+	output, err := systemStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var status SystemStatus
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if status.Version != "10.4.1" || status.Status != "UP" || status.Health != "YELLOW" || len(status.HealthCauses) != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// a token without administer rights still gets the status
+	healthStatus = http.StatusForbidden
+	output, err = systemStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status = SystemStatus{}
+	if err := json.Unmarshal([]byte(output), &status); err != nil {
+		t.Fatalf("unexpected output %s: %v", output, err)
+	}
+	if status.Status != "UP" || status.Health != "" || status.HealthError == "" {
+		t.Errorf("expected the status with a health error, got %+v", status)
+	}
+}
+
+func TestSonarQubeVersion(t *testing.T) {
+	t.Setenv("SONAR_MAX_RETRIES", "0")
+
+	newTestSonarServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/server/version" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		w.Write([]byte("10.4.1.88267\n"))
+	})
+
+	version, err := sonarQubeVersion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != "10.4.1.88267" {
+		t.Errorf("expected version 10.4.1.88267, got %q", version)
+	}
+}
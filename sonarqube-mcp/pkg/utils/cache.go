@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// responseCache holds successful GET responses keyed by request URL
+var responseCache = struct {
+	sync.Mutex
+	entries map[string]cacheEntry
+}{entries: map[string]cacheEntry{}}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose GET requests bypass the response cache.
+// Fresh responses are still stored for later cached requests.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(noCacheKey{}).(bool)
+	return bypass
+}
+
+// cacheTTL reads SONAR_CACHE_TTL as a duration (e.g. 5m) or a number of
+// seconds. Caching is disabled when it is unset, zero or invalid.
+func cacheTTL() time.Duration {
+	value := os.Getenv("SONAR_CACHE_TTL")
+	if value == "" {
+		return 0
+	}
+	if ttl, err := time.ParseDuration(value); err == nil && ttl >= 0 {
+		return ttl
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	log.Warnf("invalid SONAR_CACHE_TTL %q, caching disabled", value)
+	return 0
+}
+
+// cachedResponse returns the cached body for url if it has not expired
+func cachedResponse(url string) ([]byte, bool) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	entry, ok := responseCache.entries[url]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(responseCache.entries, url)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// storeResponse caches body for url for the given ttl, dropping expired entries
+func storeResponse(url string, body []byte, ttl time.Duration) {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+
+	now := time.Now()
+	for key, entry := range responseCache.entries {
+		if now.After(entry.expires) {
+			delete(responseCache.entries, key)
+		}
+	}
+	responseCache.entries[url] = cacheEntry{body: body, expires: now.Add(ttl)}
+}
+
+// ClearCache drops all cached responses
+func ClearCache() {
+	responseCache.Lock()
+	defer responseCache.Unlock()
+	responseCache.entries = map[string]cacheEntry{}
+}
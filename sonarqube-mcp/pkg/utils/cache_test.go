@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestMakeGetRequest_Cache(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_CACHE_TTL", "1m")
+	defer ClearCache()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := MakeGetRequest(context.Background(), server.URL+"/api/projects/search"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the second request to be served from cache, got %d requests", requests)
+	}
+
+	// bypassing the cache always hits the server
+	if _, err := MakeGetRequest(WithoutCache(context.Background()), server.URL+"/api/projects/search"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected WithoutCache to bypass the cache, got %d requests", requests)
+	}
+
+	// a successful POST invalidates cached reads
+	if _, err := MakePostRequest(context.Background(), server.URL+"/api/issues/assign", url.Values{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := MakeGetRequest(context.Background(), server.URL+"/api/projects/search"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("expected the POST to clear the cache, got %d requests", requests)
+	}
+}
+
+func TestMakeGetRequest_CacheDisabledByDefault(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+	defer ClearCache()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := MakeGetRequest(context.Background(), server.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected no caching without SONAR_CACHE_TTL, got %d requests", requests)
+	}
+}
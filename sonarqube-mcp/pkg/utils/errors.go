@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodyLength bounds how much of an unparsable error body is kept
+const maxErrorBodyLength = 500
+
+// SonarAPIError is returned for non-2xx responses from the Sonar API. Messages
+// holds the msg of each entry in the standard {"errors":[{"msg":...}]} payload.
+type SonarAPIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Messages   []string
+	// Body is the raw response body when it did not contain error messages
+	Body string
+}
+
+func (e *SonarAPIError) Error() string {
+	detail := strings.Join(e.Messages, "; ")
+	if detail == "" {
+		detail = e.Body
+	}
+	msg := fmt.Sprintf("%s %q returned status %d", e.Method, e.URL, e.StatusCode)
+	if detail != "" {
+		msg += ": " + detail
+	}
+	if hint := e.hint(); hint != "" {
+		msg += " (" + hint + ")"
+	}
+	return msg
+}
+
+// hint suggests how to resolve the common failure classes
+func (e *SonarAPIError) hint() string {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return "check that SONAR_TOKEN is set and valid"
+	case e.StatusCode == http.StatusForbidden:
+		return "the token lacks the permission required for this request"
+	case e.StatusCode == http.StatusNotFound:
+		return "check the project, component or issue key"
+	case e.StatusCode == http.StatusTooManyRequests:
+		return "rate limited by the server"
+	}
+	return ""
+}
+
+// IsNotFound reports whether the error is a 404 from the Sonar API
+func (e *SonarAPIError) IsNotFound() bool { return e.StatusCode == http.StatusNotFound }
+
+// IsAuthError reports whether the error is a 401 or 403 from the Sonar API
+func (e *SonarAPIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// newSonarAPIError builds the error for a failed response, extracting the
+// messages of the standard error payload when present
+func newSonarAPIError(method, url string, statusCode int, body []byte) *SonarAPIError {
+	apiErr := &SonarAPIError{Method: method, URL: url, StatusCode: statusCode}
+
+	var payload struct {
+		Errors []struct {
+			Msg string `json:"msg"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		for _, e := range payload.Errors {
+			if e.Msg != "" {
+				apiErr.Messages = append(apiErr.Messages, e.Msg)
+			}
+		}
+	}
+	if len(apiErr.Messages) == 0 {
+		apiErr.Body = strings.TrimSpace(string(body))
+		if len(apiErr.Body) > maxErrorBodyLength {
+			apiErr.Body = apiErr.Body[:maxErrorBodyLength] + "..."
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMakeGetRequest_SonarAPIError(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"msg":"Component key 'my_project' not found"},{"msg":"second"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := MakeGetRequest(context.Background(), server.URL)
+	var apiErr *SonarAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected a SonarAPIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound || !apiErr.IsNotFound() || apiErr.IsAuthError() {
+		t.Errorf("unexpected status %d", apiErr.StatusCode)
+	}
+	if strings.Join(apiErr.Messages, "|") != "Component key 'my_project' not found|second" || apiErr.Body != "" {
+		t.Errorf("unexpected messages %q, body %q", apiErr.Messages, apiErr.Body)
+	}
+	if !strings.Contains(err.Error(), "returned status 404: Component key 'my_project' not found; second") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestMakeGetRequest_SonarAPIErrorAfterRetries(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_MAX_RETRIES", "1")
+	defer func(original time.Duration) { retryBaseDelay = original }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+
+	_, err := MakeGetRequest(context.Background(), server.URL)
+	var apiErr *SonarAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected a SonarAPIError behind the retry error, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || len(apiErr.Messages) != 0 || apiErr.Body != "<html>maintenance</html>" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+}
+
+func TestSonarAPIError_AuthHint(t *testing.T) {
+	err := newSonarAPIError(http.MethodGet, "https://sonarcloud.io/api/projects/search", http.StatusUnauthorized, nil)
+	if !err.IsAuthError() || !strings.Contains(err.Error(), "SONAR_TOKEN") {
+		t.Errorf("expected an auth error with a token hint, got %q", err.Error())
+	}
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ConfigureLogging sets up the logrus standard logger from LOG_FORMAT
+// (text or json, default text) and LOG_LEVEL (any logrus level, default
+// info). Logs always go to stderr, which keeps stdout free for the stdio
+// transport.
+func ConfigureLogging() error {
+	log.SetOutput(os.Stderr)
+
+	switch format := strings.ToLower(os.Getenv("LOG_FORMAT")); format {
+	case "", "text":
+		log.SetFormatter(&log.TextFormatter{})
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q: must be text or json", format)
+	}
+
+	level := log.InfoLevel
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		parsed, err := log.ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL %q: %w", value, err)
+		}
+		level = parsed
+	}
+	log.SetLevel(level)
+	return nil
+}
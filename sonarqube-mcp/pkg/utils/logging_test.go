@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	t.Cleanup(func() {
+		log.SetFormatter(&log.TextFormatter{})
+		log.SetLevel(log.InfoLevel)
+	})
+
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_LEVEL", "debug")
+	if err := ConfigureLogging(); err != nil {
+		t.Fatalf("ConfigureLogging: %v", err)
+	}
+	if log.GetLevel() != log.DebugLevel {
+		t.Errorf("level = %v, want debug", log.GetLevel())
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.WithField("tool", "sonar_issues").Debug("called")
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %q", buf.String())
+	}
+	if entry["msg"] != "called" || entry["tool"] != "sonar_issues" || entry["level"] != "debug" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+
+	t.Setenv("LOG_FORMAT", "xml")
+	if err := ConfigureLogging(); err == nil {
+		t.Error("expected an error for LOG_FORMAT=xml")
+	}
+
+	t.Setenv("LOG_FORMAT", "")
+	t.Setenv("LOG_LEVEL", "loud")
+	if err := ConfigureLogging(); err == nil {
+		t.Error("expected an error for LOG_LEVEL=loud")
+	}
+}
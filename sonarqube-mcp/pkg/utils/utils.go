@@ -1,12 +1,21 @@
 package utils
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -19,44 +28,417 @@ func PrettyPrint(data any) (string, error) {
 	return string(jsonData), nil
 }
 
-func MakeGetRequest(url string) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
+const (
+	defaultMaxRetries  = 3
+	defaultHTTPTimeout = 30 * time.Second
+)
+
+var (
+	// retryBaseDelay is the backoff before the first retry; it doubles with every attempt
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxDelay caps the backoff between attempts, including Retry-After delays
+	retryMaxDelay = 30 * time.Second
+)
+
+// retryableError marks a failure worth retrying: a network error, 429 or 5xx
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// MakeGetRequest performs an authenticated GET request and returns the response
+// body. Network errors and 429/5xx responses are retried up to SONAR_MAX_RETRIES
+// times with exponential backoff and jitter, honoring Retry-After headers.
+// Cancelling ctx aborts the request and any pending retry. When SONAR_CACHE_TTL
+// is set, successful responses are cached by URL unless ctx was created with
+// WithoutCache.
+func MakeGetRequest(ctx context.Context, url string) ([]byte, error) {
+	ttl := cacheTTL()
+	if ttl <= 0 {
+		return getWithRetries(ctx, url)
+	}
+
+	if !cacheBypassed(ctx) {
+		if body, ok := cachedResponse(url); ok {
+			log.Debugf("Serving %q from cache", url)
+			return body, nil
+		}
+	}
+	body, err := getWithRetries(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	storeResponse(url, body, ttl)
+	return body, nil
+}
+
+// getWithRetries performs the GET request, retrying retryable failures
+func getWithRetries(ctx context.Context, url string) ([]byte, error) {
+	retries := maxRetries()
+
+	for attempt := 0; ; attempt++ {
+		body, err := doRequest(ctx, http.MethodGet, url, nil)
+		if err == nil {
+			return body, nil
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || ctx.Err() != nil {
+			return nil, err
+		}
+		if attempt >= retries {
+			if retries == 0 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		delay := backoffDelay(attempt, retryable.retryAfter)
+		log.Warnf("GET %q failed (attempt %d of %d), retrying in %s: %v", url, attempt+1, retries+1, delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("request cancelled while waiting to retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// MakePaginatedGetRequest follows the paging of a Sonar list endpoint,
+// requesting pageSize elements per page and concatenating the elements of the
+// field array until pageIndex*pageSize reaches the reported total. Collection
+// stops early once maxItems elements are gathered (0 means no limit). It
+// returns the elements, the total reported by the server and whether the
+// result was truncated.
+func MakePaginatedGetRequest[T any](ctx context.Context, endpoint, field string, pageSize, maxItems int) ([]T, int, bool, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	var items []T
+	for page := 1; ; page++ {
+		query := base.Query()
+		query.Set("p", strconv.Itoa(page))
+		if pageSize > 0 {
+			query.Set("ps", strconv.Itoa(pageSize))
+		}
+		base.RawQuery = query.Encode()
+
+		body, err := MakeGetRequest(ctx, base.String())
+		if err != nil {
+			return nil, 0, false, err
+		}
+
+		var response map[string]json.RawMessage
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, 0, false, fmt.Errorf("failed to unmarshal response body: %w", err)
+		}
+		var paging struct {
+			PageIndex int `json:"pageIndex"`
+			PageSize  int `json:"pageSize"`
+			Total     int `json:"total"`
+		}
+		if raw, ok := response["paging"]; ok {
+			if err := json.Unmarshal(raw, &paging); err != nil {
+				return nil, 0, false, fmt.Errorf("failed to unmarshal paging: %w", err)
+			}
+		}
+		var pageItems []T
+		if raw, ok := response[field]; ok {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, 0, false, fmt.Errorf("failed to unmarshal %s: %w", field, err)
+			}
+		}
+		items = append(items, pageItems...)
+
+		done := len(pageItems) == 0 || paging.PageIndex*paging.PageSize >= paging.Total
+		if maxItems > 0 && len(items) >= maxItems {
+			return items[:maxItems], paging.Total, len(items) > maxItems || !done, nil
+		}
+		if done {
+			return items, paging.Total, false, nil
+		}
+	}
+}
+
+// MakePostRequest performs an authenticated form-encoded POST request and
+// returns the response body. POST requests change state on the server and are
+// therefore never retried; a successful one clears the response cache so that
+// later reads see the change.
+func MakePostRequest(ctx context.Context, endpoint string, form url.Values) ([]byte, error) {
+	body, err := doRequest(ctx, http.MethodPost, endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	ClearCache()
+	return body, nil
+}
+
+// doRequest performs a single request; form, if non-nil, is sent as the
+// url-encoded request body
+func doRequest(ctx context.Context, method, endpoint string, form url.Values) ([]byte, error) {
+	var reqBody io.Reader
+	if form != nil {
+		reqBody = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
 
-	tkn := getSonarToken()
-	req.SetBasicAuth(tkn, "")
+	if err := setAuthorization(req); err != nil {
+		return nil, err
+	}
 
-	client := &http.Client{}
+	client, err := httpClient()
+	if err != nil {
+		return nil, err
+	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform request: %w", err)
+		return nil, &retryableError{err: fmt.Errorf("failed to perform request: %w", err)}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
 	// read the body regardless, so we can include it in errors
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, &retryableError{err: fmt.Errorf("failed to read response body: %w", err)}
 	}
 	// 200–299 is success
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("GET %q returned status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+		err := newSonarAPIError(method, endpoint, resp.StatusCode, body)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, &retryableError{err: err, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		}
+		return nil, err
 	}
 	return body, nil
 }
 
-func getSonarToken() string {
-	sonarToken := os.Getenv("SONAR_TOKEN")
-	if sonarToken == "" {
-		log.Fatal("SONAR_TOKEN environment variable is not set")
+// sharedClient holds the client built on the first request, so that every
+// request reuses its transport and open connections
+var sharedClient struct {
+	sync.Mutex
+	client *http.Client
+}
+
+// httpClient returns the shared client, building it on first use. A failed
+// build is not remembered, so a later request reports the error again.
+func httpClient() (*http.Client, error) {
+	sharedClient.Lock()
+	defer sharedClient.Unlock()
+
+	if sharedClient.client == nil {
+		client, err := newHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		sharedClient.client = client
+	}
+	return sharedClient.client, nil
+}
+
+// ConfigureHTTPClient builds the shared client from SONAR_PROXY_URL,
+// SONAR_CA_CERT, SONAR_INSECURE_SKIP_VERIFY and SONAR_HTTP_TIMEOUT. It is
+// called at startup so that an unreadable CA bundle or an invalid setting
+// stops the server instead of failing every request.
+func ConfigureHTTPClient() error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	sharedClient.Lock()
+	defer sharedClient.Unlock()
+	sharedClient.client = client
+	return nil
+}
+
+// newHTTPClient builds the client used for all requests to the Sonar API.
+// Requests go through SONAR_PROXY_URL when set, otherwise through the proxy
+// given by HTTP_PROXY, HTTPS_PROXY and NO_PROXY. Server certificates are
+// verified against the system roots plus SONAR_CA_CERT.
+func newHTTPClient() (*http.Client, error) {
+	proxy, err := proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Timeout: httpTimeout(), Transport: transport}, nil
+}
+
+// tlsConfig adds the PEM certificates in SONAR_CA_CERT to the system roots
+// and disables verification entirely when SONAR_INSECURE_SKIP_VERIFY is true
+func tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{}
+
+	if value := os.Getenv("SONAR_INSECURE_SKIP_VERIFY"); value != "" {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SONAR_INSECURE_SKIP_VERIFY %q: %w", value, err)
+		}
+		if insecure {
+			log.Warn("SONAR_INSECURE_SKIP_VERIFY is set, TLS certificates will not be verified")
+			config.InsecureSkipVerify = true
+		}
+	}
+
+	if path := os.Getenv("SONAR_CA_CERT"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SONAR_CA_CERT: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in SONAR_CA_CERT %q", path)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// proxyFunc returns the proxy selection for SONAR_PROXY_URL, which may be an
+// http, https or socks5 URL, falling back to the standard proxy variables
+func proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	value := os.Getenv("SONAR_PROXY_URL")
+	if value == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SONAR_PROXY_URL %q: %w", value, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid SONAR_PROXY_URL %q: scheme must be http, https, socks5 or socks5h", value)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid SONAR_PROXY_URL %q: missing host", value)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// httpTimeout reads SONAR_HTTP_TIMEOUT as a duration (e.g. 45s) or a number of
+// seconds, falling back to the default when unset or invalid
+func httpTimeout() time.Duration {
+	value := os.Getenv("SONAR_HTTP_TIMEOUT")
+	if value == "" {
+		return defaultHTTPTimeout
+	}
+	if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+		return timeout
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	log.Warnf("invalid SONAR_HTTP_TIMEOUT %q, using %s", value, defaultHTTPTimeout)
+	return defaultHTTPTimeout
+}
+
+// maxRetries reads SONAR_MAX_RETRIES, falling back to the default when unset or invalid
+func maxRetries() int {
+	value := os.Getenv("SONAR_MAX_RETRIES")
+	if value == "" {
+		return defaultMaxRetries
+	}
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		log.Warnf("invalid SONAR_MAX_RETRIES %q, using %d", value, defaultMaxRetries)
+		return defaultMaxRetries
+	}
+	return retries
+}
+
+// backoffDelay returns the delay before retry number attempt+1: the server's
+// Retry-After if given, otherwise exponential backoff with full jitter
+func backoffDelay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, retryMaxDelay)
+	}
+	backoff := min(retryBaseDelay<<attempt, retryMaxDelay)
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// setAuthorization authenticates req with the Sonar token, using the scheme
+// selected by SONAR_AUTH_SCHEME: "basic" (token as user name, the default) or
+// "bearer" (Authorization: Bearer <token>, for SonarQube 10+ and some proxies)
+func setAuthorization(req *http.Request) error {
+	tkn, err := getSonarToken()
+	if err != nil {
+		return err
+	}
+
+	switch scheme := strings.ToLower(os.Getenv("SONAR_AUTH_SCHEME")); scheme {
+	case "", "basic":
+		req.SetBasicAuth(tkn, "")
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+tkn)
+	default:
+		return fmt.Errorf("unsupported SONAR_AUTH_SCHEME %q: expected basic or bearer", scheme)
+	}
+	return nil
+}
+
+// getSonarToken returns the Sonar token from the file named by
+// SONAR_TOKEN_FILE, as mounted from a Kubernetes secret, or else from
+// SONAR_TOKEN. Surrounding whitespace, such as a trailing newline in the
+// file, is trimmed. A missing token is an error of the request being made
+// rather than a fatal one, so a misconfigured call never stops the server.
+func getSonarToken() (string, error) {
+	if path := os.Getenv("SONAR_TOKEN_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read SONAR_TOKEN_FILE: %w", err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("SONAR_TOKEN_FILE %s is empty", path)
+		}
+		return token, nil
+	}
+
+	token := strings.TrimSpace(os.Getenv("SONAR_TOKEN"))
+	if token == "" {
+		return "", fmt.Errorf("neither SONAR_TOKEN nor SONAR_TOKEN_FILE is set")
 	}
-	return sonarToken
+	return token, nil
 }
 
 // InterfacesToStringsOrEmpty will cast strings and skip everything else.
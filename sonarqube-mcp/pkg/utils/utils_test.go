@@ -0,0 +1,451 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMakeGetRequest_RetriesServerErrors(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_MAX_RETRIES", "3")
+	defer func(original time.Duration) { retryBaseDelay = original }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	body, err := MakeGetRequest(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != `{"ok":true}` || attempts != 3 {
+		t.Errorf("expected success on the third attempt, got %q after %d attempts", body, attempts)
+	}
+}
+
+func TestMakeGetRequest_GivesUp(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_MAX_RETRIES", "2")
+	defer func(original time.Duration) { retryBaseDelay = original }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := MakeGetRequest(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "giving up after 3 attempts") || !strings.Contains(err.Error(), "429") {
+		t.Errorf("expected the last error after exhausting retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestMakeGetRequest_DoesNotRetryClientErrors(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	if _, err := MakeGetRequest(context.Background(), server.URL); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("7"); got != 7*time.Second {
+		t.Errorf("expected 7s, got %s", got)
+	}
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > 10*time.Second {
+		t.Errorf("expected a delay of up to 10s for an HTTP date, got %s", got)
+	}
+	if got := parseRetryAfter("soon"); got != 0 {
+		t.Errorf("expected no delay for an invalid header, got %s", got)
+	}
+	if got := backoffDelay(0, 90*time.Second); got != retryMaxDelay {
+		t.Errorf("expected Retry-After to be capped at %s, got %s", retryMaxDelay, got)
+	}
+}
+
+// resetHTTPClient drops the shared client, so that the next request builds
+// one from the current environment
+func resetHTTPClient() {
+	sharedClient.Lock()
+	defer sharedClient.Unlock()
+	sharedClient.client = nil
+}
+
+// withFreshHTTPClient resets the shared client now and after the test, for
+// tests that change the environment the client is built from
+func withFreshHTTPClient(t *testing.T) {
+	t.Helper()
+	resetHTTPClient()
+	t.Cleanup(resetHTTPClient)
+}
+
+func TestMakeGetRequest_Timeout(t *testing.T) {
+	withFreshHTTPClient(t)
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_HTTP_TIMEOUT", "50ms")
+	t.Setenv("SONAR_MAX_RETRIES", "0")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	if _, err := MakeGetRequest(context.Background(), server.URL); err == nil {
+		t.Error("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the request to time out quickly, took %s", elapsed)
+	}
+}
+
+func TestMakeGetRequest_Cancelled(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := MakeGetRequest(ctx, server.URL)
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected cancellation to abort the pending retry, got %v", err)
+	}
+}
+
+func TestMakeGetRequest_AuthScheme(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	tests := map[string]string{
+		"":       "Basic " + base64.StdEncoding.EncodeToString([]byte("test-token:")),
+		"basic":  "Basic " + base64.StdEncoding.EncodeToString([]byte("test-token:")),
+		"bearer": "Bearer test-token",
+		"Bearer": "Bearer test-token",
+	}
+	for scheme, want := range tests {
+		t.Setenv("SONAR_AUTH_SCHEME", scheme)
+		if _, err := MakeGetRequest(context.Background(), server.URL); err != nil {
+			t.Fatalf("scheme %q: unexpected error: %v", scheme, err)
+		}
+		if authorization != want {
+			t.Errorf("scheme %q: expected Authorization %q, got %q", scheme, want, authorization)
+		}
+	}
+
+	t.Setenv("SONAR_AUTH_SCHEME", "digest")
+	if _, err := MakeGetRequest(context.Background(), server.URL); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
+
+func TestMakePostRequest(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_AUTH_SCHEME", "bearer")
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("unexpected request: %s, Authorization %q", r.Method, r.Header.Get("Authorization"))
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("issue") != "AX1" {
+			t.Errorf("expected form field issue=AX1, got %v (%v)", r.PostForm, err)
+		}
+		if attempts == 1 {
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	body, err := MakePostRequest(context.Background(), server.URL, url.Values{"issue": {"AX1"}})
+	if err != nil || string(body) != `{"ok":true}` {
+		t.Fatalf("unexpected result %q: %v", body, err)
+	}
+
+	// state-changing requests must not be retried
+	if _, err := MakePostRequest(context.Background(), server.URL, url.Values{"issue": {"AX1"}}); err == nil {
+		t.Error("expected an error for a 503 response")
+	}
+	if attempts != 2 {
+		t.Errorf("expected no retries, got %d attempts", attempts)
+	}
+}
+
+func TestMakePaginatedGetRequest(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	// seven items served in pages of the requested size
+	var requestedPages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("projectKey") != "my_project" {
+			t.Errorf("expected the original query to be kept, got %q", r.URL.RawQuery)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("p"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("ps"))
+		requestedPages = append(requestedPages, r.URL.Query().Get("p"))
+
+		var keys []string
+		for i := (page-1)*pageSize + 1; i <= page*pageSize && i <= 7; i++ {
+			keys = append(keys, fmt.Sprintf("item-%d", i))
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"paging": map[string]int{"pageIndex": page, "pageSize": pageSize, "total": 7},
+			"items":  keys,
+		})
+	}))
+	defer server.Close()
+
+	items, total, truncated, err := MakePaginatedGetRequest[string](context.Background(), server.URL+"?projectKey=my_project", "items", 3, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 7 || items[6] != "item-7" || total != 7 || truncated {
+		t.Errorf("expected all 7 items, got %v (total %d, truncated %v)", items, total, truncated)
+	}
+	if strings.Join(requestedPages, ",") != "1,2,3" {
+		t.Errorf("expected pages 1,2,3 to be requested, got %v", requestedPages)
+	}
+
+	// the cap stops paging early and reports the truncation
+	requestedPages = nil
+	items, total, truncated, err = MakePaginatedGetRequest[string](context.Background(), server.URL+"?projectKey=my_project", "items", 3, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 4 || total != 7 || !truncated {
+		t.Errorf("expected 4 of 7 items and a truncation, got %v (total %d, truncated %v)", items, total, truncated)
+	}
+	if len(requestedPages) != 2 {
+		t.Errorf("expected 2 pages to be requested, got %v", requestedPages)
+	}
+}
+
+func TestMakeGetRequest_Proxy(t *testing.T) {
+	withFreshHTTPClient(t)
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a forward proxy receives the absolute URL of the target
+		proxied = r.URL.String()
+		w.Write([]byte(`{"proxied":true}`))
+	}))
+	defer proxy.Close()
+	t.Setenv("SONAR_PROXY_URL", proxy.URL)
+
+	body, err := MakeGetRequest(context.Background(), "http://sonar.example.invalid/api/projects/search")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != `{"proxied":true}` || proxied != "http://sonar.example.invalid/api/projects/search" {
+		t.Errorf("expected the request to go through the proxy, got %q via %q", body, proxied)
+	}
+}
+
+func TestProxyFunc_Invalid(t *testing.T) {
+	for _, value := range []string{"ftp://proxy:21", "http://", "://bad"} {
+		t.Setenv("SONAR_PROXY_URL", value)
+		if _, err := proxyFunc(); err == nil {
+			t.Errorf("expected an error for SONAR_PROXY_URL %q", value)
+		}
+	}
+}
+
+func TestMakeGetRequest_CACert(t *testing.T) {
+	withFreshHTTPClient(t)
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_MAX_RETRIES", "0")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	// the test server's certificate is not trusted by default
+	if _, err := MakeGetRequest(context.Background(), server.URL); err == nil {
+		t.Fatal("expected a certificate error without SONAR_CA_CERT")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	t.Setenv("SONAR_CA_CERT", caFile)
+	if err := ConfigureHTTPClient(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := MakeGetRequest(context.Background(), server.URL)
+	if err != nil || string(body) != `{"ok":true}` {
+		t.Errorf("expected the request to succeed with SONAR_CA_CERT, got %q, %v", body, err)
+	}
+}
+
+func TestMakeGetRequest_InsecureSkipVerify(t *testing.T) {
+	withFreshHTTPClient(t)
+	t.Setenv("SONAR_TOKEN", "test-token")
+	t.Setenv("SONAR_INSECURE_SKIP_VERIFY", "true")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	if _, err := MakeGetRequest(context.Background(), server.URL); err != nil {
+		t.Errorf("expected verification to be skipped, got %v", err)
+	}
+}
+
+func TestTLSConfig_Invalid(t *testing.T) {
+	emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(emptyFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	t.Setenv("SONAR_CA_CERT", emptyFile)
+	if _, err := tlsConfig(); err == nil {
+		t.Error("expected an error for a file without certificates")
+	}
+	if err := ConfigureHTTPClient(); err == nil {
+		t.Error("expected ConfigureHTTPClient to reject a file without certificates")
+	}
+
+	t.Setenv("SONAR_CA_CERT", filepath.Join(t.TempDir(), "missing.pem"))
+	if err := ConfigureHTTPClient(); err == nil {
+		t.Error("expected ConfigureHTTPClient to reject a missing file")
+	}
+
+	t.Setenv("SONAR_CA_CERT", "")
+	t.Setenv("SONAR_INSECURE_SKIP_VERIFY", "maybe")
+	if _, err := tlsConfig(); err == nil {
+		t.Error("expected an error for an invalid SONAR_INSECURE_SKIP_VERIFY")
+	}
+}
+
+func TestGetSonarToken(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", " env-token\n")
+	t.Setenv("SONAR_TOKEN_FILE", "")
+	if token, err := getSonarToken(); err != nil || token != "env-token" {
+		t.Errorf("expected env-token from SONAR_TOKEN, got %q, %v", token, err)
+	}
+
+	// the file takes precedence over the environment variable
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SONAR_TOKEN_FILE", path)
+	if token, err := getSonarToken(); err != nil || token != "file-token" {
+		t.Errorf("expected file-token from SONAR_TOKEN_FILE, got %q, %v", token, err)
+	}
+
+	t.Setenv("SONAR_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+	if _, err := getSonarToken(); err == nil || !strings.Contains(err.Error(), "cannot read SONAR_TOKEN_FILE") {
+		t.Errorf("expected an error for an unreadable token file, got %v", err)
+	}
+
+	t.Setenv("SONAR_TOKEN_FILE", "")
+	t.Setenv("SONAR_TOKEN", "")
+	if _, err := getSonarToken(); err == nil {
+		t.Error("expected an error without a token")
+	}
+}
+
+func TestMakeGetRequest_MissingToken(t *testing.T) {
+	t.Setenv("SONAR_TOKEN", "")
+	t.Setenv("SONAR_TOKEN_FILE", "")
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := MakeGetRequest(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "SONAR_TOKEN") {
+		t.Fatalf("expected a missing token error, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no request without a token, got %d", requests)
+	}
+}
+
+func TestMakeGetRequest_SharesClient(t *testing.T) {
+	withFreshHTTPClient(t)
+	t.Setenv("SONAR_TOKEN", "test-token")
+
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := MakeGetRequest(context.Background(), server.URL); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("expected the requests to reuse one connection, got %d", n)
+	}
+}
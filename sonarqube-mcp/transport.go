@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultShutdownGracePeriod leaves room within the 30s Kubernetes
+// termination grace period
+const defaultShutdownGracePeriod = 20 * time.Second
+
+// httpTransport is implemented by the SSE and streamable HTTP servers
+type httpTransport interface {
+	Start(addr string) error
+	Shutdown(ctx context.Context) error
+}
+
+// serve runs mcpServer over the selected transport until it stops or ctx is
+// cancelled: stdio, sse (Server-Sent Events) or http (streamable HTTP, served
+// at /mcp). The HTTP based transports listen on all interfaces at port.
+func serve(ctx context.Context, mcpServer *server.MCPServer, transport, port, baseURL string) error {
+	addr := "0.0.0.0:" + port
+	switch transport {
+	case "stdio":
+		log.Info("SonarQube STDIO MCP Server started ...")
+		err := server.NewStdioServer(mcpServer).Listen(ctx, os.Stdin, os.Stdout)
+		if ctx.Err() != nil {
+			log.Info("SonarQube STDIO MCP Server stopped")
+			return nil
+		}
+		return err
+	case "sse":
+		log.Infof("SonarQube SSE MCP Server running on %s (base URL %s)", addr, baseURL)
+		return serveHTTP(ctx, server.NewSSEServer(mcpServer, server.WithBaseURL(baseURL)), addr)
+	case "http":
+		log.Infof("SonarQube streamable HTTP MCP Server running on %s/mcp", addr)
+		return serveHTTP(ctx, server.NewStreamableHTTPServer(mcpServer), addr)
+	default:
+		return fmt.Errorf("unknown transport %q: must be stdio, sse or http", transport)
+	}
+}
+
+// serveHTTP starts srv and, once ctx is cancelled, shuts it down, giving
+// in-flight requests the grace period to finish
+func serveHTTP(ctx context.Context, srv httpTransport, addr string) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Start(addr)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	gracePeriod := shutdownGracePeriod()
+	log.Infof("Shutting down, waiting up to %s for active requests", gracePeriod)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Info("SonarQube MCP Server stopped")
+	return nil
+}
+
+// shutdownGracePeriod reads SHUTDOWN_GRACE_PERIOD as a duration (e.g. 45s)
+// or a number of seconds, falling back to the default when unset or invalid
+func shutdownGracePeriod() time.Duration {
+	value := os.Getenv("SHUTDOWN_GRACE_PERIOD")
+	if value == "" {
+		return defaultShutdownGracePeriod
+	}
+	if period, err := time.ParseDuration(value); err == nil && period > 0 {
+		return period
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	log.Warnf("invalid SHUTDOWN_GRACE_PERIOD %q, using %s", value, defaultShutdownGracePeriod)
+	return defaultShutdownGracePeriod
+}
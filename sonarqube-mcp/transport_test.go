@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeTransport blocks in Start until Shutdown is called, like http.Server
+type fakeTransport struct {
+	stopped     chan struct{}
+	hasDeadline bool
+}
+
+func (f *fakeTransport) Start(addr string) error {
+	<-f.stopped
+	return http.ErrServerClosed
+}
+
+func (f *fakeTransport) Shutdown(ctx context.Context) error {
+	_, f.hasDeadline = ctx.Deadline()
+	close(f.stopped)
+	return nil
+}
+
+func TestServeHTTPGracefulShutdown(t *testing.T) {
+	t.Setenv("SHUTDOWN_GRACE_PERIOD", "5")
+	srv := &fakeTransport{stopped: make(chan struct{})}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- serveHTTP(ctx, srv, "127.0.0.1:0") }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serveHTTP returned %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serveHTTP did not return after the context was cancelled")
+	}
+	if !srv.hasDeadline {
+		t.Error("Shutdown was called without a grace period deadline")
+	}
+}
+
+func TestServeHTTPStartError(t *testing.T) {
+	srv := startErrorTransport{}
+	if err := serveHTTP(context.Background(), srv, "127.0.0.1:0"); !errors.Is(err, errAddrInUse) {
+		t.Fatalf("serveHTTP returned %v, want %v", err, errAddrInUse)
+	}
+}
+
+var errAddrInUse = errors.New("address already in use")
+
+type startErrorTransport struct{}
+
+func (startErrorTransport) Start(addr string) error            { return errAddrInUse }
+func (startErrorTransport) Shutdown(ctx context.Context) error { return nil }
+
+func TestShutdownGracePeriod(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"":      defaultShutdownGracePeriod,
+		"45s":   45 * time.Second,
+		"10":    10 * time.Second,
+		"never": defaultShutdownGracePeriod,
+	} {
+		t.Setenv("SHUTDOWN_GRACE_PERIOD", value)
+		if got := shutdownGracePeriod(); got != want {
+			t.Errorf("SHUTDOWN_GRACE_PERIOD=%q: got %s, want %s", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scpLikeURLPattern matches scp-style SSH remotes such as git@github.com:org/repo.git.
+// The user and host must not start with "-", which git would read as an option.
+var scpLikeURLPattern = regexp.MustCompile(`^[\w.][\w.-]*@[\w.][\w.-]*:`)
+
+// isRemoteRepository reports whether repository looks like a git URL rather than a local path
+func isRemoteRepository(repository string) bool {
+	for _, prefix := range []string{"http://", "https://", "ssh://", "git://"} {
+		if strings.HasPrefix(repository, prefix) {
+			return true
+		}
+	}
+	return scpLikeURLPattern.MatchString(repository)
+}
+
+// cloneDepth returns the shallow clone depth configured with ZOEKT_CLONE_DEPTH,
+// or 0 for a full clone
+func cloneDepth() (int, error) {
+	value := os.Getenv("ZOEKT_CLONE_DEPTH")
+	if value == "" {
+		return 0, nil
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, fmt.Errorf("invalid ZOEKT_CLONE_DEPTH %q: must be a non-negative integer", value)
+	}
+	return depth, nil
+}
+
+// cloneRepository clones url into a new temporary directory and returns its
+// path. Every branch in the comma-separated branches list is fetched as a
+// local branch so zoekt-git-index can resolve it. The caller must remove the
+// directory when done.
+func cloneRepository(url, branches string, submodules bool) (string, error) {
+	if strings.HasPrefix(url, "-") {
+		return "", fmt.Errorf("invalid repository URL %q: must not start with -", url)
+	}
+
+	depth, err := cloneDepth()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "zoekt-clone-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create clone directory: %v", err)
+	}
+
+	cloneCmd := []string{"git", "clone", "--quiet"}
+	if depth > 0 {
+		cloneCmd = append(cloneCmd, "--depth", strconv.Itoa(depth), "--no-single-branch")
+	}
+	if submodules {
+		cloneCmd = append(cloneCmd, "--recurse-submodules")
+		if depth > 0 {
+			cloneCmd = append(cloneCmd, "--shallow-submodules")
+		}
+	}
+	cloneCmd = append(cloneCmd, "--", url, dir)
+
+	if err := runGit(cloneCmd); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	for _, branch := range strings.Split(branches, ",") {
+		branch = strings.TrimSpace(branch)
+		if branch == "" || branch == "HEAD" {
+			continue
+		}
+
+		fetchCmd := []string{"git", "-C", dir, "fetch", "--quiet", "--update-head-ok"}
+		if depth > 0 {
+			fetchCmd = append(fetchCmd, "--depth", strconv.Itoa(depth))
+		}
+		fetchCmd = append(fetchCmd, "origin", fmt.Sprintf("+refs/heads/%s:refs/heads/%s", branch, branch))
+
+		if err := runGit(fetchCmd); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+
+	return dir, nil
+}
+
+func runGit(cmd []string) error {
+	output, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %v, output: %s", strings.Join(cmd, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// repoNameFromURL derives a repository name such as github.com/org/repo from a git URL
+func repoNameFromURL(url string) string {
+	name := url
+	if idx := strings.Index(name, "://"); idx != -1 {
+		name = name[idx+3:]
+	} else if scpLikeURLPattern.MatchString(name) {
+		// git@host:org/repo -> host/org/repo
+		name = strings.Replace(name[strings.Index(name, "@")+1:], ":", "/", 1)
+	}
+
+	// Drop credentials and a trailing .git
+	if idx := strings.Index(name, "@"); idx != -1 && idx < strings.Index(name+"/", "/") {
+		name = name[idx+1:]
+	}
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "/"), ".git")
+	return name
+}
+
+// redactURL removes the user info, e.g. an access token, from an HTTP(S) or
+// ssh:// URL. Anything that does not parse as such a URL is returned as is.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+// headCommit returns the commit SHA that HEAD points to in the repository at path
+func headCommit(path string) (string, error) {
+	output, err := exec.Command("git", "-C", path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD of %s: %v", path, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultExcerptPageSize is the number of matches returned with excerpts
+// when page_size is not set
+const defaultExcerptPageSize = 20
+
+// maxExcerptFileBytes is the size of the largest source file excerpts are
+// read from
+const maxExcerptFileBytes = 10 << 20
+
+// Excerpt is a window of source lines around a match, read from disk
+type Excerpt struct {
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// sourceRoots returns the directories excerpts may be read from, listed in
+// ZOEKT_SOURCE_ROOTS
+func sourceRoots() ([]string, error) {
+	var roots []string
+	for _, root := range filepath.SplitList(os.Getenv("ZOEKT_SOURCE_ROOTS")) {
+		if root == "" {
+			continue
+		}
+		// Symlinks are resolved so the containment check compares real paths
+		resolved, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ZOEKT_SOURCE_ROOTS entry %s: %v", root, err)
+		}
+		roots = append(roots, resolved)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("excerpt_lines requires ZOEKT_SOURCE_ROOTS to list the directories that were indexed")
+	}
+	return roots, nil
+}
+
+// isWithin reports whether path is root or below it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// resolveSourceFile finds the file a match refers to below one of roots.
+// Relative names are tried against each root in turn; a name that escapes
+// its root, directly or through a symlink, is never resolved.
+func resolveSourceFile(roots []string, file string) (string, error) {
+	for _, root := range roots {
+		candidate := file
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(root, candidate)
+		}
+		resolved, err := filepath.EvalSymlinks(candidate)
+		if err != nil || !isWithin(root, resolved) {
+			continue
+		}
+		if info, err := os.Stat(resolved); err == nil && info.Mode().IsRegular() {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found under ZOEKT_SOURCE_ROOTS", file)
+}
+
+// readSourceLines reads the lines of a source file
+func readSourceLines(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxExcerptFileBytes {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, maxExcerptFileBytes)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExcerptFileBytes)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// excerptLines returns the 1-based line of lines with up to surrounding
+// lines on either side
+func excerptLines(lines []string, line, surrounding int) Excerpt {
+	if line < 1 || line > len(lines) {
+		return Excerpt{Error: fmt.Sprintf("line %d is past the end of the file, which may have changed since it was indexed", line)}
+	}
+	start := max(line-surrounding, 1)
+	end := min(line+surrounding, len(lines))
+	return Excerpt{
+		StartLine: start,
+		EndLine:   end,
+		Content:   strings.Join(lines[start-1:end], "\n"),
+	}
+}
+
+// addExcerpts attaches an excerpt with surrounding lines on either side to
+// every match with a line number, reading each file below roots once.
+// Problems reading a file are reported on the excerpt rather than failing the
+// search.
+func addExcerpts(matches []SearchMatch, roots []string, surrounding int) []SearchMatch {
+	type source struct {
+		lines []string
+		err   error
+	}
+	sources := make(map[string]source)
+
+	withExcerpts := make([]SearchMatch, 0, len(matches))
+	for _, match := range matches {
+		if match.Line > 0 {
+			src, ok := sources[match.File]
+			if !ok {
+				path, err := resolveSourceFile(roots, match.File)
+				if err == nil {
+					src.lines, src.err = readSourceLines(path)
+				} else {
+					src.err = err
+				}
+				sources[match.File] = src
+			}
+
+			var excerpt Excerpt
+			if src.err != nil {
+				excerpt = Excerpt{Error: src.err.Error()}
+			} else {
+				excerpt = excerptLines(src.lines, match.Line, surrounding)
+			}
+			match.Excerpt = &excerpt
+		}
+		withExcerpts = append(withExcerpts, match)
+	}
+	return withExcerpts
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExcerptLines(t *testing.T) {
+	lines := []string{"one", "two", "three", "four", "five"}
+
+	for _, tc := range []struct {
+		line, surrounding int
+		want              Excerpt
+	}{
+		{3, 1, Excerpt{StartLine: 2, EndLine: 4, Content: "two\nthree\nfour"}},
+		{1, 2, Excerpt{StartLine: 1, EndLine: 3, Content: "one\ntwo\nthree"}},
+		{5, 10, Excerpt{StartLine: 1, EndLine: 5, Content: strings.Join(lines, "\n")}},
+	} {
+		if got := excerptLines(lines, tc.line, tc.surrounding); got != tc.want {
+			t.Errorf("line %d, surrounding %d: expected %+v, got %+v", tc.line, tc.surrounding, tc.want, got)
+		}
+	}
+
+	if got := excerptLines(lines, 6, 1); got.Error == "" {
+		t.Errorf("expected an error past the end of the file, got %+v", got)
+	}
+}
+
+func TestSourceRoots(t *testing.T) {
+	t.Setenv("ZOEKT_SOURCE_ROOTS", "")
+	if _, err := sourceRoots(); err == nil {
+		t.Error("expected an error without ZOEKT_SOURCE_ROOTS")
+	}
+
+	t.Setenv("ZOEKT_SOURCE_ROOTS", filepath.Join(t.TempDir(), "missing"))
+	if _, err := sourceRoots(); err == nil {
+		t.Error("expected an error for a missing root")
+	}
+}
+
+func TestAddExcerpts_StaysWithinRoots(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks may require privileges")
+	}
+
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("password\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "repo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "repo", "main.go"), []byte("package main\n\nfunc main() {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "repo", "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("ZOEKT_SOURCE_ROOTS", root)
+	roots, err := sourceRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := filepath.Rel(root, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches := addExcerpts([]SearchMatch{
+		{File: "repo/main.go", Line: 3, Content: "func main() {"},
+		{File: "repo/main.go", Line: 1, Content: "package main"},
+		{File: "repo/link.txt", Line: 1, Content: "password"},
+		{File: rel, Line: 1, Content: "password"},
+		{File: secret, Line: 1, Content: "password"},
+		{File: "repo/main.go"},
+	}, roots, 1)
+
+	want := Excerpt{StartLine: 2, EndLine: 4, Content: "\nfunc main() {\n}"}
+	if matches[0].Excerpt == nil || *matches[0].Excerpt != want {
+		t.Errorf("expected %+v, got %+v", want, matches[0].Excerpt)
+	}
+	if matches[1].Excerpt == nil || matches[1].Excerpt.Content != "package main\n" {
+		t.Errorf("unexpected excerpt at the start of the file: %+v", matches[1].Excerpt)
+	}
+	for _, match := range matches[2:5] {
+		if match.Excerpt == nil || match.Excerpt.Error == "" || strings.Contains(match.Excerpt.Content, "password") {
+			t.Errorf("%s: expected the file outside the root not to be read, got %+v", match.File, match.Excerpt)
+		}
+	}
+	if matches[5].Excerpt != nil {
+		t.Errorf("expected no excerpt for a file-only match, got %+v", matches[5].Excerpt)
+	}
+}
@@ -2,10 +2,14 @@ module github.com/santoshkal/zoekt-mcp-server
 
 go 1.24.2
 
-require github.com/mark3labs/mcp-go v0.34.0
+require (
+	github.com/mark3labs/mcp-go v0.34.0
+	github.com/sirupsen/logrus v1.9.3
+)
 
 require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
 )
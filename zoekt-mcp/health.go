@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// zoektBinaries are the zoekt commands the tools run
+var zoektBinaries = []string{"zoekt", "zoekt-index", "zoekt-git-index"}
+
+// zoektBinary returns the command to run for the zoekt binary name: the
+// binary in ZOEKT_BIN_DIR when set, otherwise name, which is looked up on PATH
+func zoektBinary(name string) string {
+	if dir := os.Getenv("ZOEKT_BIN_DIR"); dir != "" {
+		return filepath.Join(dir, name)
+	}
+	return name
+}
+
+// lookupZoektBinary resolves the zoekt binary name like zoektBinary and
+// checks that it exists and is executable
+func lookupZoektBinary(name string) (string, error) {
+	path, err := exec.LookPath(zoektBinary(name))
+	if err != nil {
+		if dir := os.Getenv("ZOEKT_BIN_DIR"); dir != "" {
+			return "", fmt.Errorf("%s not found in ZOEKT_BIN_DIR %s", name, dir)
+		}
+		return "", fmt.Errorf("%s not found in PATH", name)
+	}
+	return path, nil
+}
+
+// BinaryStatus reports whether a zoekt binary could be resolved
+type BinaryStatus struct {
+	Name  string `json:"name"`
+	Path  string `json:"path,omitempty"`
+	Found bool   `json:"found"`
+	Error string `json:"error,omitempty"`
+}
+
+// IndexDirStatus reports whether the index directory can be used
+type IndexDirStatus struct {
+	Path     string `json:"path"`
+	Exists   bool   `json:"exists"`
+	Writable bool   `json:"writable"`
+	Shards   int    `json:"shards"`
+	Error    string `json:"error,omitempty"`
+}
+
+// checkBinaries resolves every zoekt binary and reports whether all were found
+func checkBinaries() ([]BinaryStatus, bool) {
+	statuses := make([]BinaryStatus, 0, len(zoektBinaries))
+	healthy := true
+	for _, name := range zoektBinaries {
+		status := BinaryStatus{Name: name}
+		path, err := lookupZoektBinary(name)
+		if err != nil {
+			status.Error = err.Error()
+			healthy = false
+		} else {
+			status.Path = path
+			status.Found = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, healthy
+}
+
+// checkIndexDir checks that indexDir is a directory the indexers can write
+// shards to, by creating and removing a temporary file in it
+func checkIndexDir(indexDir string) IndexDirStatus {
+	status := IndexDirStatus{Path: indexDir}
+
+	info, err := os.Stat(indexDir)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if !info.IsDir() {
+		status.Error = "not a directory"
+		return status
+	}
+	status.Exists = true
+
+	shards, _ := filepath.Glob(filepath.Join(indexDir, "*.zoekt"))
+	status.Shards = len(shards)
+
+	probe, err := os.CreateTemp(indexDir, ".zoekt-health-*")
+	if err != nil {
+		status.Error = fmt.Sprintf("not writable: %v", err)
+		return status
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	status.Writable = true
+	return status
+}
+
+func createHealthTool() mcp.Tool {
+	return mcp.NewTool("zoekt-health",
+		mcp.WithDescription("Check that the server can work: the zoekt, zoekt-index and zoekt-git-index binaries resolve on PATH (or in ZOEKT_BIN_DIR) and the index directory exists and is writable. Returns status ok or unhealthy with the details of each check."),
+		mcp.WithString("index_dir",
+			mcp.Description("Index directory to check (default: ~/.zoekt)"),
+		),
+	)
+}
+
+func handleHealthTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	indexDir := request.GetString("index_dir", "")
+	if indexDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		indexDir = filepath.Join(homeDir, ".zoekt")
+	}
+
+	binaries, healthy := checkBinaries()
+	indexStatus := checkIndexDir(indexDir)
+	if !indexStatus.Writable {
+		healthy = false
+	}
+
+	status := "ok"
+	if !healthy {
+		status = "unhealthy"
+	}
+
+	result := map[string]interface{}{
+		"status":    status,
+		"binaries":  binaries,
+		"index_dir": indexStatus,
+	}
+
+	return mcp.NewToolResultText(toJSON(result)), nil
+}
+
+func createServerInfoTool() mcp.Tool {
+	return mcp.NewTool("server_info",
+		mcp.WithDescription("Report the name and version of this MCP server together with the version of the installed zoekt binary, to debug compatibility problems across environments."),
+	)
+}
+
+func handleServerInfoTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	result := map[string]interface{}{
+		"name":       serverName,
+		"version":    serverVersion,
+		"go_version": runtime.Version(),
+	}
+
+	version, err := probeZoektVersion()
+	switch {
+	case errors.Is(err, errUnknownZoektVersion):
+		result["zoekt_version"] = "unknown"
+	case err != nil:
+		result["zoekt_version_error"] = err.Error()
+	default:
+		result["zoekt_version"] = version.String()
+	}
+
+	return mcp.NewToolResultText(toJSON(result)), nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHealthTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	binDir := t.TempDir()
+	for _, name := range []string{"zoekt", "zoekt-index"} {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+
+	indexDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(indexDir, "repo_v16.00000.zoekt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(indexDir string) map[string]json.RawMessage {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"index_dir": indexDir}
+		result, err := handleHealthTool(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var output map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+			t.Fatal(err)
+		}
+		return output
+	}
+
+	output := check(indexDir)
+	if string(output["status"]) != `"unhealthy"` {
+		t.Errorf("expected unhealthy without zoekt-git-index, got %s", output["status"])
+	}
+	var binaries []BinaryStatus
+	if err := json.Unmarshal(output["binaries"], &binaries); err != nil {
+		t.Fatal(err)
+	}
+	if len(binaries) != 3 || !binaries[0].Found || !binaries[1].Found || binaries[2].Found {
+		t.Errorf("unexpected binaries %+v", binaries)
+	}
+	if binaries[0].Path != filepath.Join(binDir, "zoekt") {
+		t.Errorf("expected zoekt to resolve in ZOEKT_BIN_DIR, got %q", binaries[0].Path)
+	}
+	var index IndexDirStatus
+	if err := json.Unmarshal(output["index_dir"], &index); err != nil {
+		t.Fatal(err)
+	}
+	if !index.Exists || !index.Writable || index.Shards != 1 {
+		t.Errorf("unexpected index dir status %+v", index)
+	}
+
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt-git-index"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	output = check(indexDir)
+	if string(output["status"]) != `"ok"` {
+		t.Errorf("expected ok, got %s", output["status"])
+	}
+
+	output = check(filepath.Join(indexDir, "missing"))
+	if string(output["status"]) != `"unhealthy"` {
+		t.Errorf("expected unhealthy for a missing index dir, got %s", output["status"])
+	}
+}
+
+func TestServerInfoTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho 'zoekt version v3.7.2'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+
+	result, err := handleServerInfoTool(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info map[string]string
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info["name"] != serverName || info["version"] != serverVersion || info["zoekt_version"] != "v3.7.2" {
+		t.Errorf("unexpected server info %v", info)
+	}
+
+	t.Setenv("ZOEKT_BIN_DIR", t.TempDir())
+	result, err = handleServerInfoTool(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info = nil
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info["zoekt_version_error"] == "" {
+		t.Errorf("expected a version error without a zoekt binary, got %v", info)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	t.Cleanup(func() {
+		log.SetFormatter(&log.TextFormatter{})
+		log.SetLevel(log.InfoLevel)
+	})
+
+	t.Setenv("LOG_FORMAT", "JSON")
+	t.Setenv("LOG_LEVEL", "warn")
+	if err := configureLogging(); err != nil {
+		t.Fatalf("configureLogging: %v", err)
+	}
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.Info("dropped")
+	log.Warn("kept")
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON log line, got %q", buf.String())
+	}
+	if entry["msg"] != "kept" || entry["level"] != "warning" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+
+	t.Setenv("LOG_FORMAT", "logfmt")
+	if err := configureLogging(); err == nil {
+		t.Error("expected an error for LOG_FORMAT=logfmt")
+	}
+}
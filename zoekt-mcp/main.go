@@ -3,28 +3,65 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	log "github.com/sirupsen/logrus"
 )
 
+const serverName = "zoekt-mcp-server"
+
+// serverVersion can be overridden at build time with
+// -ldflags "-X main.serverVersion=..."
+var serverVersion = "1.0.0"
+
+// defaultPreviewLength is the number of characters of command output returned
+// in the preview when preview_length is not set
+const defaultPreviewLength = 500
+
 func main() {
+	transport := flag.String("t", "stdio", "Transport type (stdio, sse or http)")
+	port := flag.String("p", "8080", "Port for the SSE and HTTP transports")
+	baseURL := flag.String("b", "", "Base URL for the SSE transport (default: http://localhost:<port>)")
+	flag.Parse()
+
+	if err := configureLogging(); err != nil {
+		log.Fatal(err)
+	}
+
+	if envPort, ok := os.LookupEnv("PORT"); ok {
+		*port = envPort
+	}
+	if envBaseURL, ok := os.LookupEnv("BASE_URL"); ok {
+		*baseURL = envBaseURL
+	}
+	if *baseURL == "" {
+		*baseURL = "http://localhost:" + *port
+	}
+
 	s := server.NewMCPServer(
-		"zoekt-mcp-server",
-		"1.0.0",
+		serverName,
+		serverVersion,
 	)
 
 	s.AddTool(createIndexTool(), handleIndexTool)
 	s.AddTool(createGitIndexTool(), handleGitIndexTool)
+	s.AddTool(createReindexTool(), handleReindexTool)
 	s.AddTool(createSearchTool(), handleSearchTool)
+	s.AddTool(createValidateQueryTool(), handleValidateQueryTool)
+	s.AddTool(createSnapshotTool(), handleSnapshotTool)
+	s.AddTool(createSnapshotDiffTool(), handleSnapshotDiffTool)
+	s.AddTool(createHealthTool(), handleHealthTool)
+	s.AddTool(createServerInfoTool(), handleServerInfoTool)
 
-	if err := server.ServeStdio(s); err != nil {
+	if err := serve(s, *transport, *port, *baseURL); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -37,12 +74,13 @@ func createIndexTool() mcp.Tool {
 		mcp.WithString("output_file", mcp.Required()),
 		mcp.WithString("language_map"),
 		mcp.WithBoolean("incremental"),
+		withPreviewLength(),
 	)
 }
 
 func createGitIndexTool() mcp.Tool {
 	return mcp.NewTool("zoekt-git-index",
-		mcp.WithDescription("Index a git repository for code search"),
+		mcp.WithDescription("Index a git repository for code search. The repository may be a local path or an HTTP(S)/SSH git URL, which is cloned into a temporary directory first."),
 		mcp.WithString("repository", mcp.Required()),
 		mcp.WithString("index_dir"),
 		mcp.WithString("output_file", mcp.Required()),
@@ -50,6 +88,20 @@ func createGitIndexTool() mcp.Tool {
 		mcp.WithString("branch_prefix"),
 		mcp.WithBoolean("submodules"),
 		mcp.WithBoolean("incremental"),
+		mcp.WithString("repo_url",
+			mcp.Description("Upstream URL of the repository, recorded in the shard so results can link back to the source (default: the clone URL for remote repositories)"),
+		),
+		withPreviewLength(),
+	)
+}
+
+func createReindexTool() mcp.Tool {
+	return mcp.NewTool("zoekt-reindex",
+		mcp.WithDescription("Discover every git repository under a root directory and index each one with zoekt-git-index. Failures are reported per repository instead of aborting the batch."),
+		mcp.WithString("root", mcp.Required()),
+		mcp.WithString("index_dir"),
+		mcp.WithString("output_file", mcp.Required()),
+		mcp.WithBoolean("incremental"),
 	)
 }
 
@@ -66,9 +118,58 @@ func createSearchTool() mcp.Tool {
 		mcp.WithBoolean("symbol_search"),
 		mcp.WithBoolean("debug_score"),
 		mcp.WithBoolean("verbose"),
+		mcp.WithBoolean("literal",
+			mcp.Description("Match the query as literal text instead of Zoekt query syntax with regular expressions (default: false)"),
+		),
+		mcp.WithBoolean("summarize",
+			mcp.Description("Return a compact ranked list of the top matching files instead of a raw preview. Full results are still written to output_file."),
+		),
+		mcp.WithNumber("summary_limit",
+			mcp.Description("Maximum number of files in the summary (default: 10)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Index of the first match to return when paging through results (default: 0)"),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("Number of matches to return per page. Enables paging; the response includes total_matches and has_more."),
+		),
+		mcp.WithNumber("excerpt_lines",
+			mcp.Description("Read each matched file from disk and include this many lines before and after every match. Enables paging with a page_size of 20 unless set. Files are only read below ZOEKT_SOURCE_ROOTS."),
+		),
+		withPreviewLength(),
 	)
 }
 
+// withPreviewLength adds the preview_length parameter to the tools that
+// return a preview of the command output
+func withPreviewLength() mcp.ToolOption {
+	return mcp.WithNumber("preview_length",
+		mcp.Description("Number of characters of output to return in the preview, 0 for the full output (default: 500)"),
+	)
+}
+
+// previewLengthArg returns the preview_length argument of a request
+func previewLengthArg(request mcp.CallToolRequest) (int, error) {
+	length := request.GetInt("preview_length", defaultPreviewLength)
+	if length < 0 {
+		return 0, fmt.Errorf("invalid preview_length %d", length)
+	}
+	return length, nil
+}
+
+func createValidateQueryTool() mcp.Tool {
+	return mcp.NewTool("zoekt-validate-query",
+		mcp.WithDescription("Validate a Zoekt query before searching. Returns whether the query is valid, an error describing the problem if not, and the parsed query tree."),
+		mcp.WithString("query", mcp.Required()),
+		mcp.WithString("index_dir"),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Also run the query through the zoekt binary with max_matches=0 (default: true)"),
+		),
+		mcp.WithBoolean("check_version",
+			mcp.Description("Check that every atom in the query is supported by the installed zoekt version (default: true)"),
+		),
+	)
+}
 
 func handleIndexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	directory, err := request.RequireString("directory")
@@ -80,6 +181,14 @@ func handleIndexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := checkOutputFile(outputFile); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	preview, err := previewLengthArg(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	cmd := []string{"zoekt-index"}
 
@@ -103,12 +212,18 @@ func handleIndexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Cal
 
 	cmd = append(cmd, directory)
 
-	result, err := executeCommand(cmd, outputFile)
+	start := time.Now()
+	result, stdout, stderr, err := executeCommand(cmd, outputFile, preview)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute zoekt-index: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	// The indexer logs its shard statistics to stderr
+	stats := parseIndexOutput(string(stdout) + string(stderr))
+	stats.ElapsedSeconds = time.Since(start).Seconds()
+	result["stats"] = stats
+
+	return mcp.NewToolResultText(toJSON(result)), nil
 }
 
 func handleGitIndexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -121,6 +236,14 @@ func handleGitIndexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := checkOutputFile(outputFile); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	preview, err := previewLengthArg(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	cmd := []string{"zoekt-git-index"}
 
@@ -152,14 +275,156 @@ func handleGitIndexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 		cmd = append(cmd, "-incremental")
 	}
 
-	cmd = append(cmd, repository)
+	// Remote repositories are cloned into a temporary directory first
+	repositoryPath := repository
+	remote := isRemoteRepository(repository)
+	if remote {
+		cloneDir, err := cloneRepository(repository, branches, submodules)
+		if err != nil {
+			message := strings.ReplaceAll(err.Error(), repository, redactURL(repository))
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to clone %s: %s", redactURL(repository), message)), nil
+		}
+		defer os.RemoveAll(cloneDir)
+		repositoryPath = cloneDir
+	}
+
+	// Record where the shard came from so search results can link back to it.
+	// Credentials are stripped so they end up neither in the index nor in the result.
+	repoURL := redactURL(request.GetString("repo_url", ""))
+	if repoURL == "" && remote {
+		repoURL = redactURL(repository)
+	}
+	if repoURL != "" {
+		cmd = append(cmd, "-name", repoNameFromURL(repoURL), "-url", repoURL)
+	}
+
+	cmd = append(cmd, repositoryPath)
+
+	// Read HEAD before indexing so the reported commit is the one that was indexed
+	commit, commitErr := headCommit(repositoryPath)
 
-	result, err := executeCommand(cmd, outputFile)
+	start := time.Now()
+	result, stdout, stderr, err := executeCommand(cmd, outputFile, preview)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute zoekt-git-index: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	if remote {
+		result["cloned_from"] = redactURL(repository)
+	}
+	if repoURL != "" {
+		result["repo_url"] = repoURL
+	}
+	if commitErr == nil {
+		result["commit"] = commit
+	}
+
+	// The indexer logs its shard statistics to stderr
+	stats := parseIndexOutput(string(stdout) + string(stderr))
+	stats.ElapsedSeconds = time.Since(start).Seconds()
+	result["stats"] = stats
+
+	return mcp.NewToolResultText(toJSON(result)), nil
+}
+
+func handleReindexTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	root, err := request.RequireString("root")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	outputFile, err := request.RequireString("output_file")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := checkOutputFile(outputFile); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	indexDir := request.GetString("index_dir", "")
+	if indexDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		indexDir = filepath.Join(homeDir, ".zoekt")
+	}
+	incremental := request.GetBool("incremental", false)
+
+	repos, err := findGitRepositories(root)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to scan %s: %v", root, err)), nil
+	}
+
+	// Every repository is indexed even if an earlier one fails. The log of
+	// each run is appended to output_file as it finishes, so the output of a
+	// large directory is never buffered whole.
+	logFile, err := os.Create(outputFile)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create output file: %v", err)), nil
+	}
+	defer logFile.Close()
+
+	results := make([]ReindexResult, 0, len(repos))
+	failed := 0
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+
+		cmd := []string{"zoekt-git-index", "-index", indexDir}
+		if incremental {
+			cmd = append(cmd, "-incremental")
+		}
+		cmd = append(cmd, repo)
+
+		start := time.Now()
+		stdout, stderr, err := runCommand(ctx, cmd)
+		elapsed := time.Since(start).Seconds()
+
+		fmt.Fprintf(logFile, "==> %s\n%s%s\n", strings.Join(cmd, " "), stdout, stderr)
+
+		// The indexer logs its shard statistics to stderr
+		repoResult := ReindexResult{
+			Repository: repo,
+			Status:     "success",
+			Stdout:     truncateString(strings.TrimSpace(string(stdout)), defaultPreviewLength),
+			Stderr:     truncateString(strings.TrimSpace(string(stderr)), defaultPreviewLength),
+			Stats:      parseIndexOutput(string(stdout) + string(stderr)),
+		}
+		repoResult.Stats.ElapsedSeconds = elapsed
+		if err != nil {
+			repoResult.Status = "failed"
+			repoResult.Error = err.Error()
+			failed++
+		}
+		results = append(results, repoResult)
+	}
+
+	if err := logFile.Close(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to write output to file: %v", err)), nil
+	}
+
+	// A cancelled run stops before the next repository; the ones not reached
+	// are reported as skipped
+	status := "success"
+	if ctx.Err() != nil {
+		status = "cancelled"
+	} else if failed > 0 && failed == len(repos) {
+		status = "failed"
+	} else if failed > 0 {
+		status = "partial"
+	}
+
+	result := map[string]interface{}{
+		"root":         root,
+		"index_dir":    indexDir,
+		"output_file":  outputFile,
+		"status":       status,
+		"repositories": results,
+		"succeeded":    len(results) - failed,
+		"failed":       failed,
+		"skipped":      len(repos) - len(results),
+	}
+
+	return mcp.NewToolResultText(toJSON(result)), nil
 }
 
 func handleSearchTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -172,6 +437,27 @@ func handleSearchTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	if err := checkOutputFile(outputFile); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	preview, err := previewLengthArg(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Excerpts are read from disk, so the allowed roots are checked up front
+	excerptContext := int(request.GetFloat("excerpt_lines", 0))
+	if excerptContext < 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid excerpt_lines %d", excerptContext)), nil
+	}
+	var roots []string
+	if excerptContext > 0 {
+		roots, err = sourceRoots()
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
 
 	cmd := []string{"zoekt"}
 
@@ -225,38 +511,132 @@ func handleSearchTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.Ca
 		cmd = append(cmd, "-v")
 	}
 
-	cmd = append(cmd, query)
+	// Literal mode escapes regex metacharacters and query operators
+	if request.GetBool("literal", false) {
+		query = literalQuery(query)
+	}
 
-	result, err := executeCommand(cmd, outputFile)
+	// The query follows -- like in the validator's dry run, so that a negated
+	// query such as -file:_test is not read as a flag
+	cmd = append(cmd, "--", query)
+
+	result, output, _, err := executeCommand(cmd, outputFile, preview)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute zoekt search: %v", err)), nil
 	}
 
-	return mcp.NewToolResultText(result), nil
+	// Summary mode replaces the raw preview with a ranked per-file overview
+	if request.GetBool("summarize", false) {
+		limit := int(request.GetFloat("summary_limit", 10))
+		result["summary"] = summarizeMatches(parseSearchOutput(string(output)), limit)
+		delete(result, "preview")
+		delete(result, "truncated")
+	}
+
+	// Paging is applied to the parsed result set, since zoekt returns every match at once
+	offset := int(request.GetFloat("offset", 0))
+	pageSize := int(request.GetFloat("page_size", 0))
+	if excerptContext > 0 && pageSize == 0 {
+		pageSize = defaultExcerptPageSize
+	}
+	if offset > 0 || pageSize > 0 {
+		matches := parseSearchOutput(string(output))
+		page, hasMore := paginateMatches(matches, offset, pageSize)
+		if excerptContext > 0 {
+			page = addExcerpts(page, roots, excerptContext)
+		}
+		result["matches"] = page
+		result["offset"] = offset
+		result["page_size"] = pageSize
+		result["total_matches"] = len(matches)
+		result["has_more"] = hasMore
+		delete(result, "preview")
+		delete(result, "truncated")
+	}
+
+	return mcp.NewToolResultText(toJSON(result)), nil
 }
 
+// executeCommand runs cmd, writes its stdout to outputFile and returns the
+// result summary for the client together with the raw stdout and stderr.
+// Anything zoekt prints to stderr is reported under "diagnostics" so it never
+// ends up in the result file. The preview holds the first previewLength
+// characters of stdout, or all of it when previewLength is 0; "truncated" and
+// "output_bytes" tell the client whether to read outputFile for the rest.
+// Output beyond ZOEKT_MAX_OUTPUT_BYTES kills the command; the output captured
+// until then is still written to outputFile and previewed in the error.
+func executeCommand(cmd []string, outputFile string, previewLength int) (map[string]interface{}, []byte, []byte, error) {
+	stdout, stderr, err := runCommand(context.Background(), cmd)
+	if errors.Is(err, errOutputLimit) {
+		partial := string(stdout)
+		if previewLength > 0 {
+			partial = truncateString(partial, previewLength)
+		}
+		if writeErr := os.WriteFile(outputFile, stdout, 0644); writeErr != nil {
+			return nil, nil, nil, fmt.Errorf("%v and could not be written to file: %v", err, writeErr)
+		}
+		return nil, nil, nil, fmt.Errorf("%v, so the command was stopped; the first %d bytes of output were written to %s, narrow the query or raise the limit. Partial output: %s",
+			err, len(stdout), outputFile, partial)
+	} else if err != nil {
+		return nil, nil, nil, fmt.Errorf("command failed: %v, stdout: %s, stderr: %s", err, stdout, stderr)
+	}
 
-func executeCommand(cmd []string, outputFile string) (string, error) {
-	execCmd := exec.Command(cmd[0], cmd[1:]...)
-	
-	output, err := execCmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("command failed: %v, output: %s", err, string(output))
+	if err := os.WriteFile(outputFile, stdout, 0644); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to write output to file: %v", err)
 	}
 
-	if err := os.WriteFile(outputFile, output, 0644); err != nil {
-		return "", fmt.Errorf("failed to write output to file: %v", err)
+	preview := string(stdout)
+	truncated := previewLength > 0 && len(preview) > previewLength
+	if truncated {
+		preview = truncateString(preview, previewLength)
 	}
 
 	result := map[string]interface{}{
-		"command":     strings.Join(cmd, " "),
-		"output_file": outputFile,
-		"status":      "success",
-		"preview":     truncateString(string(output), 500),
+		"command":      strings.Join(cmd, " "),
+		"output_file":  outputFile,
+		"status":       "success",
+		"preview":      preview,
+		"truncated":    truncated,
+		"output_bytes": len(stdout),
+		"diagnostics":  string(stderr),
 	}
 
+	return result, stdout, stderr, nil
+}
+
+// checkOutputFile makes sure outputFile can be written before an expensive
+// command runs, creating its parent directory if needed
+func checkOutputFile(outputFile string) error {
+	dir := filepath.Dir(outputFile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("output_file directory %s cannot be created: %v", dir, err)
+	}
+
+	info, err := os.Stat(outputFile)
+	if err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("output_file %s is a directory", outputFile)
+		}
+		f, err := os.OpenFile(outputFile, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("output_file %s is not writable: %v", outputFile, err)
+		}
+		f.Close()
+		return nil
+	}
+
+	probe, err := os.CreateTemp(dir, ".zoekt-output-*")
+	if err != nil {
+		return fmt.Errorf("output_file directory %s is not writable: %v", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+func toJSON(result map[string]interface{}) string {
 	jsonResult, _ := json.MarshalIndent(result, "", "  ")
-	return string(jsonResult), nil
+	return string(jsonResult)
 }
 
 func truncateString(s string, maxLen int) string {
@@ -264,4 +644,67 @@ func truncateString(s string, maxLen int) string {
 		return s
 	}
 	return s[:maxLen] + "..."
-}
\ No newline at end of file
+}
+
+func handleValidateQueryTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := request.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"query": query,
+	}
+
+	tree, warnings, err := parseQuery(query)
+	if err != nil {
+		result["valid"] = false
+		result["error"] = err.Error()
+		return mcp.NewToolResultText(toJSON(result)), nil
+	}
+
+	result["valid"] = true
+	result["tree"] = tree
+
+	if request.GetBool("check_version", true) {
+		version, err := probeZoektVersion()
+		switch {
+		case errors.Is(err, errUnknownZoektVersion):
+			// Without a version the atoms can only be flagged as possibly unsupported
+			result["zoekt_version"] = "unknown"
+			for _, atom := range unsupportedAtoms(tree, zoektVersion{}) {
+				warnings = append(warnings, fmt.Sprintf("%s: needs zoekt %s or later and may be searched as plain text by the installed zoekt, whose version is unknown; %s", atom.Field, atom.MinVersion, atom.Suggestion))
+			}
+		case err != nil:
+			result["version_check"] = fmt.Sprintf("skipped: %v", err)
+		default:
+			result["zoekt_version"] = version.String()
+			if unsupported := unsupportedAtoms(tree, version); len(unsupported) > 0 {
+				result["unsupported_atoms"] = unsupported
+				for _, atom := range unsupported {
+					warnings = append(warnings, fmt.Sprintf("%s: may be searched as plain text by zoekt %s, it needs %s or later; %s", atom.Field, version, atom.MinVersion, atom.Suggestion))
+				}
+			}
+		}
+	}
+
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+
+	if request.GetBool("dry_run", true) {
+		indexDir := request.GetString("index_dir", "")
+		if indexDir == "" {
+			homeDir, _ := os.UserHomeDir()
+			indexDir = filepath.Join(homeDir, ".zoekt")
+		}
+
+		dryRun := dryRunQuery(query, indexDir)
+		result["dry_run"] = dryRun
+		if dryRun.Ran && !dryRun.Accepted {
+			result["valid"] = false
+		}
+	}
+
+	return mcp.NewToolResultText(toJSON(result)), nil
+}
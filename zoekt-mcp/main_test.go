@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeZoekt installs a zoekt script printing output in a temporary
+// ZOEKT_BIN_DIR
+func fakeZoekt(t *testing.T, output string) {
+	t.Helper()
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s' '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+}
+
+func TestExecuteCommand_PreviewLength(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	output := strings.Repeat("x", 600)
+	fakeZoekt(t, output)
+	outputFile := filepath.Join(t.TempDir(), "out.txt")
+
+	for _, tc := range []struct {
+		previewLength int
+		want          string
+	}{
+		{defaultPreviewLength, output[:500] + "..."},
+		{10, output[:10] + "..."},
+		{0, output},
+		{1000, output},
+	} {
+		result, _, _, err := executeCommand([]string{"zoekt", "foo"}, outputFile, tc.previewLength)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result["preview"] != tc.want {
+			t.Errorf("preview_length %d: unexpected preview of %d characters", tc.previewLength, len(result["preview"].(string)))
+		}
+		if result["truncated"] != (len(tc.want) != len(output)) || result["output_bytes"] != len(output) {
+			t.Errorf("preview_length %d: unexpected truncated %v, output_bytes %v", tc.previewLength, result["truncated"], result["output_bytes"])
+		}
+	}
+
+	written, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != output {
+		t.Errorf("expected the full output in the output file, got %d bytes", len(written))
+	}
+}
+
+func TestCheckOutputFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// missing parent directories are created
+	nested := filepath.Join(dir, "a", "b", "out.txt")
+	if err := checkOutputFile(nested); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(filepath.Dir(nested)); err != nil || !info.IsDir() {
+		t.Errorf("expected %s to be created", filepath.Dir(nested))
+	}
+	if _, err := os.Stat(nested); !os.IsNotExist(err) {
+		t.Errorf("expected the output file not to be created, got %v", err)
+	}
+
+	if err := checkOutputFile(dir); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("expected a directory error, got %v", err)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkOutputFile(filepath.Join(file, "out.txt")); err == nil || !strings.Contains(err.Error(), "cannot be created") {
+		t.Errorf("expected an error for a path below a file, got %v", err)
+	}
+}
+
+func TestSearchTool_BogusOutputFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	// zoekt must not run when the output cannot be written
+	binDir := t.TempDir()
+	marker := filepath.Join(binDir, "ran")
+	script := "#!/bin/sh\ntouch '" + marker + "'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"query": "foo", "output_file": filepath.Join(file, "out.txt")}
+	result, err := handleSearchTool(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "output_file directory") {
+		t.Errorf("expected an output_file error, got %+v", result.Content)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("zoekt ran despite the invalid output_file")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// defaultMaxOutputBytes is the amount of command output buffered when
+// ZOEKT_MAX_OUTPUT_BYTES is not set
+const defaultMaxOutputBytes = 64 << 20
+
+// errOutputLimit is returned by an outputLimit's writers once the limit is
+// exceeded, which makes exec stop copying the command's output
+var errOutputLimit = errors.New("output limit exceeded")
+
+// maxOutputBytes returns the output limit configured with
+// ZOEKT_MAX_OUTPUT_BYTES, where 0 means no limit
+func maxOutputBytes() (int, error) {
+	value := os.Getenv("ZOEKT_MAX_OUTPUT_BYTES")
+	if value == "" {
+		return defaultMaxOutputBytes, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid ZOEKT_MAX_OUTPUT_BYTES %q: must be a non-negative integer", value)
+	}
+	return limit, nil
+}
+
+// outputLimit caps the combined output buffered by its writers. When a write
+// would exceed the limit, the part that fits is kept and onExceeded is called
+// once, e.g. to kill the command producing the output.
+type outputLimit struct {
+	mu         sync.Mutex
+	limit      int
+	used       int
+	exceeded   bool
+	onExceeded func()
+}
+
+// writer returns a writer appending to buf within the limit
+func (l *outputLimit) writer(buf *bytes.Buffer) *limitedWriter {
+	return &limitedWriter{limit: l, buf: buf}
+}
+
+// isExceeded reports whether more output than the limit was written
+func (l *outputLimit) isExceeded() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.exceeded
+}
+
+// limitedWriter is one of the writers sharing an outputLimit
+type limitedWriter struct {
+	limit *outputLimit
+	buf   *bytes.Buffer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	l := w.limit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.exceeded {
+		return 0, errOutputLimit
+	}
+	if l.limit == 0 || l.used+len(p) <= l.limit {
+		l.used += len(p)
+		return w.buf.Write(p)
+	}
+
+	n, _ := w.buf.Write(p[:l.limit-l.used])
+	l.used = l.limit
+	l.exceeded = true
+	if l.onExceeded != nil {
+		l.onExceeded()
+	}
+	return n, errOutputLimit
+}
+
+// runCommand runs the zoekt binary cmd[0] with the arguments cmd[1:] and
+// returns its stdout and stderr, buffered separately within the
+// ZOEKT_MAX_OUTPUT_BYTES limit. The command is killed when ctx is cancelled or
+// when its output exceeds the limit; in the latter case the error wraps
+// errOutputLimit and the output captured until then is returned with it.
+func runCommand(ctx context.Context, cmd []string) ([]byte, []byte, error) {
+	maxBytes, err := maxOutputBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	execCmd := exec.CommandContext(ctx, zoektBinary(cmd[0]), cmd[1:]...)
+
+	var stdout, stderr bytes.Buffer
+	limit := &outputLimit{
+		limit: maxBytes,
+		onExceeded: func() {
+			execCmd.Process.Kill()
+		},
+	}
+	execCmd.Stdout = limit.writer(&stdout)
+	execCmd.Stderr = limit.writer(&stderr)
+
+	err = execCmd.Run()
+	if limit.isExceeded() {
+		return stdout.Bytes(), stderr.Bytes(), fmt.Errorf("output exceeded ZOEKT_MAX_OUTPUT_BYTES (%d bytes): %w", maxBytes, errOutputLimit)
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxOutputBytes(t *testing.T) {
+	t.Setenv("ZOEKT_MAX_OUTPUT_BYTES", "")
+	if limit, err := maxOutputBytes(); err != nil || limit != defaultMaxOutputBytes {
+		t.Errorf("expected the default limit, got %d, %v", limit, err)
+	}
+
+	t.Setenv("ZOEKT_MAX_OUTPUT_BYTES", "0")
+	if limit, err := maxOutputBytes(); err != nil || limit != 0 {
+		t.Errorf("expected no limit, got %d, %v", limit, err)
+	}
+
+	for _, value := range []string{"-1", "lots"} {
+		t.Setenv("ZOEKT_MAX_OUTPUT_BYTES", value)
+		if _, err := maxOutputBytes(); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestExecuteCommand_MaxOutputBytes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	// zoekt never stops printing, so only the limit ends the command
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nwhile :; do echo 0123456789; done\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+	t.Setenv("ZOEKT_MAX_OUTPUT_BYTES", "1000")
+	outputFile := filepath.Join(t.TempDir(), "out.txt")
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, _, err := executeCommand([]string{"zoekt", "foo"}, outputFile, 20)
+		done <- err
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the command was not stopped at the output limit")
+	}
+	if err == nil || !strings.Contains(err.Error(), "exceeded ZOEKT_MAX_OUTPUT_BYTES (1000 bytes)") {
+		t.Fatalf("expected an output limit error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Partial output: 0123456789\n012345678...") {
+		t.Errorf("expected a preview of the partial output, got %v", err)
+	}
+
+	written, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1000 {
+		t.Errorf("expected the first 1000 bytes in the output file, got %d", len(written))
+	}
+}
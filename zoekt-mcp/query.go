@@ -0,0 +1,482 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QueryNode is a node of a parsed zoekt query tree
+type QueryNode struct {
+	Type     string       `json:"type"` // "and", "or", "not" or "atom"
+	Field    string       `json:"field,omitempty"`
+	Value    string       `json:"value,omitempty"`
+	Children []*QueryNode `json:"children,omitempty"`
+}
+
+// queryFieldAliases maps every field prefix zoekt understands to its canonical name
+var queryFieldAliases = map[string]string{
+	"archived": "archived",
+	"b":        "branch",
+	"branch":   "branch",
+	"c":        "content",
+	"content":  "content",
+	"case":     "case",
+	"f":        "file",
+	"file":     "file",
+	"fork":     "fork",
+	"lang":     "lang",
+	"public":   "public",
+	"r":        "repo",
+	"repo":     "repo",
+	"regex":    "regex",
+	"sym":      "sym",
+	"t":        "type",
+	"type":     "type",
+}
+
+// queryFieldValues lists the accepted values of enumerated fields
+var queryFieldValues = map[string][]string{
+	"archived": {"yes", "no"},
+	"case":     {"yes", "no", "auto"},
+	"fork":     {"yes", "no"},
+	"public":   {"yes", "no"},
+	"type":     {"filematch", "filename", "file", "repo"},
+}
+
+// regexFields are fields whose values zoekt compiles as regular expressions
+var regexFields = map[string]bool{
+	"":        true,
+	"content": true,
+	"file":    true,
+	"repo":    true,
+	"regex":   true,
+	"sym":     true,
+}
+
+type queryTokenKind int
+
+const (
+	tokenWord queryTokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenOr
+	tokenNot
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	text  string
+	field string
+}
+
+// parseQuery parses a zoekt query into a tree. Terms are combined with an
+// implicit AND, "or" has the lowest precedence and a leading "-" negates.
+// It returns the tree, warnings about suspicious but legal constructs, and
+// an error for queries zoekt would reject.
+func parseQuery(query string) (*QueryNode, []string, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("empty query")
+	}
+
+	p := &queryParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+
+	var warnings []string
+	if err := validateQueryNode(node, &warnings); err != nil {
+		return nil, nil, err
+	}
+	return node, warnings, nil
+}
+
+func tokenizeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, text: ")"})
+			i++
+		case c == '-' && i+1 < len(query) && query[i+1] != ' ':
+			tokens = append(tokens, queryToken{kind: tokenNot, text: "-"})
+			i++
+		default:
+			token, next, err := readQueryWord(query, i)
+			if err != nil {
+				return nil, err
+			}
+			if token.field == "" && strings.EqualFold(token.text, "or") {
+				token.kind = tokenOr
+			}
+			tokens = append(tokens, token)
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// readQueryWord reads a single term starting at i. Parentheses inside a
+// term are kept as part of it as long as they are balanced, so regexes
+// such as foo(bar|baz) stay intact.
+func readQueryWord(query string, i int) (queryToken, int, error) {
+	var value strings.Builder
+	token := queryToken{kind: tokenWord}
+	depth := 0
+
+	for i < len(query) {
+		c := query[i]
+		if c == ' ' || c == '\t' || c == '\n' {
+			break
+		}
+		if c == ')' && depth == 0 {
+			break
+		}
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ':':
+			// The first colon after a known field name separates field and value
+			if token.field == "" && value.Len() > 0 {
+				if field, ok := queryFieldAliases[value.String()]; ok {
+					token.field = field
+					value.Reset()
+					i++
+					continue
+				}
+			}
+		case '"':
+			end, quoted, err := readQuoted(query, i)
+			if err != nil {
+				return queryToken{}, 0, err
+			}
+			value.WriteString(quoted)
+			i = end
+			continue
+		}
+
+		value.WriteByte(c)
+		i++
+	}
+
+	token.text = value.String()
+	if token.field != "" && token.text == "" {
+		return queryToken{}, 0, fmt.Errorf("missing value for field %q", token.field)
+	}
+	return token, i, nil
+}
+
+// readQuoted reads a double-quoted string starting at i and returns the
+// index after the closing quote together with the unescaped content
+func readQuoted(query string, i int) (int, string, error) {
+	var value strings.Builder
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if j+1 < len(query) {
+				j++
+				value.WriteByte(query[j])
+			}
+		case '"':
+			return j + 1, value.String(), nil
+		default:
+			value.WriteByte(query[j])
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated quoted string at position %d", i)
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *queryParser) parseOr() (*QueryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []*QueryNode{left}
+	for t := p.peek(); t != nil && t.kind == tokenOr; t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &QueryNode{Type: "or", Children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (*QueryNode, error) {
+	var children []*QueryNode
+	for t := p.peek(); t != nil && t.kind != tokenOr && t.kind != tokenClose; t = p.peek() {
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	switch len(children) {
+	case 0:
+		if t := p.peek(); t != nil {
+			return nil, fmt.Errorf("unexpected %q", t.text)
+		}
+		return nil, fmt.Errorf("query ends unexpectedly")
+	case 1:
+		return children[0], nil
+	}
+	return &QueryNode{Type: "and", Children: children}, nil
+}
+
+func (p *queryParser) parseUnary() (*QueryNode, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenNot:
+		p.pos++
+		if p.peek() == nil {
+			return nil, fmt.Errorf("negation without a term")
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNode{Type: "not", Children: []*QueryNode{child}}, nil
+	case tokenOpen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case tokenWord:
+		p.pos++
+		return &QueryNode{Type: "atom", Field: t.field, Value: t.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// DryRunResult reports how the installed zoekt binary handled a query
+type DryRunResult struct {
+	Ran      bool   `json:"ran"`
+	Accepted bool   `json:"accepted"`
+	Message  string `json:"message,omitempty"`
+}
+
+// dryRunQuery runs the query through the zoekt binary without collecting matches
+func dryRunQuery(query, indexDir string) DryRunResult {
+	zoekt, err := lookupZoektBinary("zoekt")
+	if err != nil {
+		return DryRunResult{Message: err.Error() + "; only local parsing was performed"}
+	}
+
+	output, err := exec.Command(zoekt, "-index_dir", indexDir, "-max_matches", "0", "--", query).CombinedOutput()
+	if err != nil {
+		return DryRunResult{Ran: true, Message: strings.TrimSpace(fmt.Sprintf("%v: %s", err, output))}
+	}
+	return DryRunResult{Ran: true, Accepted: true}
+}
+
+// zoektVersion is the version reported by the installed zoekt binary
+type zoektVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v zoektVersion) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func (v zoektVersion) less(other zoektVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+var versionPattern = regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// atomMinVersion lists the atoms that old zoekt builds silently treat as
+// plain text, with the release line assumed to understand them. The mapping
+// is approximate, so atoms found here only produce warnings and never make a
+// query invalid. Atoms not listed here are supported by every release.
+var atomMinVersion = map[string]zoektVersion{
+	"archived": {Major: 3},
+	"fork":     {Major: 3},
+	"public":   {Major: 3},
+	"sym":      {Major: 3},
+	"type":     {Major: 3},
+}
+
+// atomSuggestions explains how to work around an unsupported atom
+var atomSuggestions = map[string]string{
+	"archived": "filter repositories by name with repo: instead",
+	"fork":     "filter repositories by name with repo: instead",
+	"public":   "filter repositories by name with repo: instead",
+	"sym":      "search the symbol name as content, or use the symbol_search option of zoekt-search",
+	"type":     "use the list_files option of zoekt-search instead of type:filename",
+}
+
+// UnsupportedAtom describes a query atom the installed zoekt cannot interpret
+type UnsupportedAtom struct {
+	Field      string `json:"field"`
+	MinVersion string `json:"min_version"`
+	Suggestion string `json:"suggestion"`
+}
+
+// parseZoektVersion extracts a version from the output of zoekt -version
+func parseZoektVersion(output string) (zoektVersion, bool) {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return zoektVersion{}, false
+	}
+	var v zoektVersion
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	return v, true
+}
+
+// errUnknownZoektVersion is returned by probeZoektVersion for zoekt builds
+// without a -version flag
+var errUnknownZoektVersion = errors.New("the installed zoekt does not report its version")
+
+// probeZoektVersion asks the installed zoekt binary for its version
+func probeZoektVersion() (zoektVersion, error) {
+	zoekt, err := lookupZoektBinary("zoekt")
+	if err != nil {
+		return zoektVersion{}, err
+	}
+
+	output, _ := exec.Command(zoekt, "-version").CombinedOutput()
+	if strings.Contains(string(output), "flag provided but not defined") {
+		return zoektVersion{}, errUnknownZoektVersion
+	}
+	if v, ok := parseZoektVersion(string(output)); ok {
+		return v, nil
+	}
+	return zoektVersion{}, fmt.Errorf("unable to determine zoekt version from %q", strings.TrimSpace(string(output)))
+}
+
+// unsupportedAtoms returns the atoms in the query tree that the given zoekt version does not support
+func unsupportedAtoms(node *QueryNode, version zoektVersion) []UnsupportedAtom {
+	var result []UnsupportedAtom
+	seen := make(map[string]bool)
+
+	var walk func(n *QueryNode)
+	walk = func(n *QueryNode) {
+		for _, child := range n.Children {
+			walk(child)
+		}
+		if n.Type != "atom" || seen[n.Field] {
+			return
+		}
+		if minVersion, ok := atomMinVersion[n.Field]; ok && version.less(minVersion) {
+			seen[n.Field] = true
+			result = append(result, UnsupportedAtom{
+				Field:      n.Field,
+				MinVersion: minVersion.String(),
+				Suggestion: atomSuggestions[n.Field],
+			})
+		}
+	}
+	walk(node)
+
+	return result
+}
+
+// literalQuery rewrites query so zoekt matches it as literal text. Every
+// ASCII character other than letters, digits and underscores is written as a
+// \xHH regex escape, so neither the query parser (spaces, quotes, parentheses,
+// field prefixes, leading -) nor the regex engine gives it special meaning.
+func literalQuery(query string) string {
+	var b strings.Builder
+	for _, r := range query {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+		case r < 0x80:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// validateQueryNode checks field values and regex syntax of every atom
+func validateQueryNode(node *QueryNode, warnings *[]string) error {
+	for _, child := range node.Children {
+		if err := validateQueryNode(child, warnings); err != nil {
+			return err
+		}
+	}
+	if node.Type != "atom" {
+		return nil
+	}
+
+	if allowed, ok := queryFieldValues[node.Field]; ok {
+		for _, v := range allowed {
+			if node.Value == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid value %q for %s (expected one of %s)", node.Value, node.Field, strings.Join(allowed, ", "))
+	}
+
+	if regexFields[node.Field] {
+		if _, err := regexp.Compile(node.Value); err != nil {
+			return fmt.Errorf("invalid regular expression %q: %v", node.Value, err)
+		}
+	}
+
+	// Unknown prefixes are not fields; zoekt searches for them literally
+	if node.Field == "" {
+		if idx := strings.Index(node.Value, ":"); idx > 0 && !strings.ContainsAny(node.Value[:idx], `\.*+?()[]{}|^$`) {
+			*warnings = append(*warnings, fmt.Sprintf("%q is not a known field; %q will be searched as text", node.Value[:idx], node.Value))
+		}
+	}
+	return nil
+}
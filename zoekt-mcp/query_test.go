@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseQuery(t *testing.T) {
+	tree, warnings, err := parseQuery(`lang:go (handleRequest or f:server\.go) -file:_test`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	if tree.Type != "and" || len(tree.Children) != 3 {
+		t.Fatalf("expected an and node with 3 children, got %+v", tree)
+	}
+	if c := tree.Children[0]; c.Type != "atom" || c.Field != "lang" || c.Value != "go" {
+		t.Errorf("unexpected lang atom: %+v", c)
+	}
+	if c := tree.Children[1]; c.Type != "or" || len(c.Children) != 2 || c.Children[1].Field != "file" {
+		t.Errorf("unexpected or node: %+v", c)
+	}
+	if c := tree.Children[2]; c.Type != "not" || c.Children[0].Field != "file" || c.Children[0].Value != "_test" {
+		t.Errorf("unexpected negation: %+v", c)
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{query: "", err: "empty query"},
+		{query: "(foo bar", err: "missing closing parenthesis"},
+		{query: "foo)", err: `unexpected ")"`},
+		{query: "foo or", err: "query ends unexpectedly"},
+		{query: `"unterminated`, err: "unterminated quoted string at position 0"},
+		{query: "content:foo[", err: "invalid regular expression"},
+		{query: "case:maybe foo", err: `invalid value "maybe" for case`},
+		{query: "repo:", err: `missing value for field "repo"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, _, err := parseQuery(test.query)
+			if err == nil {
+				t.Fatalf("expected error containing %q", test.err)
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %q", test.err, err.Error())
+			}
+		})
+	}
+}
+
+func TestParseQuery_UnknownField(t *testing.T) {
+	tree, warnings, err := parseQuery("author:bob")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tree.Field != "" || tree.Value != "author:bob" {
+		t.Errorf("expected a plain text atom, got %+v", tree)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected one warning, got %v", warnings)
+	}
+}
+
+func TestLeadingDashQuery_ValidateAndSearch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	// the fake zoekt only accepts the query as the argument after --, so the
+	// dry run and the search must build the same command line
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nwhile [ $# -gt 0 ]; do\n  if [ \"$1\" = \"--\" ]; then [ \"$2\" = \"-file:_test foo\" ] && [ $# -eq 2 ]; exit; fi\n  shift\ndone\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+	indexDir := t.TempDir()
+
+	if result := dryRunQuery("-file:_test foo", indexDir); !result.Ran || !result.Accepted {
+		t.Errorf("expected the dry run to pass the query after --, got %+v", result)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"query":       "-file:_test foo",
+		"index_dir":   indexDir,
+		"output_file": filepath.Join(t.TempDir(), "out.txt"),
+	}
+	result, err := handleSearchTool(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Errorf("expected the search to pass the query after --, got %+v", result.Content)
+	}
+}
+
+func TestUnsupportedAtoms(t *testing.T) {
+	tree, _, err := parseQuery("sym:handleRequest lang:go -archived:yes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a stub release predating sym: and archived:
+	old, ok := parseZoektVersion("zoekt version v2.1.0")
+	if !ok {
+		t.Fatal("expected version to parse")
+	}
+
+	unsupported := unsupportedAtoms(tree, old)
+	if len(unsupported) != 2 {
+		t.Fatalf("expected 2 unsupported atoms, got %+v", unsupported)
+	}
+	if unsupported[0].Field != "sym" || unsupported[0].MinVersion != "v3.0.0" || unsupported[0].Suggestion == "" {
+		t.Errorf("unexpected first unsupported atom: %+v", unsupported[0])
+	}
+	if unsupported[1].Field != "archived" {
+		t.Errorf("expected archived to be unsupported, got %+v", unsupported[1])
+	}
+
+	current, _ := parseZoektVersion("v3.7.2-89-g1a2b3c4")
+	if unsupported := unsupportedAtoms(tree, current); len(unsupported) != 0 {
+		t.Errorf("expected no unsupported atoms for %s, got %+v", current, unsupported)
+	}
+}
+
+func TestValidateQueryTool_UnknownVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	// a zoekt build without -version that accepts every query
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nif [ \"$1\" = \"-version\" ]; then echo 'flag provided but not defined: -version' >&2; exit 2; fi\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"query": "sym:handleRequest type:file", "index_dir": t.TempDir()}
+	result, err := handleValidateQueryTool(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output struct {
+		Valid            bool              `json:"valid"`
+		ZoektVersion     string            `json:"zoekt_version"`
+		Warnings         []string          `json:"warnings"`
+		UnsupportedAtoms []UnsupportedAtom `json:"unsupported_atoms"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	if !output.Valid {
+		t.Error("expected sym: and type: to stay valid when the zoekt version is unknown")
+	}
+	if output.ZoektVersion != "unknown" {
+		t.Errorf("expected zoekt_version unknown, got %q", output.ZoektVersion)
+	}
+	if len(output.Warnings) != 2 || !strings.HasPrefix(output.Warnings[0], "sym:") || !strings.HasPrefix(output.Warnings[1], "type:") {
+		t.Errorf("expected a warning for sym: and type:, got %q", output.Warnings)
+	}
+	if len(output.UnsupportedAtoms) != 0 {
+		t.Errorf("expected no unsupported_atoms without a version, got %+v", output.UnsupportedAtoms)
+	}
+}
+
+func TestLiteralQuery(t *testing.T) {
+	tests := []string{
+		"foo(bar)",
+		"a.b*c+d?",
+		`"quoted" -negated file:x`,
+		"[a-z]{2} | ^$",
+		`back\slash`,
+	}
+
+	for _, query := range tests {
+		escaped := literalQuery(query)
+
+		// the escaped query must be a single plain term for the zoekt parser
+		tree, _, err := parseQuery(escaped)
+		if err != nil {
+			t.Fatalf("literalQuery(%q) = %q does not parse: %v", query, escaped, err)
+		}
+		if tree.Type != "atom" || tree.Field != "" {
+			t.Errorf("literalQuery(%q) = %q parsed as %+v, want a single content atom", query, escaped, tree)
+		}
+
+		// and a regex that matches exactly the original text
+		re, err := regexp.Compile("^" + escaped + "$")
+		if err != nil {
+			t.Fatalf("literalQuery(%q) = %q is not a valid regex: %v", query, escaped, err)
+		}
+		if !re.MatchString(query) {
+			t.Errorf("literalQuery(%q) = %q does not match the original text", query, escaped)
+		}
+	}
+
+	if got := literalQuery("plain_word123"); got != "plain_word123" {
+		t.Errorf("expected plain words to be unchanged, got %q", got)
+	}
+}
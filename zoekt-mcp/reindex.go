@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ReindexResult is the outcome of indexing one repository during a reindex run
+type ReindexResult struct {
+	Repository string     `json:"repository"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Stdout     string     `json:"stdout,omitempty"`
+	Stderr     string     `json:"stderr,omitempty"`
+	Stats      IndexStats `json:"stats"`
+}
+
+// findGitRepositories returns every directory under root that contains a .git
+// entry. Repositories are not searched for nested repositories.
+func findGitRepositories(root string) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the scan
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		// .git is a directory for normal clones and a file for worktrees and submodules
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return repos, err
+}
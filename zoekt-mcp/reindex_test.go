@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFindGitRepositories(t *testing.T) {
+	root := t.TempDir()
+
+	for _, dir := range []string{
+		"alpha/.git",
+		"group/beta/.git",
+		"group/beta/vendor/nested/.git",
+		"plain/src",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// worktrees use a .git file instead of a directory
+	if err := os.MkdirAll(filepath.Join(root, "worktree"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "worktree", ".git"), []byte("gitdir: ../alpha/.git\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := findGitRepositories(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "alpha"),
+		filepath.Join(root, "group", "beta"),
+		filepath.Join(root, "worktree"),
+	}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Errorf("expected %v, got %v", expected, repos)
+	}
+}
+
+// reindexFixture creates two repositories under a temporary root and installs
+// a zoekt-git-index script in a temporary ZOEKT_BIN_DIR
+func reindexFixture(t *testing.T, script string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, repo := range []string{"alpha", "beta"} {
+		if err := os.MkdirAll(filepath.Join(root, repo, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt-git-index"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+	return root
+}
+
+func runReindex(t *testing.T, ctx context.Context, root, outputFile string) map[string]json.RawMessage {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{
+		"root":        root,
+		"index_dir":   t.TempDir(),
+		"output_file": outputFile,
+	}
+	result, err := handleReindexTool(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+		t.Fatalf("unexpected result %v: %v", result.Content, err)
+	}
+	return output
+}
+
+func TestReindexTool_SeparateOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	root := reindexFixture(t, "#!/bin/sh\necho indexed\necho 'finished shard' >&2\n")
+	outputFile := filepath.Join(t.TempDir(), "reindex.log")
+
+	output := runReindex(t, context.Background(), root, outputFile)
+	if string(output["status"]) != `"success"` {
+		t.Fatalf("expected success, got %s", output["status"])
+	}
+	var results []ReindexResult
+	if err := json.Unmarshal(output["repositories"], &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 repositories, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Stdout != "indexed" || result.Stderr != "finished shard" {
+			t.Errorf("expected stdout and stderr to be reported separately, got %+v", result)
+		}
+	}
+
+	log, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(log), "==> zoekt-git-index") != 2 {
+		t.Errorf("expected the log of both runs in the output file, got %q", log)
+	}
+}
+
+func TestReindexTool_MaxOutputBytes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	root := reindexFixture(t, "#!/bin/sh\nwhile :; do echo 0123456789; done\n")
+	t.Setenv("ZOEKT_MAX_OUTPUT_BYTES", "1000")
+
+	output := runReindex(t, context.Background(), root, filepath.Join(t.TempDir(), "reindex.log"))
+	if string(output["status"]) != `"failed"` {
+		t.Fatalf("expected both runs to fail at the output limit, got %s", output["status"])
+	}
+	var results []ReindexResult
+	if err := json.Unmarshal(output["repositories"], &results); err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if !strings.Contains(result.Error, "exceeded ZOEKT_MAX_OUTPUT_BYTES (1000 bytes)") {
+			t.Errorf("expected an output limit error, got %q", result.Error)
+		}
+	}
+}
+
+func TestReindexTool_Cancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	root := reindexFixture(t, "#!/bin/sh\necho indexed\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := runReindex(t, ctx, root, filepath.Join(t.TempDir(), "reindex.log"))
+	if string(output["status"]) != `"cancelled"` || string(output["skipped"]) != "2" {
+		t.Errorf("expected a cancelled run skipping both repositories, got status %s, skipped %s", output["status"], output["skipped"])
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// shardFinishedPattern matches the line zoekt's builder logs for every shard it writes
+var shardFinishedPattern = regexp.MustCompile(`finished (?:shard )?(\S+): (\d+) index bytes \(overhead [\d.]+\), (\d+) files processed`)
+
+// matchLinePattern matches a zoekt result line of the form path:line:content
+var matchLinePattern = regexp.MustCompile(`^(.+?):(\d+):(.*)$`)
+
+// SearchMatch is a single result parsed from zoekt's output
+type SearchMatch struct {
+	File    string   `json:"file"`
+	Line    int      `json:"line,omitempty"`
+	Content string   `json:"content,omitempty"`
+	Excerpt *Excerpt `json:"excerpt,omitempty"`
+}
+
+// FileSummary is one ranked entry of a search summary
+type FileSummary struct {
+	File    string `json:"file"`
+	Matches int    `json:"matches"`
+	Context string `json:"context,omitempty"`
+}
+
+// IndexStats summarizes what an indexing run produced
+type IndexStats struct {
+	Shards         int     `json:"shards"`
+	FilesIndexed   int     `json:"files_indexed"`
+	FilesSkipped   int     `json:"files_skipped"`
+	IndexBytes     int64   `json:"index_bytes"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// SearchSummary is a compact, ranked view of a search result set
+type SearchSummary struct {
+	TotalMatches int           `json:"total_matches"`
+	TotalFiles   int           `json:"total_files"`
+	TopFiles     []FileSummary `json:"top_files"`
+}
+
+// parseSearchOutput converts zoekt's line-oriented output into matches.
+// Lines without a line number (as printed with -l) are returned as file-only matches.
+func parseSearchOutput(output string) []SearchMatch {
+	var matches []SearchMatch
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if m := matchLinePattern.FindStringSubmatch(line); m != nil {
+			lineNum, _ := strconv.Atoi(m[2])
+			matches = append(matches, SearchMatch{
+				File:    m[1],
+				Line:    lineNum,
+				Content: m[3],
+			})
+			continue
+		}
+
+		matches = append(matches, SearchMatch{File: line})
+	}
+	return matches
+}
+
+// summarizeMatches groups matches by file and ranks files by match count.
+// Ties keep zoekt's own ranking order. At most limit files are returned.
+func summarizeMatches(matches []SearchMatch, limit int) SearchSummary {
+	var files []*FileSummary
+	byFile := make(map[string]*FileSummary)
+
+	for _, match := range matches {
+		summary, ok := byFile[match.File]
+		if !ok {
+			summary = &FileSummary{
+				File:    match.File,
+				Context: truncateString(strings.TrimSpace(match.Content), 120),
+			}
+			byFile[match.File] = summary
+			files = append(files, summary)
+		}
+		summary.Matches++
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Matches > files[j].Matches
+	})
+
+	if limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+
+	topFiles := make([]FileSummary, 0, len(files))
+	for _, f := range files {
+		topFiles = append(topFiles, *f)
+	}
+
+	return SearchSummary{
+		TotalMatches: len(matches),
+		TotalFiles:   len(byFile),
+		TopFiles:     topFiles,
+	}
+}
+
+// paginateMatches returns the page of matches starting at offset and whether
+// more matches remain after it. Offsets past the end yield an empty page.
+func paginateMatches(matches []SearchMatch, offset, pageSize int) ([]SearchMatch, bool) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(matches) {
+		return []SearchMatch{}, false
+	}
+
+	end := len(matches)
+	if pageSize > 0 && offset+pageSize < end {
+		end = offset + pageSize
+	}
+	return matches[offset:end], end < len(matches)
+}
+
+// parseIndexOutput collects shard statistics from the log output of
+// zoekt-index and zoekt-git-index. Elapsed time is measured by the caller.
+func parseIndexOutput(output string) IndexStats {
+	var stats IndexStats
+	for _, line := range strings.Split(output, "\n") {
+		if m := shardFinishedPattern.FindStringSubmatch(line); m != nil {
+			bytes, _ := strconv.ParseInt(m[2], 10, 64)
+			files, _ := strconv.Atoi(m[3])
+			stats.Shards++
+			stats.IndexBytes += bytes
+			stats.FilesIndexed += files
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(line), "skipping") {
+			stats.FilesSkipped++
+		}
+	}
+	return stats
+}
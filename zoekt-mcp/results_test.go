@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseSearchOutput(t *testing.T) {
+	output := "main.go:12:func main() {\nlib/util.go:3:package util\ncmd/tool.go\n"
+
+	matches := parseSearchOutput(output)
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(matches))
+	}
+	if matches[0].File != "main.go" || matches[0].Line != 12 || matches[0].Content != "func main() {" {
+		t.Errorf("unexpected first match: %+v", matches[0])
+	}
+	if matches[2].File != "cmd/tool.go" || matches[2].Line != 0 {
+		t.Errorf("expected file-only match, got %+v", matches[2])
+	}
+}
+
+func TestSummarizeMatches(t *testing.T) {
+	output := `a.go:1:  first in a
+b.go:4:first in b
+b.go:9:second in b
+c.go:2:first in c
+b.go:11:third in b
+c.go:5:second in c
+`
+
+	summary := summarizeMatches(parseSearchOutput(output), 2)
+
+	if summary.TotalMatches != 6 {
+		t.Errorf("expected 6 total matches, got %d", summary.TotalMatches)
+	}
+	if summary.TotalFiles != 3 {
+		t.Errorf("expected 3 total files, got %d", summary.TotalFiles)
+	}
+	if len(summary.TopFiles) != 2 {
+		t.Fatalf("expected summary limited to 2 files, got %d", len(summary.TopFiles))
+	}
+
+	want := []FileSummary{
+		{File: "b.go", Matches: 3, Context: "first in b"},
+		{File: "c.go", Matches: 2, Context: "first in c"},
+	}
+	for i, w := range want {
+		if summary.TopFiles[i] != w {
+			t.Errorf("rank %d: expected %+v, got %+v", i, w, summary.TopFiles[i])
+		}
+	}
+}
+
+func TestPaginateMatches(t *testing.T) {
+	matches := parseSearchOutput("a.go:1:a\nb.go:2:b\nc.go:3:c\nd.go:4:d\ne.go:5:e\n")
+
+	page, hasMore := paginateMatches(matches, 0, 2)
+	if len(page) != 2 || page[0].File != "a.go" || !hasMore {
+		t.Errorf("unexpected first page: %+v (has_more=%v)", page, hasMore)
+	}
+
+	page, hasMore = paginateMatches(matches, 4, 2)
+	if len(page) != 1 || page[0].File != "e.go" || hasMore {
+		t.Errorf("unexpected last page: %+v (has_more=%v)", page, hasMore)
+	}
+
+	page, hasMore = paginateMatches(matches, 10, 2)
+	if len(page) != 0 || hasMore {
+		t.Errorf("expected empty page past the end, got %+v (has_more=%v)", page, hasMore)
+	}
+}
+
+func TestParseIndexOutput(t *testing.T) {
+	output := `2025/07/29 16:13:12 skipping large file vendor/blob.bin
+2025/07/29 16:13:13 finished shard /root/.zoekt/repo_v16.00000.zoekt: 1048576 index bytes (overhead 2.4), 120 files processed
+2025/07/29 16:13:14 finished shard /root/.zoekt/repo_v16.00001.zoekt: 524288 index bytes (overhead 3.1), 30 files processed
+`
+
+	stats := parseIndexOutput(output)
+	want := IndexStats{Shards: 2, FilesIndexed: 150, FilesSkipped: 1, IndexBytes: 1572864}
+	if stats != want {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+}
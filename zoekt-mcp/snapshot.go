@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// snapshotNamePattern restricts snapshot names to safe file names
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Snapshot is the match count of a named query at one point in time
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Timestamp time.Time `json:"timestamp"`
+	Matches   int       `json:"matches"`
+	Files     int       `json:"files"`
+}
+
+// SnapshotDiff compares the latest two snapshots of a named query
+type SnapshotDiff struct {
+	Name         string   `json:"name"`
+	Previous     Snapshot `json:"previous"`
+	Latest       Snapshot `json:"latest"`
+	MatchesDelta int      `json:"matches_delta"`
+	FilesDelta   int      `json:"files_delta"`
+	// Trend is increased, decreased or unchanged
+	Trend        string `json:"trend"`
+	QueryChanged bool   `json:"query_changed,omitempty"`
+}
+
+// snapshotFile returns the file the snapshots of name are stored in, one
+// JSON object per line, below indexDir
+func snapshotFile(indexDir, name string) (string, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid snapshot name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return filepath.Join(indexDir, "snapshots", name+".jsonl"), nil
+}
+
+// appendSnapshot stores a snapshot after the earlier ones of its name
+func appendSnapshot(indexDir string, snapshot Snapshot) error {
+	path, err := snapshotFile(indexDir, snapshot.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadSnapshots returns the stored snapshots of name, oldest first
+func loadSnapshots(indexDir, name string) ([]Snapshot, error) {
+	path, err := snapshotFile(indexDir, name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no snapshots named %q in %s", name, indexDir)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var snapshots []Snapshot
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			return nil, fmt.Errorf("corrupt snapshot in %s: %v", path, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, scanner.Err()
+}
+
+// diffSnapshots compares the latest two snapshots
+func diffSnapshots(snapshots []Snapshot) (SnapshotDiff, error) {
+	if len(snapshots) < 2 {
+		return SnapshotDiff{}, fmt.Errorf("at least two snapshots are needed for a diff, found %d", len(snapshots))
+	}
+	previous, latest := snapshots[len(snapshots)-2], snapshots[len(snapshots)-1]
+
+	diff := SnapshotDiff{
+		Name:         latest.Name,
+		Previous:     previous,
+		Latest:       latest,
+		MatchesDelta: latest.Matches - previous.Matches,
+		FilesDelta:   latest.Files - previous.Files,
+		Trend:        "unchanged",
+		QueryChanged: latest.Query != previous.Query,
+	}
+	switch {
+	case diff.MatchesDelta > 0:
+		diff.Trend = "increased"
+	case diff.MatchesDelta < 0:
+		diff.Trend = "decreased"
+	}
+	return diff, nil
+}
+
+// countMatches runs query with zoekt and counts the matches and the files
+// they are in. Output is capped like executeCommand's. The query follows --
+// so that a negated query such as -file:_test is not read as a flag.
+func countMatches(ctx context.Context, indexDir, query string) (int, int, error) {
+	stdout, stderr, err := runCommand(ctx, []string{"zoekt", "-index_dir", indexDir, "--", query})
+	if errors.Is(err, errOutputLimit) {
+		return 0, 0, fmt.Errorf("%v, so the matches could not be counted", err)
+	} else if err != nil {
+		return 0, 0, fmt.Errorf("command failed: %v, stderr: %s", err, stderr)
+	}
+
+	matches := parseSearchOutput(string(stdout))
+	files := make(map[string]bool)
+	for _, match := range matches {
+		files[match.File] = true
+	}
+	return len(matches), len(files), nil
+}
+
+func createSnapshotTool() mcp.Tool {
+	return mcp.NewTool("zoekt-snapshot",
+		mcp.WithDescription("Run a query and store its match count with a timestamp under a name, e.g. to track when uses of a deprecated API disappear. Snapshots are kept in the snapshots directory of the index dir; compare them with zoekt-snapshot-diff."),
+		mcp.WithString("name", mcp.Required(),
+			mcp.Description("Name of the tracked query, e.g. deprecated-api; letters, digits, '.', '_' and '-'"),
+		),
+		mcp.WithString("query", mcp.Required()),
+		mcp.WithString("index_dir"),
+	)
+}
+
+func createSnapshotDiffTool() mcp.Tool {
+	return mcp.NewTool("zoekt-snapshot-diff",
+		mcp.WithDescription("Compare the latest two snapshots of a named query taken with zoekt-snapshot and report whether its matches increased, decreased or stayed unchanged."),
+		mcp.WithString("name", mcp.Required()),
+		mcp.WithString("index_dir"),
+	)
+}
+
+func handleSnapshotTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	query, err := request.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if _, err := snapshotFile("", name); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	indexDir := request.GetString("index_dir", "")
+	if indexDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		indexDir = filepath.Join(homeDir, ".zoekt")
+	}
+
+	matches, files, err := countMatches(ctx, indexDir, query)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to execute zoekt search: %v", err)), nil
+	}
+
+	snapshot := Snapshot{
+		Name:      name,
+		Query:     query,
+		Timestamp: time.Now().UTC(),
+		Matches:   matches,
+		Files:     files,
+	}
+	if err := appendSnapshot(indexDir, snapshot); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to store snapshot: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"status":   "success",
+		"snapshot": snapshot,
+	}
+	return mcp.NewToolResultText(toJSON(result)), nil
+}
+
+func handleSnapshotDiffTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	indexDir := request.GetString("index_dir", "")
+	if indexDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		indexDir = filepath.Join(homeDir, ".zoekt")
+	}
+
+	snapshots, err := loadSnapshots(indexDir, name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	diff, err := diffSnapshots(snapshots)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]interface{}{
+		"status":    "success",
+		"diff":      diff,
+		"snapshots": len(snapshots),
+	}
+	return mcp.NewToolResultText(toJSON(result)), nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	indexDir := t.TempDir()
+	taken := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, snapshot := range []Snapshot{
+		{Name: "deprecated-api", Query: "OldClient", Timestamp: taken, Matches: 40, Files: 12},
+		{Name: "deprecated-api", Query: "OldClient", Timestamp: taken.Add(24 * time.Hour), Matches: 25, Files: 9},
+		{Name: "deprecated-api", Query: "OldClient", Timestamp: taken.Add(48 * time.Hour), Matches: 18, Files: 9},
+	} {
+		if err := appendSnapshot(indexDir, snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"name": "deprecated-api", "index_dir": indexDir}
+	result, err := handleSnapshotDiffTool(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error: %v", result.Content)
+	}
+	var output struct {
+		Diff      SnapshotDiff `json:"diff"`
+		Snapshots int          `json:"snapshots"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := output.Diff
+	if output.Snapshots != 3 {
+		t.Errorf("expected 3 snapshots, got %d", output.Snapshots)
+	}
+	if diff.Previous.Matches != 25 || diff.Latest.Matches != 18 {
+		t.Errorf("expected the latest two snapshots, got %+v and %+v", diff.Previous, diff.Latest)
+	}
+	if diff.MatchesDelta != -7 || diff.FilesDelta != 0 || diff.Trend != "decreased" {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+	if !diff.Latest.Timestamp.Equal(taken.Add(48 * time.Hour)) {
+		t.Errorf("unexpected latest timestamp %v", diff.Latest.Timestamp)
+	}
+	if diff.QueryChanged {
+		t.Error("expected query_changed to be false")
+	}
+
+	if err := appendSnapshot(indexDir, Snapshot{Name: "deprecated-api", Query: "OldClient|LegacyClient", Matches: 30}); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err := loadSnapshots(indexDir, "deprecated-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err = diffSnapshots(snapshots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.MatchesDelta != 12 || diff.Trend != "increased" || !diff.QueryChanged {
+		t.Errorf("unexpected diff after changing the query: %+v", diff)
+	}
+}
+
+func TestSnapshotDiffNeedsTwoSnapshots(t *testing.T) {
+	indexDir := t.TempDir()
+	diff := func(name string) string {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"name": name, "index_dir": indexDir}
+		result, err := handleSnapshotDiffTool(context.Background(), request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.IsError {
+			t.Fatalf("expected an error for %q", name)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := diff("missing"); !strings.Contains(text, "no snapshots") {
+		t.Errorf("unexpected error %q", text)
+	}
+	if err := appendSnapshot(indexDir, Snapshot{Name: "single", Query: "x", Matches: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if text := diff("single"); !strings.Contains(text, "at least two snapshots") {
+		t.Errorf("unexpected error %q", text)
+	}
+	if text := diff("../escape"); !strings.Contains(text, "invalid snapshot name") {
+		t.Errorf("unexpected error %q", text)
+	}
+}
+
+func TestSnapshotTool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/sh\nprintf 'a.go:1:OldClient()\\nb.go:4:OldClient()\\nb.go:9:OldClient()\\n'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+
+	indexDir := t.TempDir()
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"name": "deprecated-api", "query": "OldClient", "index_dir": indexDir}
+	result, err := handleSnapshotTool(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error: %v", result.Content)
+	}
+
+	snapshots, err := loadSnapshots(indexDir, "deprecated-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("expected 1 stored snapshot, got %d", len(snapshots))
+	}
+	if snapshots[0].Matches != 3 || snapshots[0].Files != 2 || snapshots[0].Query != "OldClient" || snapshots[0].Timestamp.IsZero() {
+		t.Errorf("unexpected snapshot %+v", snapshots[0])
+	}
+}
+
+func TestSnapshotTool_LeadingDash(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	// the fake zoekt only matches when the query follows the -- separator
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n[ \"$3\" = \"--\" ] && [ \"$4\" = \"-file:_test OldClient\" ] && printf 'a.go:1:OldClient()\\n'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "zoekt"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ZOEKT_BIN_DIR", binDir)
+
+	matches, files, err := countMatches(context.Background(), t.TempDir(), "-file:_test OldClient")
+	if err != nil {
+		t.Fatalf("expected the query to be passed after --, got %v", err)
+	}
+	if matches != 1 || files != 1 {
+		t.Errorf("expected 1 match in 1 file, got %d in %d", matches, files)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/server"
+	log "github.com/sirupsen/logrus"
+)
+
+// serve runs s over the selected transport until it stops: stdio, sse
+// (Server-Sent Events) or http (streamable HTTP, served at /mcp). The HTTP
+// based transports listen on all interfaces at port.
+func serve(s *server.MCPServer, transport, port, baseURL string) error {
+	addr := "0.0.0.0:" + port
+	switch transport {
+	case "stdio":
+		return server.ServeStdio(s)
+	case "sse":
+		log.Infof("zoekt MCP server (SSE) listening on %s (base URL %s)", addr, baseURL)
+		return server.NewSSEServer(s, server.WithBaseURL(baseURL)).Start(addr)
+	case "http":
+		log.Infof("zoekt MCP server (streamable HTTP) listening on %s/mcp", addr)
+		return server.NewStreamableHTTPServer(s).Start(addr)
+	default:
+		return fmt.Errorf("unknown transport %q: must be stdio, sse or http", transport)
+	}
+}
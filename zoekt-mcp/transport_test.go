@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestServeUnknownTransport(t *testing.T) {
+	err := serve(server.NewMCPServer("test", "1.0.0"), "websocket", "0", "")
+	if err == nil || !strings.Contains(err.Error(), `unknown transport "websocket"`) {
+		t.Fatalf("expected unknown transport error, got %v", err)
+	}
+}